@@ -13,6 +13,7 @@ import (
 	"github.com/dushixiang/pika/internal/handler"
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/scheduler"
+	"github.com/dushixiang/pika/internal/service"
 	"github.com/dushixiang/pika/pkg/replace"
 	"github.com/dushixiang/pika/pkg/version"
 	"github.com/dushixiang/pika/web"
@@ -29,16 +30,24 @@ import (
 )
 
 func Run(configPath string) {
-	err := orz.Quick(configPath, setup)
+	var components *AppComponents
+	err := orz.Quick(configPath, func(app *orz.App) (err error) {
+		components, err = setup(app)
+		return err
+	})
+	// 服务停止后立即发送缓存中的批量告警，避免退出时丢失
+	if components != nil {
+		components.Notifier.FlushWebhookBatches()
+	}
 	if err != nil {
 		log.Fatal(err)
 	}
 }
 
-func setup(app *orz.App) error {
+func setup(app *orz.App) (*AppComponents, error) {
 	// 数据库迁移
 	if err := autoMigrate(app.GetDatabase()); err != nil {
-		return err
+		return nil, err
 	}
 
 	// 读取应用配置
@@ -47,7 +56,7 @@ func setup(app *orz.App) error {
 	if _config != nil {
 		if err := _config.App.Unmarshal(&appConfig); err != nil {
 			app.Logger().Error("读取配置失败", zap.Error(err))
-			return err
+			return nil, err
 		}
 	}
 
@@ -63,7 +72,7 @@ func setup(app *orz.App) error {
 	// 初始化应用组件
 	components, err := InitializeApp(app.Logger(), app.GetDatabase(), &appConfig)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// 初始化默认属性配置
@@ -90,6 +99,15 @@ func setup(app *orz.App) error {
 	// 启动指标监控任务（用于告警检测）
 	go startMetricsMonitoring(ctx, components, app.Logger())
 
+	// 启动每日告警摘要任务
+	go components.AlertService.StartDigestTask(ctx)
+
+	// 启动通知重试任务（包括重启前未完成的重试）
+	go components.AlertService.StartNotificationRetryTask(ctx)
+
+	// 启动通知渠道全部禁用检查
+	go components.AlertService.StartChannelDriftCheck(ctx)
+
 	// 启动服务监控任务调度器
 	monitorScheduler := scheduler.NewMonitorScheduler(components.MonitorService, app.Logger(), 10)
 	monitorScheduler.Start(ctx)
@@ -100,7 +118,7 @@ func setup(app *orz.App) error {
 	// 设置API
 	setupApi(app, components)
 
-	return nil
+	return components, nil
 }
 
 func setupApi(app *orz.App, components *AppComponents) {
@@ -174,6 +192,9 @@ func setupApi(app *orz.App, components *AppComponents) {
 		publicApi.GET("/agent/version", components.AgentHandler.GetAgentVersion)
 		publicApi.GET("/agent/downloads/:filename", components.AgentHandler.DownloadAgent)
 		publicApi.GET("/agent/install.sh", components.AgentHandler.GetInstallScript)
+
+		// 飞书卡片交互回调（通过签名和 Token 校验来源）
+		publicApi.POST("/notification-channels/:id/feishu/callback", components.NotificationChannelHandler.FeishuCallback)
 	}
 
 	// 公开接口（支持可选认证）- 已登录返回全部数据，未登录只返回公开数据
@@ -246,15 +267,45 @@ func setupApi(app *orz.App, components *AppComponents) {
 		adminApi.GET("/agents/:id/tamper/alerts", components.TamperHandler.GetTamperAlerts)
 
 		// 通用属性管理
+		adminApi.GET("/properties", components.PropertyHandler.GetProperties)
+		adminApi.GET("/properties/export", components.PropertyHandler.ExportProperties)
+		adminApi.POST("/properties/import", components.PropertyHandler.ImportProperties)
 		adminApi.GET("/properties/:id", components.PropertyHandler.GetProperty)
 		adminApi.PUT("/properties/:id", components.PropertyHandler.SetProperty)
-
-		// 通知渠道测试（从数据库读取配置测试）
-		adminApi.POST("/notification-channels/:type/test", components.PropertyHandler.TestNotificationChannel)
+		adminApi.DELETE("/properties/:id", components.PropertyHandler.DeleteProperty)
+		adminApi.GET("/properties/:id/audits", components.PropertyHandler.ListPropertyAudits)
+
+		// 通知渠道管理
+		adminApi.GET("/notification-channels", components.NotificationChannelHandler.List)
+		adminApi.GET("/notification-channels/types", components.NotificationChannelHandler.ListTypes)
+		adminApi.GET("/notification-channels/stats", components.NotificationChannelHandler.Stats)
+		adminApi.POST("/notification-channels", components.NotificationChannelHandler.Create)
+		adminApi.POST("/notification-channels/preview", components.NotificationChannelHandler.Preview)
+		adminApi.POST("/notification-channels/dry-run", components.NotificationChannelHandler.DryRun)
+		adminApi.GET("/notification-channels/:id", components.NotificationChannelHandler.Get)
+		adminApi.PUT("/notification-channels/:id", components.NotificationChannelHandler.Update)
+		adminApi.DELETE("/notification-channels/:id", components.NotificationChannelHandler.Delete)
+		adminApi.POST("/notification-channels/:id/clone", components.NotificationChannelHandler.Clone)
+		adminApi.GET("/notification-channels/:id/schedule", components.NotificationChannelHandler.ScheduleStatus)
+		adminApi.POST("/notification-channels/:id/enable", components.NotificationChannelHandler.Enable)
+		adminApi.POST("/notification-channels/:id/disable", components.NotificationChannelHandler.Disable)
+		// 通知渠道测试（从数据库读取配置测试，兼容按类型测试）
+		adminApi.POST("/notification-channels/:id/test", components.NotificationChannelHandler.Test)
 
 		// 告警记录查询
 		adminApi.GET("/alert-records", components.AlertHandler.ListAlertRecords)
 		adminApi.DELETE("/alert-records", components.AlertHandler.ClearAlertRecords)
+		adminApi.POST("/alert-records/:id/replay", components.AlertHandler.ReplayAlertRecord)
+		adminApi.POST("/alert-snoozes", components.AlertHandler.SnoozeAlert)
+		adminApi.DELETE("/alert-snoozes", components.AlertHandler.ClearSnooze)
+		adminApi.GET("/alert-suppressions", components.AlertHandler.ListSuppressions)
+		adminApi.GET("/notification-logs", components.AlertHandler.ListNotificationLogs)
+		adminApi.GET("/silence-rules", components.AlertHandler.ListSilenceRules)
+		adminApi.POST("/silence-rules", components.AlertHandler.CreateSilenceRule)
+		adminApi.DELETE("/silence-rules/:id", components.AlertHandler.DeleteSilenceRule)
+		adminApi.GET("/alert-effective-config", components.AlertHandler.EffectiveNotificationConfig)
+		adminApi.GET("/muted-alert-types", components.PropertyHandler.GetMutedAlertTypes)
+		adminApi.PUT("/muted-alert-types", components.PropertyHandler.SetMutedAlertTypes)
 
 		// 服务监控配置
 		adminApi.GET("/monitors", components.MonitorHandler.List)
@@ -287,6 +338,10 @@ func autoMigrate(database *gorm.DB) error {
 		&models.HostMetric{},
 		&models.AuditResult{},
 		&models.Property{},
+		&models.PropertyAudit{},
+		&models.NotificationChannel{},
+		&models.NotificationRetry{},
+		&models.NotificationLog{},
 		&models.AlertRecord{},
 		&models.AlertState{},
 		&models.MonitorMetric{},
@@ -312,7 +367,11 @@ func autoMigrate(database *gorm.DB) error {
 // initDefaultProperties 初始化默认属性配置
 func initDefaultProperties(ctx context.Context, components *AppComponents, logger *zap.Logger) error {
 	// 使用 PropertyService 的初始化方法
-	return components.PropertyService.InitializeDefaultConfigs(ctx)
+	if err := components.PropertyService.InitializeDefaultConfigs(ctx); err != nil {
+		return err
+	}
+	// 将旧版通知渠道配置迁移到独立的表
+	return components.NotificationChannelService.MigrateFromProperty(ctx)
 }
 
 func ErrorHandler(logger *zap.Logger) func(next echo.HandlerFunc) echo.HandlerFunc {
@@ -471,6 +530,7 @@ func JWTAuthMiddleware(accountHandler *handler.AccountHandler) echo.MiddlewareFu
 			c.Set("userID", claims.UserID)
 			c.Set("username", claims.Username)
 			c.Set("authenticated", true)
+			c.SetRequest(c.Request().WithContext(service.WithActor(c.Request().Context(), claims.Username)))
 
 			return next(c)
 		}
@@ -496,6 +556,7 @@ func OptionalJWTAuthMiddleware(accountHandler *handler.AccountHandler) echo.Midd
 						c.Set("userID", claims.UserID)
 						c.Set("username", claims.Username)
 						c.Set("authenticated", true)
+						c.SetRequest(c.Request().WithContext(service.WithActor(c.Request().Context(), claims.Username)))
 					}
 				}
 			}
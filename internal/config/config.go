@@ -7,6 +7,8 @@ type AppConfig struct {
 	OIDC   *OIDCConfig        `json:"OIDC"`   // OIDC配置（可选）
 	GitHub *GitHubOAuthConfig `json:"GitHub"` // GitHub OAuth配置（可选）
 	GeoIP  *GeoIPConfig       `json:"GeoIP"`  // GeoIP配置（可选）
+
+	Notification *NotificationConfig `json:"Notification"` // 通知发送配置（可选）
 }
 
 // JWTConfig JWT配置
@@ -39,3 +41,17 @@ type GeoIPConfig struct {
 	DBPath     string `json:"DBPath"`     // GeoIP数据库文件路径（如：GeoLite2-City.mmdb）
 	DBLanguage string `json:"DBLanguage"` // 数据库语言（如：zh-CN、en）
 }
+
+// NotificationConfig 通知发送配置（出站 HTTP 连接）
+type NotificationConfig struct {
+	MaxIdleConns           int `json:"MaxIdleConns"`           // 最大空闲连接数（默认100）
+	MaxIdleConnsPerHost    int `json:"MaxIdleConnsPerHost"`    // 每个主机的最大空闲连接数（默认10）
+	IdleConnTimeoutSeconds int `json:"IdleConnTimeoutSeconds"` // 空闲连接超时时间（秒，默认90）
+
+	TLSMinVersion   string   `json:"TLSMinVersion"`   // 最低 TLS 版本：1.2(默认), 1.3
+	TLSCipherSuites []string `json:"TLSCipherSuites"` // 允许的加密套件名称（如 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256），为空使用 Go 默认值，全部无效时启动失败
+
+	DedupWindowSeconds int `json:"DedupWindowSeconds"` // 相同告警通知（探针、告警类型、状态相同）的去重窗口（秒，默认0不去重）
+
+	SecretKey string `json:"SecretKey"` // 通知渠道敏感字段（密钥、密码、令牌）的加密密钥，环境变量 PIKA_SECRET_KEY 优先，为空不加密
+}
@@ -2,7 +2,11 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
+	"time"
 
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
 	"github.com/dushixiang/pika/internal/service"
 	"github.com/go-orz/orz"
 	"github.com/labstack/echo/v4"
@@ -44,6 +48,43 @@ func (h *AlertHandler) ListAlertRecords(c echo.Context) error {
 	return orz.Ok(c, page)
 }
 
+// ListNotificationLogs 列出通知发送记录
+// 支持按探针、渠道类型、时间范围（start/end 为毫秒时间戳）和是否成功过滤
+func (h *AlertHandler) ListNotificationLogs(c echo.Context) error {
+	filter := repo.NotificationLogFilter{
+		AgentID:     c.QueryParam("agentId"),
+		ChannelType: c.QueryParam("channelType"),
+	}
+	if start := c.QueryParam("start"); start != "" {
+		v, err := strconv.ParseInt(start, 10, 64)
+		if err != nil {
+			return orz.NewError(400, "无效的开始时间")
+		}
+		filter.Start = v
+	}
+	if end := c.QueryParam("end"); end != "" {
+		v, err := strconv.ParseInt(end, 10, 64)
+		if err != nil {
+			return orz.NewError(400, "无效的结束时间")
+		}
+		filter.End = v
+	}
+	if success := c.QueryParam("success"); success != "" {
+		v, err := strconv.ParseBool(success)
+		if err != nil {
+			return orz.NewError(400, "无效的发送结果")
+		}
+		filter.Success = &v
+	}
+
+	page, err := h.alertService.ListNotificationLogs(c.Request().Context(), filter, orz.GetPageRequest(c))
+	if err != nil {
+		h.logger.Error("获取通知发送记录失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, page)
+}
+
 // ClearAlertRecords 清空告警记录
 func (h *AlertHandler) ClearAlertRecords(c echo.Context) error {
 	if err := h.alertService.Clear(c.Request().Context()); err != nil {
@@ -57,3 +98,125 @@ func (h *AlertHandler) ClearAlertRecords(c echo.Context) error {
 		"message": "清空成功",
 	})
 }
+
+// ReplayAlertRecord 将历史告警重新发送到指定通知渠道
+func (h *AlertHandler) ReplayAlertRecord(c echo.Context) error {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		return orz.NewError(400, "无效的告警记录ID")
+	}
+
+	var req struct {
+		ChannelID string `json:"channelId"`
+	}
+	if err := c.Bind(&req); err != nil || req.ChannelID == "" {
+		return orz.NewError(400, "缺少通知渠道ID")
+	}
+
+	if err := h.alertService.ReplayNotification(c.Request().Context(), id, req.ChannelID); err != nil {
+		h.logger.Error("重放告警通知失败", zap.Int64("recordId", id), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "重放告警通知失败: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "告警通知已重放",
+	})
+}
+
+// EffectiveNotificationConfig 获取指定探针（可选告警类型、级别、状态）实际生效的通知渠道和过滤条件
+func (h *AlertHandler) EffectiveNotificationConfig(c echo.Context) error {
+	agentID := c.QueryParam("agentId")
+	if agentID == "" {
+		return orz.NewError(400, "缺少探针ID")
+	}
+
+	result, err := h.alertService.EffectiveNotificationConfig(c.Request().Context(), agentID,
+		c.QueryParam("alertType"), c.QueryParam("level"), c.QueryParam("status"))
+	if err != nil {
+		h.logger.Error("获取生效的通知配置失败", zap.String("agentId", agentID), zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, result)
+}
+
+// SnoozeAlert 暂停指定探针某类告警的通知一段时间
+func (h *AlertHandler) SnoozeAlert(c echo.Context) error {
+	var req struct {
+		AgentID         string `json:"agentId"`
+		AlertType       string `json:"alertType"`
+		DurationMinutes int    `json:"durationMinutes"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	snooze, err := h.alertService.SnoozeAlert(c.Request().Context(), req.AgentID, req.AlertType, time.Duration(req.DurationMinutes)*time.Minute)
+	if err != nil {
+		h.logger.Error("暂停告警通知失败", zap.Error(err))
+		return err
+	}
+
+	return orz.Ok(c, snooze)
+}
+
+// ListSuppressions 列出当前生效的告警抑制项（暂停通知、全局静音）
+func (h *AlertHandler) ListSuppressions(c echo.Context) error {
+	suppressions, err := h.alertService.ListSuppressions(c.Request().Context())
+	if err != nil {
+		h.logger.Error("获取告警抑制列表失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, suppressions)
+}
+
+// ClearSnooze 提前解除暂停通知
+func (h *AlertHandler) ClearSnooze(c echo.Context) error {
+	agentID := c.QueryParam("agentId")
+	alertType := c.QueryParam("alertType")
+	if err := h.alertService.ClearSnooze(c.Request().Context(), agentID, alertType); err != nil {
+		h.logger.Error("解除告警暂停通知失败", zap.String("agentId", agentID), zap.String("alertType", alertType), zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"message": "暂停通知已解除",
+	})
+}
+
+// ListSilenceRules 列出静默规则
+func (h *AlertHandler) ListSilenceRules(c echo.Context) error {
+	rules, err := h.alertService.ListSilenceRules(c.Request().Context())
+	if err != nil {
+		h.logger.Error("获取静默规则失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, rules)
+}
+
+// CreateSilenceRule 创建静默规则
+func (h *AlertHandler) CreateSilenceRule(c echo.Context) error {
+	var rule models.SilenceRule
+	if err := c.Bind(&rule); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	created, err := h.alertService.CreateSilenceRule(c.Request().Context(), rule)
+	if err != nil {
+		h.logger.Error("创建静默规则失败", zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, created)
+}
+
+// DeleteSilenceRule 删除静默规则
+func (h *AlertHandler) DeleteSilenceRule(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.alertService.DeleteSilenceRule(c.Request().Context(), id); err != nil {
+		h.logger.Error("删除静默规则失败", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"message": "静默规则已删除",
+	})
+}
@@ -0,0 +1,343 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/service"
+	"github.com/go-orz/orz"
+	"github.com/labstack/echo/v4"
+	"go.uber.org/zap"
+)
+
+type NotificationChannelHandler struct {
+	logger   *zap.Logger
+	service  *service.NotificationChannelService
+	notifier *service.Notifier
+}
+
+func NewNotificationChannelHandler(logger *zap.Logger, service *service.NotificationChannelService, notifier *service.Notifier) *NotificationChannelHandler {
+	return &NotificationChannelHandler{
+		logger:   logger,
+		service:  service,
+		notifier: notifier,
+	}
+}
+
+// List 获取所有通知渠道
+func (h *NotificationChannelHandler) List(c echo.Context) error {
+	channels, err := h.service.List(c.Request().Context())
+	if err != nil {
+		return err
+	}
+	for i := range channels {
+		redactChannel(&channels[i])
+	}
+	return orz.Ok(c, channels)
+}
+
+// ListTypes 获取支持的通知渠道类型及其配置字段
+func (h *NotificationChannelHandler) ListTypes(c echo.Context) error {
+	return orz.Ok(c, service.SupportedChannelTypes())
+}
+
+// Get 获取通知渠道
+func (h *NotificationChannelHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+	channel, err := h.service.Get(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, redactChannel(channel))
+}
+
+// Create 创建通知渠道
+func (h *NotificationChannelHandler) Create(c echo.Context) error {
+	var req models.NotificationChannelConfig
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	channel, err := h.service.Create(c.Request().Context(), req)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, redactChannel(channel))
+}
+
+// Update 更新通知渠道
+func (h *NotificationChannelHandler) Update(c echo.Context) error {
+	id := c.Param("id")
+
+	var req models.NotificationChannelConfig
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	channel, err := h.service.Update(c.Request().Context(), id, req)
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, redactChannel(channel))
+}
+
+// Delete 删除通知渠道
+func (h *NotificationChannelHandler) Delete(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"message": "通知渠道删除成功",
+	})
+}
+
+// ScheduleStatus 获取通知渠道当前是否处于生效时间段及下一次生效时间
+func (h *NotificationChannelHandler) ScheduleStatus(c echo.Context) error {
+	channel, err := h.service.Get(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	status, err := service.GetChannelScheduleStatus(channel.Config, time.Now())
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+	return orz.Ok(c, status)
+}
+
+// Stats 获取各渠道最近一分钟、一小时的通知发送量和成功率（内存统计，重启后清零）
+func (h *NotificationChannelHandler) Stats(c echo.Context) error {
+	return orz.Ok(c, h.notifier.NotificationStats())
+}
+
+// Clone 复制通知渠道
+func (h *NotificationChannelHandler) Clone(c echo.Context) error {
+	channel, err := h.service.Clone(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+	return orz.Ok(c, redactChannel(channel))
+}
+
+// Enable 启用通知渠道
+func (h *NotificationChannelHandler) Enable(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.SetEnabled(c.Request().Context(), id, true); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"message": "通知渠道启用成功",
+	})
+}
+
+// Disable 禁用通知渠道
+func (h *NotificationChannelHandler) Disable(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.SetEnabled(c.Request().Context(), id, false); err != nil {
+		return err
+	}
+	return orz.Ok(c, orz.Map{
+		"message": "通知渠道禁用成功",
+	})
+}
+
+// Test 测试通知渠道（按渠道ID查找，兼容按类型查找）
+func (h *NotificationChannelHandler) Test(c echo.Context) error {
+	key := c.Param("id")
+	if key == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "缺少渠道参数",
+		})
+	}
+
+	ctx := c.Request().Context()
+
+	targetChannel, err := h.service.FindByIDOrType(ctx, key)
+	if err != nil {
+		return err
+	}
+	channelType := targetChannel.Type
+
+	if !targetChannel.Enabled {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "通知渠道未启用",
+		})
+	}
+
+	// 邮件渠道支持仅测试 SMTP 连通性与认证，不发送邮件
+	if c.QueryParam("mode") == "connectivity" {
+		if targetChannel.Type != "email" {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": "仅邮件渠道支持连通性测试",
+			})
+		}
+		if err := h.notifier.TestSMTPConnection(ctx, targetChannel.Config); err != nil {
+			h.logger.Error("SMTP连通性测试失败", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "SMTP连通性测试失败: " + err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, map[string]string{
+			"message": "SMTP连接与认证成功",
+		})
+	}
+
+	// 发送测试消息，经渠道注册表分发，与告警使用相同的代理、超时、重试和并发限制
+	messageID, sendErr := h.notifier.SendRawByConfig(ctx, &targetChannel.NotificationChannelConfig, service.BuildTestMessage())
+	if errors.Is(sendErr, service.ErrUnsupportedChannelType) {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "不支持的通知渠道类型",
+		})
+	}
+	if sendErr != nil {
+		h.logger.Error("发送测试通知失败", zap.String("type", channelType), zap.Error(sendErr))
+		// 多接收方渠道返回每个接收方的投递结果
+		var recipientErr *service.RecipientError
+		if errors.As(sendErr, &recipientErr) {
+			return c.JSON(http.StatusInternalServerError, map[string]interface{}{
+				"error":      "发送测试通知失败: " + targetChannel.DisplayName() + ": " + sendErr.Error(),
+				"recipients": recipientErr.Results,
+			})
+		}
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "发送测试通知失败: " + targetChannel.DisplayName() + ": " + sendErr.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"message":   "测试通知已发送",
+		"messageId": messageID,
+	})
+}
+
+// Preview 预览示例告警在指定平台下各格式（文本、Markdown、卡片）的渲染结果
+func (h *NotificationChannelHandler) Preview(c echo.Context) error {
+	var req struct {
+		Platform string                 `json:"platform"`
+		Config   map[string]interface{} `json:"config"`
+		Agent    *models.Agent          `json:"agent"`
+		Record   *models.AlertRecord    `json:"record"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+	if req.Platform == "" {
+		return orz.NewError(400, "缺少平台参数")
+	}
+
+	req.Agent, req.Record = sampleAlert(req.Agent, req.Record)
+
+	preview, err := h.notifier.PreviewMessage(c.Request().Context(), req.Platform, req.Config, req.Agent, req.Record)
+	if err != nil {
+		return orz.NewError(400, err.Error())
+	}
+	return orz.Ok(c, preview)
+}
+
+// DryRun 演练模式：渲染示例告警在各渠道下实际会发送的请求，但不发送
+// 未提供渠道配置时使用已保存的全部渠道
+func (h *NotificationChannelHandler) DryRun(c echo.Context) error {
+	var req struct {
+		Channels []models.NotificationChannelConfig `json:"channels"`
+		Agent    *models.Agent                      `json:"agent"`
+		Record   *models.AlertRecord                `json:"record"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return orz.NewError(400, "请求参数错误")
+	}
+
+	ctx := c.Request().Context()
+	if len(req.Channels) == 0 {
+		channels, err := h.service.GetChannelConfigs(ctx)
+		if err != nil {
+			return err
+		}
+		req.Channels = channels
+	}
+	req.Agent, req.Record = sampleAlert(req.Agent, req.Record)
+
+	return orz.Ok(c, h.notifier.DryRunNotificationByConfigs(ctx, req.Channels, req.Record, req.Agent))
+}
+
+// redactChannel 返回给前端前将敏感字段替换为占位值，更新时原样提交占位值表示保留原值
+func redactChannel(channel *models.NotificationChannel) *models.NotificationChannel {
+	channel.NotificationChannelConfig = service.RedactChannelSecrets(channel.NotificationChannelConfig)
+	return channel
+}
+
+// sampleAlert 未提供示例数据时使用内置的示例探针和告警
+func sampleAlert(agent *models.Agent, record *models.AlertRecord) (*models.Agent, *models.AlertRecord) {
+	if agent == nil {
+		agent = &models.Agent{
+			ID:       "sample-agent",
+			Name:     "示例探针",
+			Hostname: "sample-host",
+			IP:       "192.168.1.100",
+		}
+	}
+	if record == nil {
+		record = &models.AlertRecord{
+			AgentID:     agent.ID,
+			AlertType:   "cpu",
+			Message:     "CPU使用率持续超过阈值",
+			Threshold:   80,
+			ActualValue: 92.5,
+			Level:       "warning",
+			Status:      "firing",
+			FiredAt:     time.Now().UnixMilli(),
+		}
+	}
+	return agent, record
+}
+
+// FeishuCallback 接收飞书卡片交互回调（公开接口，通过签名和 Token 校验来源）
+func (h *NotificationChannelHandler) FeishuCallback(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	channel, err := h.service.Get(ctx, c.Param("id"))
+	if err != nil || channel.Type != "feishu" {
+		return c.JSON(http.StatusNotFound, map[string]string{
+			"error": "通知渠道不存在",
+		})
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request().Body, 1<<20))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "读取请求失败",
+		})
+	}
+
+	callback, err := service.VerifyFeishuCallback(channel.Config, c.Request().Header, body, time.Now())
+	if err != nil {
+		h.logger.Warn("飞书回调校验失败", zap.String("channelId", channel.ID), zap.Error(err))
+		if errors.Is(err, service.ErrFeishuCallbackUnauthorized) {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": "回调校验失败",
+			})
+		}
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "无效的回调请求",
+		})
+	}
+
+	// 地址校验
+	if callback.Type == "url_verification" {
+		return c.JSON(http.StatusOK, map[string]string{
+			"challenge": callback.Challenge,
+		})
+	}
+
+	if action := callback.CardAction(); action != nil {
+		h.logger.Info("收到飞书卡片交互",
+			zap.String("channelId", channel.ID),
+			zap.String("tag", action.Tag),
+			zap.Any("value", action.Value),
+		)
+	}
+
+	return c.JSON(http.StatusOK, map[string]interface{}{})
+}
@@ -1,28 +1,36 @@
 package handler
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/service"
 	"github.com/go-orz/orz"
 	"github.com/labstack/echo/v4"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 )
 
 type PropertyHandler struct {
-	logger   *zap.Logger
-	service  *service.PropertyService
-	notifier *service.Notifier
+	logger        *zap.Logger
+	service       *service.PropertyService
+	bundleService *service.PropertyBundleService
+	notifier      *service.Notifier
 }
 
-func NewPropertyHandler(logger *zap.Logger, service *service.PropertyService, notifier *service.Notifier) *PropertyHandler {
+func NewPropertyHandler(logger *zap.Logger, service *service.PropertyService, bundleService *service.PropertyBundleService, notifier *service.Notifier) *PropertyHandler {
 	return &PropertyHandler{
-		logger:   logger,
-		service:  service,
-		notifier: notifier,
+		logger:        logger,
+		service:       service,
+		bundleService: bundleService,
+		notifier:      notifier,
 	}
 }
 
@@ -30,30 +38,98 @@ func NewPropertyHandler(logger *zap.Logger, service *service.PropertyService, no
 func (h *PropertyHandler) GetProperty(c echo.Context) error {
 	id := c.Param("id")
 
-	property, err := h.service.Get(c.Request().Context(), id)
+	property, value, err := h.propertyValue(c.Request().Context(), id)
 	if err != nil {
+		var corruptErr *service.CorruptPropertyError
+		if errors.As(err, &corruptErr) {
+			// 返回原始值，前端可展示并重新保存以修复
+			return c.JSON(http.StatusUnprocessableEntity, map[string]interface{}{
+				"error": "属性值不是合法的 JSON，请修正后重新保存",
+				"id":    property.ID,
+				"name":  property.Name,
+				"raw":   corruptErr.Raw,
+			})
+		}
 		h.logger.Error("获取属性失败", zap.String("id", id), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "获取属性失败",
 		})
 	}
 
-	// 解析 JSON 值
-	var value interface{}
-	if property.Value != "" {
-		if err := json.Unmarshal([]byte(property.Value), &value); err != nil {
-			h.logger.Error("解析属性值失败", zap.String("id", id), zap.Error(err))
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"id":      property.ID,
+		"name":    property.Name,
+		"value":   value,
+		"version": property.Version,
+	})
+}
+
+// GetProperties 批量获取属性，ids 为逗号分隔的属性ID，返回 id 到属性的映射，不存在的属性不返回
+// 值损坏的属性返回 error 和原始值 raw，与 GetProperty 一致
+func (h *PropertyHandler) GetProperties(c echo.Context) error {
+	ctx := c.Request().Context()
+	result := make(map[string]interface{})
+	for _, id := range strings.Split(c.QueryParam("ids"), ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if _, ok := result[id]; ok {
+			continue
+		}
+
+		property, value, err := h.propertyValue(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			var corruptErr *service.CorruptPropertyError
+			if errors.As(err, &corruptErr) {
+				result[id] = map[string]interface{}{
+					"name":  property.Name,
+					"error": "属性值不是合法的 JSON，请修正后重新保存",
+					"raw":   corruptErr.Raw,
+				}
+				continue
+			}
+			h.logger.Error("获取属性失败", zap.String("id", id), zap.Error(err))
 			return c.JSON(http.StatusInternalServerError, map[string]string{
-				"error": "解析属性值失败",
+				"error": "获取属性失败",
 			})
 		}
+		result[id] = map[string]interface{}{
+			"name":    property.Name,
+			"value":   value,
+			"version": property.Version,
+		}
 	}
+	return c.JSON(http.StatusOK, result)
+}
 
-	return c.JSON(http.StatusOK, map[string]interface{}{
-		"id":    property.ID,
-		"name":  property.Name,
-		"value": value,
-	})
+// propertyValue 获取属性及解析后的 JSON 值，通知渠道的敏感字段以占位值代替，不返回给前端
+// 值损坏时返回 *service.CorruptPropertyError，此时 property 仍有效
+func (h *PropertyHandler) propertyValue(ctx context.Context, id string) (models.Property, interface{}, error) {
+	property, err := h.service.Get(ctx, id)
+	if err != nil {
+		return property, nil, err
+	}
+
+	var value interface{}
+	if err := h.service.GetValue(ctx, id, &value); err != nil {
+		return property, nil, err
+	}
+
+	if id == service.PropertyIDNotificationChannels {
+		channels, err := parseNotificationChannels(value)
+		if err != nil {
+			return property, nil, err
+		}
+		for i := range channels {
+			channels[i] = service.RedactChannelSecrets(channels[i])
+		}
+		value = channels
+	}
+	return property, value, nil
 }
 
 // SetProperty 设置属性
@@ -63,6 +139,8 @@ func (h *PropertyHandler) SetProperty(c echo.Context) error {
 	var req struct {
 		Name  string      `json:"name"`
 		Value interface{} `json:"value"`
+		// 读取时的版本号，不为空时若已被其他人修改则返回 409
+		IfVersion *int64 `json:"ifVersion"`
 	}
 
 	if err := c.Bind(&req); err != nil {
@@ -71,18 +149,190 @@ func (h *PropertyHandler) SetProperty(c echo.Context) error {
 		})
 	}
 
-	if err := h.service.Set(c.Request().Context(), id, req.Name, req.Value); err != nil {
+	if errs := service.ValidatePropertyValue(id, req.Value); len(errs) > 0 {
+		return c.JSON(http.StatusBadRequest, map[string]interface{}{
+			"error":  "属性值不符合格式要求",
+			"errors": errs,
+		})
+	}
+
+	if id == service.PropertyIDNotificationChannels {
+		channels, err := parseNotificationChannels(req.Value)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":  "通知渠道配置校验失败",
+				"errors": []string{err.Error()},
+			})
+		}
+		// 仍为占位值的敏感字段保留已保存的值
+		channels, err = h.service.RestoreRedactedChannelSecrets(c.Request().Context(), channels)
+		if err != nil {
+			h.logger.Error("读取已保存的通知渠道配置失败", zap.Error(err))
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": "设置属性失败",
+			})
+		}
+		if errs := service.ValidateChannelConfigs(channels); len(errs) > 0 {
+			return c.JSON(http.StatusBadRequest, map[string]interface{}{
+				"error":  "通知渠道配置校验失败",
+				"errors": errs,
+			})
+		}
+		req.Value = channels
+	}
+
+	if id == service.PropertyIDSystemConfig {
+		if err := validateSystemConfig(req.Value); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	version, err := h.service.SetWithVersion(c.Request().Context(), id, req.Name, req.Value, req.IfVersion)
+	if err != nil {
+		if errors.Is(err, service.ErrPropertyVersionConflict) {
+			return c.JSON(http.StatusConflict, map[string]string{
+				"error": "配置已被其他人修改，请刷新后重试",
+			})
+		}
 		h.logger.Error("设置属性失败", zap.String("id", id), zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{
 			"error": "设置属性失败",
 		})
 	}
 
-	return c.JSON(http.StatusOK, map[string]string{
+	resp := map[string]interface{}{
 		"message": "设置成功",
+		"version": version,
+	}
+	if id == service.PropertyIDNotificationChannels {
+		if results := h.verifyWebhookChannels(c, req.Value); len(results) > 0 {
+			resp["verification"] = results
+		}
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// DeleteProperty 删除属性
+func (h *PropertyHandler) DeleteProperty(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.service.Delete(c.Request().Context(), id); err != nil {
+		h.logger.Error("删除属性失败", zap.String("id", id), zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "删除属性失败",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]string{
+		"message": "删除成功",
 	})
 }
 
+// ListPropertyAudits 分页获取属性的变更审计记录
+func (h *PropertyHandler) ListPropertyAudits(c echo.Context) error {
+	id := c.Param("id")
+	page, err := h.service.ListAudits(c.Request().Context(), id, orz.GetPageRequest(c))
+	if err != nil {
+		h.logger.Error("获取属性审计记录失败", zap.String("id", id), zap.Error(err))
+		return err
+	}
+	return orz.Ok(c, page)
+}
+
+// GetMutedAlertTypes 获取全局静音的告警类型
+func (h *PropertyHandler) GetMutedAlertTypes(c echo.Context) error {
+	systemConfig, err := h.service.GetSystemConfig(c.Request().Context())
+	if err != nil {
+		h.logger.Error("获取系统配置失败", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "获取系统配置失败",
+		})
+	}
+	mutedAlertTypes := systemConfig.MutedAlertTypes
+	if mutedAlertTypes == nil {
+		mutedAlertTypes = []string{}
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"alertTypes": mutedAlertTypes,
+	})
+}
+
+// SetMutedAlertTypes 设置全局静音的告警类型，无需逐个修改通知渠道
+func (h *PropertyHandler) SetMutedAlertTypes(c echo.Context) error {
+	var req struct {
+		AlertTypes []string `json:"alertTypes"`
+	}
+	if err := c.Bind(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{
+			"error": "请求参数错误",
+		})
+	}
+
+	systemConfig, err := h.service.SetMutedAlertTypes(c.Request().Context(), req.AlertTypes)
+	if err != nil {
+		h.logger.Error("设置静音告警类型失败", zap.Error(err))
+		return c.JSON(http.StatusInternalServerError, map[string]string{
+			"error": "设置静音告警类型失败",
+		})
+	}
+	return c.JSON(http.StatusOK, map[string]interface{}{
+		"alertTypes": systemConfig.MutedAlertTypes,
+	})
+}
+
+// parseNotificationChannels 将属性值解析为通知渠道配置列表
+func parseNotificationChannels(value interface{}) ([]models.NotificationChannelConfig, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var channels []models.NotificationChannelConfig
+	if err := json.Unmarshal(data, &channels); err != nil {
+		return nil, fmt.Errorf("通知渠道配置格式错误: %w", err)
+	}
+	return channels, nil
+}
+
+// validateSystemConfig 校验系统配置
+func validateSystemConfig(value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	var systemConfig models.SystemConfig
+	if err := json.Unmarshal(data, &systemConfig); err != nil {
+		return err
+	}
+	return systemConfig.Validate()
+}
+
+// verifyWebhookChannels 对开启了 verifyOnSave 的自定义Webhook渠道做连通性探测
+// 探测结果仅作为提示返回，不影响保存
+func (h *PropertyHandler) verifyWebhookChannels(c echo.Context, value interface{}) []service.WebhookProbeResult {
+	channels, err := parseNotificationChannels(value)
+	if err != nil {
+		return nil
+	}
+
+	var results []service.WebhookProbeResult
+	for _, channel := range channels {
+		if channel.Type != "webhook" {
+			continue
+		}
+		if verify, _ := channel.Config["verifyOnSave"].(bool); !verify {
+			continue
+		}
+		result := h.notifier.ProbeWebhook(c.Request().Context(), channel.Config)
+		result.Name = channel.Name
+		if !result.Reachable {
+			h.logger.Warn("自定义Webhook地址不可达", zap.String("url", result.URL), zap.String("error", result.Error))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
 // GetLogo 获取系统 Logo（公开访问，返回图片文件流）
 func (h *PropertyHandler) GetLogo(c echo.Context) error {
 	sysConfig, err := h.service.GetSystemConfig(c.Request().Context())
@@ -158,73 +408,41 @@ func (h *PropertyHandler) GetMetricsConfig(c echo.Context) error {
 	})
 }
 
-// TestNotificationChannel 测试通知渠道（从数据库读取配置）
-func (h *PropertyHandler) TestNotificationChannel(c echo.Context) error {
-	channelType := c.Param("type")
-	if channelType == "" {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "缺少渠道类型参数",
-		})
-	}
-
-	ctx := c.Request().Context()
-
-	channels, err := h.service.GetNotificationChannelConfigs(c.Request().Context())
+// ExportProperties 导出所有属性和通知渠道为一个 JSON 文件，includeSecrets=true 时包含通知渠道敏感字段的原值
+func (h *PropertyHandler) ExportProperties(c echo.Context) error {
+	includeSecrets := c.QueryParam("includeSecrets") == "true"
+	bundle, err := h.bundleService.ExportBundle(c.Request().Context(), includeSecrets)
 	if err != nil {
-		h.logger.Error("获取通知渠道配置失败", zap.Error(err))
+		h.logger.Error("导出属性失败", zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "获取通知渠道配置失败",
-		})
-	}
-
-	// 查找指定类型的渠道
-	var targetChannel *models.NotificationChannelConfig
-	for i := range channels {
-		if channels[i].Type == channelType {
-			targetChannel = &channels[i]
-			break
-		}
-	}
-
-	if targetChannel == nil {
-		return c.JSON(http.StatusNotFound, map[string]string{
-			"error": "通知渠道不存在，请先配置",
-		})
-	}
-
-	if !targetChannel.Enabled {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "通知渠道未启用",
+			"error": "导出属性失败",
 		})
 	}
+	filename := fmt.Sprintf("pika-properties-%s.json", time.UnixMilli(bundle.ExportedAt).Format("20060102150405"))
+	c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	return c.JSON(http.StatusOK, bundle)
+}
 
-	// 发送测试消息
-	message := "这是一条测试通知消息"
-
-	var sendErr error
-	switch targetChannel.Type {
-	case "dingtalk":
-		sendErr = h.notifier.SendDingTalkByConfig(ctx, targetChannel.Config, message)
-	case "wecom":
-		sendErr = h.notifier.SendWeComByConfig(ctx, targetChannel.Config, message)
-	case "feishu":
-		sendErr = h.notifier.SendFeishuByConfig(ctx, targetChannel.Config, message)
-	case "webhook":
-		sendErr = h.notifier.SendWebhookByConfig(ctx, targetChannel.Config, message)
-	default:
+// ImportProperties 导入属性和通知渠道，mode=merge（默认）仅覆盖文件中的内容，mode=replace 同时删除文件中没有的属性和通知渠道
+func (h *PropertyHandler) ImportProperties(c echo.Context) error {
+	var bundle service.PropertyBundle
+	if err := c.Bind(&bundle); err != nil {
 		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "不支持的通知渠道类型",
+			"error": "无效的请求参数",
 		})
 	}
 
-	if sendErr != nil {
-		h.logger.Error("发送测试通知失败", zap.String("type", channelType), zap.Error(sendErr))
+	if err := h.bundleService.ImportBundle(c.Request().Context(), &bundle, c.QueryParam("mode")); err != nil {
+		var orzErr *orz.Error
+		if errors.As(err, &orzErr) {
+			return err
+		}
+		h.logger.Error("导入属性失败", zap.Error(err))
 		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "发送测试通知失败: " + sendErr.Error(),
+			"error": "导入属性失败",
 		})
 	}
-
 	return c.JSON(http.StatusOK, map[string]string{
-		"message": "测试通知已发送",
+		"message": "导入成功",
 	})
 }
@@ -1,11 +1,21 @@
 package models
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"gorm.io/datatypes"
+)
+
 // AlertRecord 告警记录
 type AlertRecord struct {
 	ID          int64   `gorm:"primaryKey;autoIncrement" json:"id"`    // 记录ID
 	AgentID     string  `gorm:"index" json:"agentId"`                  // 探针ID
 	AgentName   string  `json:"agentName"`                             // 探针名称
 	AlertType   string  `json:"alertType"`                             // 告警类型: cpu, memory, disk, network
+	MonitorID   string  `json:"monitorId,omitempty"`                   // 监控项ID（证书、服务下线等按监控项产生的告警）
 	Message     string  `json:"message"`                               // 告警消息
 	Threshold   float64 `json:"threshold"`                             // 告警阈值
 	ActualValue float64 `json:"actualValue"`                           // 实际值
@@ -15,12 +25,47 @@ type AlertRecord struct {
 	ResolvedAt  int64   `json:"resolvedAt,omitempty"`                  // 恢复时间（时间戳毫秒）
 	CreatedAt   int64   `json:"createdAt"`                             // 创建时间（时间戳毫秒）
 	UpdatedAt   int64   `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+
+	RecentValues datatypes.JSONSlice[float64] `json:"recentValues,omitempty"`                            // 触发前的近期采样值（从旧到新）
+	Labels       map[string]string            `gorm:"serializer:json;type:text" json:"labels,omitempty"` // 告警标签（来自告警规则），随通知透传给下游
+	Replay       bool                         `gorm:"-" json:"replay,omitempty"`                         // 是否为重放的历史告警（不持久化）
 }
 
 func (AlertRecord) TableName() string {
 	return "alert_records"
 }
 
+// DedupKey 告警的关联键，由探针ID、告警类型、监控项ID和标签计算得到的稳定哈希
+// 同一告警的触发与恢复得到相同的值，去重、确认、升级等功能应统一使用该键
+func (r *AlertRecord) DedupKey() string {
+	var b strings.Builder
+	b.WriteString(r.AgentID)
+	b.WriteString("\x00")
+	b.WriteString(r.AlertType)
+	// 同一探针上的多个监控项各自独立告警
+	if r.MonitorID != "" {
+		b.WriteString("\x00monitor=")
+		b.WriteString(r.MonitorID)
+	}
+
+	if len(r.Labels) > 0 {
+		keys := make([]string, 0, len(r.Labels))
+		for k := range r.Labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			b.WriteString("\x00")
+			b.WriteString(k)
+			b.WriteString("=")
+			b.WriteString(r.Labels[k])
+		}
+	}
+
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:16])
+}
+
 // AlertState 告警状态（持久化到数据库，用于判断是否持续超过阈值）
 type AlertState struct {
 	ID            string  `gorm:"primaryKey" json:"id"`                  // 状态ID（格式：agentId:configId:alertType）
@@ -40,3 +85,57 @@ type AlertState struct {
 func (AlertState) TableName() string {
 	return "alert_states"
 }
+
+// AlertSnooze 告警暂停通知（针对单个探针的某类告警，到期后自动恢复通知）
+type AlertSnooze struct {
+	AgentID   string `json:"agentId"`   // 探针ID
+	AlertType string `json:"alertType"` // 告警类型
+	ExpiresAt int64  `json:"expiresAt"` // 到期时间（时间戳毫秒）
+	CreatedAt int64  `json:"createdAt"` // 创建时间（时间戳毫秒）
+}
+
+// Active 是否仍在暂停期内
+func (s AlertSnooze) Active(now int64) bool {
+	return s.ExpiresAt > now
+}
+
+// SilenceRule 静默规则，用于计划内的维护窗口，命中的告警不发送通知
+// 可设置起止时间，也可设置每周重复的时间段，两者同时设置时需同时满足
+type SilenceRule struct {
+	ID        string         `json:"id"`                  // 规则ID
+	AgentID   string         `json:"agentId,omitempty"`   // 探针ID，为空表示所有探针
+	AlertType string         `json:"alertType,omitempty"` // 告警类型，为空表示所有类型
+	StartAt   int64          `json:"startAt,omitempty"`   // 开始时间（时间戳毫秒），为空表示立即生效
+	EndAt     int64          `json:"endAt,omitempty"`     // 结束时间（时间戳毫秒），为空表示手动删除前一直生效
+	Schedule  *SilenceWindow `json:"schedule,omitempty"`  // 每周重复的静默时间段
+	Comment   string         `json:"comment,omitempty"`   // 备注，如维护内容
+	CreatedAt int64          `json:"createdAt"`           // 创建时间（时间戳毫秒）
+}
+
+// SilenceWindow 每周重复的静默时间段，start 晚于 end 时表示跨天，days 为空表示每天，0 为周日
+type SilenceWindow struct {
+	Start    string `json:"start"`              // 开始时间 HH:MM
+	End      string `json:"end"`                // 结束时间 HH:MM
+	Days     []int  `json:"days,omitempty"`     // 星期
+	Timezone string `json:"timezone,omitempty"` // 时区，如 Asia/Shanghai，为空表示服务器时区
+}
+
+// Matches 规则是否作用于指定探针的某类告警
+func (r SilenceRule) Matches(agentID, alertType string) bool {
+	return (r.AgentID == "" || r.AgentID == agentID) && (r.AlertType == "" || r.AlertType == alertType)
+}
+
+// Expired 规则是否已过结束时间
+func (r SilenceRule) Expired(now int64) bool {
+	return r.EndAt > 0 && r.EndAt <= now
+}
+
+// AlertSuppression 当前生效的告警抑制项（暂停通知、全局静音、静默规则）
+type AlertSuppression struct {
+	Kind        string `json:"kind"`                  // 类型：snooze(暂停通知), mute(全局静音), silence(静默规则)
+	AgentID     string `json:"agentId,omitempty"`     // 探针ID，为空表示所有探针
+	AlertType   string `json:"alertType"`             // 告警类型
+	ExpiresAt   int64  `json:"expiresAt,omitempty"`   // 到期时间（时间戳毫秒），为空表示手动解除前一直生效
+	RemainingMs int64  `json:"remainingMs,omitempty"` // 剩余时长（毫秒）
+	CreatedAt   int64  `json:"createdAt,omitempty"`   // 创建时间（时间戳毫秒）
+}
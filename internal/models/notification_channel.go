@@ -0,0 +1,12 @@
+package models
+
+// NotificationChannel 通知渠道（每个渠道一行，替代 Property 中的整体 JSON 配置）
+type NotificationChannel struct {
+	NotificationChannelConfig `gorm:"embedded"`
+	CreatedAt                 int64 `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt                 int64 `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (NotificationChannel) TableName() string {
+	return "notification_channels"
+}
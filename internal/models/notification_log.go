@@ -0,0 +1,18 @@
+package models
+
+// NotificationLog 通知发送记录，每次向渠道发送告警通知（含重试）记录一条，用于排查通知是否送达
+type NotificationLog struct {
+	ID            int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	Timestamp     int64  `gorm:"index" json:"timestamp"`     // 发送时间（时间戳毫秒）
+	ChannelID     string `gorm:"index" json:"channelId"`     // 通知渠道ID
+	ChannelType   string `gorm:"index" json:"channelType"`   // 通知渠道类型
+	AlertRecordID int64  `gorm:"index" json:"alertRecordId"` // 告警记录ID
+	AgentID       string `gorm:"index" json:"agentId"`       // 探针ID
+	Success       bool   `gorm:"index" json:"success"`       // 是否发送成功
+	ErrorMessage  string `json:"errorMessage,omitempty"`     // 失败原因
+	DurationMs    int64  `json:"durationMs"`                 // 发送耗时（毫秒）
+}
+
+func (NotificationLog) TableName() string {
+	return "notification_logs"
+}
@@ -0,0 +1,26 @@
+package models
+
+const (
+	// NotificationRetryPending 等待重试
+	NotificationRetryPending = "pending"
+	// NotificationRetryDead 超过最大重试次数，进入死信队列，不再自动重试
+	NotificationRetryDead = "dead"
+)
+
+// NotificationRetry 发送失败待重试的通知（持久化，重启后继续重试）
+type NotificationRetry struct {
+	ID            int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	ChannelID     string `gorm:"index" json:"channelId"`                // 通知渠道ID
+	RecordID      int64  `gorm:"index" json:"recordId"`                 // 告警记录ID
+	RecordStatus  string `json:"recordStatus"`                          // 入队时的告警状态，状态变化后旧通知不再重试
+	Attempts      int    `json:"attempts"`                              // 已重试次数
+	Status        string `gorm:"index" json:"status"`                   // 状态: pending, dead
+	NextAttemptAt int64  `gorm:"index" json:"nextAttemptAt"`            // 下次重试时间（时间戳毫秒）
+	LastError     string `json:"lastError"`                             // 最近一次失败原因
+	CreatedAt     int64  `json:"createdAt"`                             // 创建时间（时间戳毫秒）
+	UpdatedAt     int64  `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
+}
+
+func (NotificationRetry) TableName() string {
+	return "notification_retries"
+}
@@ -1,10 +1,18 @@
 package models
 
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
 // Property 通用属性配置表
 type Property struct {
 	ID        string `gorm:"primaryKey" json:"id"`                  // 属性ID (如: notification_channels)
 	Name      string `json:"name"`                                  // 可读名称
 	Value     string `json:"value" gorm:"type:text"`                // JSON配置
+	Version   int64  `json:"version" gorm:"not null;default:0"`     // 版本号，每次保存递增，用于检测并发修改
 	CreatedAt int64  `json:"createdAt"`                             // 创建时间（时间戳毫秒）
 	UpdatedAt int64  `json:"updatedAt" gorm:"autoUpdateTime:milli"` // 更新时间（时间戳毫秒）
 }
@@ -13,23 +21,102 @@ func (Property) TableName() string {
 	return "properties"
 }
 
-// NotificationChannelConfig 通知渠道配置（存储在 Property 中）
+// NotificationChannelConfig 通知渠道配置
+// 同时作为 NotificationChannel 表的内嵌字段，旧版本存储在 Property 中
 type NotificationChannelConfig struct {
-	Type    string                 `json:"type"`    // 类型: dingtalk, wecom, feishu, webhook
-	Enabled bool                   `json:"enabled"` // 是否启用
-	Config  map[string]interface{} `json:"config"`  // 配置对象
+	ID          string                 `gorm:"primaryKey" json:"id"`                    // 渠道ID (UUID)
+	Name        string                 `json:"name"`                                    // 渠道名称
+	Description string                 `json:"description"`                             // 渠道描述，发送失败时用于定位具体渠道
+	Type        string                 `gorm:"index" json:"type"`                       // 类型: dingtalk, wecom, feishu, webhook
+	Enabled     bool                   `json:"enabled"`                                 // 是否启用
+	TestOnly    bool                   `json:"testOnly"`                                // 仅用于测试：可通过测试接口发送，但不接收真实告警
+	Fallback    bool                   `json:"fallback"`                                // 备用渠道：仅当所有主渠道都发送失败时才发送
+	Priority    int                    `json:"priority"`                                // 优先级：数值越大越先发送
+	Config      map[string]interface{} `gorm:"serializer:json;type:text" json:"config"` // 配置对象
+}
+
+// DisplayName 渠道的可读名称，优先使用描述，其次名称，最后类型
+func (c *NotificationChannelConfig) DisplayName() string {
+	if c.Description != "" {
+		return c.Description
+	}
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type
 }
 
 // 配置格式说明：
 // dingtalk: { "secretKey": "xxx", "signSecret": "xxx" }
+//           可选 "robots": [{"secretKey": "xxx", "signSecret": "xxx"}] 同时发送到多个群，失败时返回每个群的投递结果
+//           可选 "msgType": "text"(默认) | "markdown" | "actionCard"，markdown 以加粗标题和字段表格展示；
+//           actionCard 附带“查看详情”按钮，链接为 "baseUrl" 加探针ID，未配置 baseUrl 时按 markdown 发送
+// dingtalk/wecom 可选 "atMobiles": ["13800000000"]、"atAll": true 在告警中 @ 成员，"atOnlyOnFiring": true 时恢复通知不 @；
+//           企业微信仅群机器人模式支持 @
 // wecom:    { "secretKey": "xxx" }
-// feishu:   { "secretKey": "xxx", "signSecret": "xxx" }
+//           或群聊模式 { "mode": "appchat", "corpId": "xxx", "corpSecret": "xxx", "chatId": "xxx" }
+// feishu:   { "secretKey": "xxx", "signSecret": "xxx" }，配置 signSecret 时按飞书 timestamp + sign 方式签名
+//           可选 "format": "post"，以富文本发送：每个字段一行、字段名加粗，配置 baseUrl 时附详情链接，失败时回退为文本
+//           可选 "msgType": "interactive"，以消息卡片发送：标题栏按告警级别着色，字段两列排列，配置 baseUrl 时附详情按钮
+//           卡片交互回调地址 /api/notification-channels/{id}/feishu/callback，需配置 "encryptKey" 或 "verificationToken" 用于校验
+// 所有渠道可选 "format": "plain"，使用 [INFO]/[WARN]/[CRIT]/[OK] 文本标记代替 emoji，适用于短信等受限渠道
+// 所有渠道可选 "fields": ["probe", "host", "ip", "type", "message", "threshold", "value", "time"]，指定消息字段及顺序，
+// 未配置时使用完整布局；自定义字段时探针只显示名称，不暴露探针ID
+// 所有渠道可选 "showLabels": true，在消息中附带“标签:”一行；webhook 的 json/form 请求体始终包含标签，custom 模板可使用 {{alert.labels.<key>}}
+// 所有渠道可选 "resolvedDetail": false，关闭恢复消息中“恢复至 45.00%，低于阈值 80.00%”的阈值对比（默认附带）
+// 所有渠道可选 "locale": "en"（或 "lang"，支持 en-US 等带地区的写法），覆盖系统配置中的消息语言；不支持的语言依次回退到系统默认语言和 zh
+// 所有渠道可选 "template"（text/template）自定义告警和恢复消息，"firingTemplate"/"resolvedTemplate" 分别覆盖告警和恢复消息，
+// 可用变量 {{.Title}} {{.TypeName}} {{.Value}} {{.Threshold}} {{.FiredAt}} {{.ResolvedAt}} {{.Agent.Name}} {{.Alert.Message}} 等，
+// 辅助函数 {{formatTime .Alert.FiredAt}} {{levelIcon .Alert.Level}}，未配置的使用内置格式；模板在保存配置时校验
+// 所有渠道可选 "alertTypes": ["cert", "cpu"] 和 "minLevel": "warning"（info < warning < critical），
+// 只接收指定类型、不低于指定级别的告警及其恢复通知，未配置时接收所有告警
+// 所有渠道可选 "notifyOnResolve": false 或 {"info": false}，关闭全部或指定级别的恢复通知（默认发送）
+// 所有渠道可选 "suppressResolvedWhenOffline": true，探针离线时不发送 CPU/内存/磁盘/网络告警的恢复通知（离线导致的无数据恢复）
+// 所有渠道可选 "schedule": {"start": "08:00", "end": "22:00", "days": [1, 2, 3, 4, 5], "timezone": "Asia/Shanghai"}，
+// 生效时间段之外的通知暂缓到下一次生效时再发送
+// 所有渠道可选 "digest": {"enabled": true, "time": "09:00", "timezone": "Asia/Shanghai"}，
+// 开启后不再接收实时告警，每天在指定时间发送过去 24 小时按探针、告警类型分组的告警摘要
+// 所有渠道可选 "timeoutSeconds": 30，单次请求的超时时间（默认 10 秒），调用方设置了更早的截止时间时以其为准
+// 所有渠道可选 "maxRetries": 3，网络错误和 429/5xx 响应的最大重试次数（默认 3，0 表示不重试），按指数退避加随机抖动等待
+// 所有渠道可选 "maxConcurrent": 2，限制该渠道同时进行的发送数量（默认不限制），各渠道互不影响
+// 所有渠道可选 "proxyUrl": "http://proxy.example.com:3128"（支持 http/https/socks5），该渠道的 HTTP 请求经此代理发送；
+// 未配置时按 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量选择代理（邮件渠道不经过代理）
+// 所有渠道可选 "ratePerMinute": 10，限制该渠道每分钟发送的告警通知数量（令牌桶，默认不限制），
+// 超出的通知直接丢弃，下一条发出的通知中附带被抑制的数量；"rateLimitPerAgent": true 时按探针分别计数
+// 所有渠道可选 "groupAlerts": true，同一探针在一次检查中同时触发或恢复的多条指标告警合并为一条消息发送，
+// 消息以探针信息开头，每条告警一行并标明告警中/已恢复（"digest" 为每日摘要，与此不同）
+// wecom/feishu 可选 "baseUrl": "https://pika.example.com"，消息超出平台长度限制被截断时附上探针详情页链接；
+//           钉钉、ntfy、Bark 同样使用 baseUrl，旧配置中的 "dashboardUrl" 仍然兼容
+// telegram: { "botToken": "123456:ABC-xxx", "chatId": "-1001234567890", "messageThreadId": 42 }
+//           messageThreadId 可选，用于发送到话题群组的指定话题；消息以 HTML 模式发送
+// slack:    { "webhookUrl": "https://hooks.slack.com/services/xxx" }，消息以按告警级别着色的附件发送
+// discord:  { "webhookUrl": "https://discord.com/api/webhooks/xxx/xxx" }，消息以按告警级别着色的 embed 发送
+// teams:    { "webhookUrl": "https://xxx.webhook.office.com/webhookb2/xxx" }，Incoming Webhook 以按告警级别着色的 MessageCard 发送，
+//           Power Automate 工作流地址（*.logic.azure.com、*.powerplatform.com）以 Adaptive Card 发送
+// pagerduty: { "routingKey": "xxx" }，通过 Events API v2 发送 trigger 事件，恢复时发送 resolve 事件自动关闭，
+//           dedup_key 由探针ID和告警类型组成；通常配合 "minLevel": "critical" 只为严重告警创建事件
+// ntfy:     { "server": "https://ntfy.sh", "topic": "pika-alerts", "username": "xxx", "password": "xxx", "accessToken": "tk_xxx", "baseUrl": "https://pika.example.com" }
+//           server 默认 https://ntfy.sh；认证可选，accessToken 优先于用户名密码；优先级和标签按告警级别设置，配置 baseUrl 时点击通知打开探针详情页
+// bark:     { "server": "https://api.day.app", "deviceKey": "xxx", "group": "pika", "sound": "alarm", "baseUrl": "https://pika.example.com" }
+//           server 默认 https://api.day.app；警告级别以时效性通知（timeSensitive）、严重级别以重要警告（critical）推送
+// gotify:   { "server": "https://gotify.example.com", "token": "xxx" }，优先级按告警级别设置（info 4、warning 6、critical 8）
+// email:    { "smtpHost": "smtp.example.com", "smtpPort": 587, "username": "xxx", "password": "xxx", "from": "Pika <pika@example.com>", "to": ["ops@example.com"], "cc": ["leader@example.com"] }
+//           邮件同时包含纯文本和 HTML 正文，HTML 标题栏按告警级别着色；cc 可选；收件人和抄送在同一个 SMTP 会话中发送
+//           端口 465 使用隐式 TLS，其他端口在服务器支持时使用 STARTTLS
 // webhook:  {
 //   "url": "https://...",
 //   "method": "POST",  // 可选：GET, POST, PUT, PATCH, DELETE，默认 POST
 //   "headers": {"key": "value"},  // 可选：自定义请求头
 //   "bodyTemplate": "json"  // 可选：json(默认), form, custom
-//   "customBody": ""  // 当 bodyTemplate 为 custom 时使用，支持变量替换
+//   "schemaVersion": 1,  // 可选：固定 json 请求体的版本，1(默认) 为 msg_type/text 结构，2 为顶层 message 结构；请求体中带有 schemaVersion 字段
+//   "customBody": "",  // 当 bodyTemplate 为 custom 时使用，支持变量替换
+//   "charset": "gbk",  // 可选：请求体字符集，支持 utf-8(默认), gbk, gb18030
+//   "batch": {"enabled": true, "maxSize": 50, "flushIntervalSeconds": 5},  // 可选：json 模板下按批次以数组发送
+//   "verifyOnSave": true,  // 可选：保存配置时探测地址是否可达，结果仅作提示
+//   "signingSecret": "xxx",  // 可选：对请求体做 HMAC 签名，通过 X-Pika-Signature: <algorithm>=<hex> 请求头发送
+//   "signingAlgorithm": "sha256",  // 可选：签名算法 sha1, sha256(默认), sha512
+//   "bodyFormat": "text",  // 可选：bodyTemplate 为 json 时的请求体内容，text(默认) 为包装后的消息文本，json 为原始告警记录
+//   "payloadMode": "text"  // 可选：bodyTemplate 为 json 时，structured 发送 {"agent": {...}, "record": {...}, "message": "..."}，优先于 bodyFormat
 // }
 
 // WebhookConfig 自定义 Webhook 配置结构
@@ -39,6 +126,12 @@ type WebhookConfig struct {
 	Headers      map[string]string `json:"headers,omitempty"`      // 自定义请求头
 	BodyTemplate string            `json:"bodyTemplate,omitempty"` // 请求体模板：json, form, custom
 	CustomBody   string            `json:"customBody,omitempty"`   // 自定义请求体模板（支持变量）
+	Charset      string            `json:"charset,omitempty"`      // 请求体字符集：utf-8(默认), gbk, gb18030
+	BodyFormat   string            `json:"bodyFormat,omitempty"`   // json 模板的请求体内容：text(默认，包装后的消息文本), json(原始告警记录)
+	PayloadMode  string            `json:"payloadMode,omitempty"`  // json 模板的请求体结构：text(默认), structured(完整的 agent、record 和 message)
+
+	SigningSecret    string `json:"signingSecret,omitempty"`    // 请求体签名密钥
+	SigningAlgorithm string `json:"signingAlgorithm,omitempty"` // 签名算法：sha1, sha256(默认), sha512
 }
 
 type SystemConfig struct {
@@ -47,6 +140,49 @@ type SystemConfig struct {
 	LogoBase64   string `json:"logoBase64"`   // 系统logo（base64编码）
 	ICPCode      string `json:"icpCode"`      // ICP备案号
 	DefaultView  string `json:"defaultView"`  // 默认视图 grid | list
+
+	// 告警级别的展示方式（图标和名称），未配置的级别使用内置 emoji
+	Severities map[string]SeverityPresentation `json:"severities,omitempty"`
+	// 告警消息的默认语言 zh | en，渠道可通过 "locale" 单独指定
+	Locale string `json:"locale,omitempty"`
+	// 全局静音的告警类型，如 CA 迁移期间静音 cert，所有渠道均不发送该类型的通知
+	MutedAlertTypes []string `json:"mutedAlertTypes,omitempty"`
+}
+
+// SeverityPresentation 告警级别的展示方式
+type SeverityPresentation struct {
+	Icon string `json:"icon"` // 图标，如 🚨
+	Name string `json:"name"` // 显示名称，如 严重
+}
+
+// AlertLevels 支持的告警级别
+var AlertLevels = []string{"info", "warning", "critical"}
+
+const (
+	// maxSeverityIconLength 级别图标最大字符数（emoji 可能由多个码点组成）
+	maxSeverityIconLength = 8
+	// maxSeverityNameLength 级别名称最大字符数
+	maxSeverityNameLength = 16
+)
+
+// Validate 校验系统配置
+func (c *SystemConfig) Validate() error {
+	for level, severity := range c.Severities {
+		if !slices.Contains(AlertLevels, level) {
+			return fmt.Errorf("未知的告警级别: %s", level)
+		}
+		if n := utf8.RuneCountInString(severity.Icon); n == 0 || n > maxSeverityIconLength {
+			return fmt.Errorf("告警级别 %s 的图标不能为空且不能超过%d个字符", level, maxSeverityIconLength)
+		}
+		name := strings.TrimSpace(severity.Name)
+		if name == "" {
+			return fmt.Errorf("告警级别 %s 的名称不能为空", level)
+		}
+		if utf8.RuneCountInString(name) > maxSeverityNameLength {
+			return fmt.Errorf("告警级别 %s 的名称不能超过%d个字符", level, maxSeverityNameLength)
+		}
+	}
+	return nil
 }
 
 // TimeRangeOption 时间范围选项
@@ -74,8 +210,40 @@ type MetricsConfig struct {
 
 // AlertConfig 全局告警配置
 type AlertConfig struct {
-	Enabled bool       `json:"enabled"` // 是否启用全局告警
-	Rules   AlertRules `json:"rules"`   // 告警规则
+	Enabled      bool       `json:"enabled"`      // 是否启用全局告警
+	Rules        AlertRules `json:"rules"`        // 告警规则
+	IncludeTrend bool       `json:"includeTrend"` // 告警消息中是否附带近期采样值
+	TrendSamples int        `json:"trendSamples"` // 附带的近期采样值个数（默认5）
+	// 告警类型的默认级别，告警记录未设置级别时使用，未配置的类型使用 DefaultAlertLevels
+	DefaultLevels map[string]string `json:"defaultLevels,omitempty"`
+	// 跨探针告警聚合
+	Aggregation AlertAggregationConfig `json:"aggregation"`
+}
+
+// AlertAggregationConfig 跨探针告警聚合配置
+// 开启后，同一告警类型和级别的告警在窗口期内合并为一条“影响 N 个探针”的汇总消息
+type AlertAggregationConfig struct {
+	Enabled       bool `json:"enabled"`       // 是否启用聚合
+	WindowSeconds int  `json:"windowSeconds"` // 聚合窗口（秒，默认30）
+	SampleSize    int  `json:"sampleSize"`    // 汇总消息中列出的探针个数（默认5）
+}
+
+// DefaultAlertLevels 内置的告警类型默认级别
+var DefaultAlertLevels = map[string]string{
+	"tamper":        "critical",
+	"cert":          "critical",
+	"service":       "critical",
+	"agent_offline": "critical",
+}
+
+// DefaultLevel 获取告警类型的默认级别，优先使用配置，其次使用内置默认值
+func (c *AlertConfig) DefaultLevel(alertType string) string {
+	if c != nil {
+		if level, ok := c.DefaultLevels[alertType]; ok && level != "" {
+			return level
+		}
+	}
+	return DefaultAlertLevels[alertType]
 }
 
 // AlertRules 告警规则
@@ -111,4 +279,25 @@ type AlertRules struct {
 	// 探针离线告警配置
 	AgentOfflineEnabled  bool `json:"agentOfflineEnabled"`  // 是否启用探针离线告警
 	AgentOfflineDuration int  `json:"agentOfflineDuration"` // 持续时间（秒）
+
+	// 探针版本落后告警配置
+	VersionEnabled bool   `json:"versionEnabled"` // 是否启用版本落后告警
+	MinVersion     string `json:"minVersion"`     // 要求的最低探针版本，如 1.2.0
+
+	// 告警标签：按告警类型配置任意键值对，随告警通知透传，便于下游按标签路由
+	// 格式: {"cpu": {"team": "infra", "severity": "p2"}}
+	Labels map[string]map[string]string `json:"labels,omitempty"`
+}
+
+// LabelsFor 获取告警类型配置的标签，返回副本
+func (r AlertRules) LabelsFor(alertType string) map[string]string {
+	labels := r.Labels[alertType]
+	if len(labels) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(labels))
+	for k, v := range labels {
+		result[k] = v
+	}
+	return result
 }
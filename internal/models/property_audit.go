@@ -0,0 +1,24 @@
+package models
+
+const (
+	// PropertyAuditSet 设置属性
+	PropertyAuditSet = "set"
+	// PropertyAuditDelete 删除属性
+	PropertyAuditDelete = "delete"
+)
+
+// PropertyAudit 属性变更审计记录
+// 只保存变更前后值的 SHA-256 哈希，不保存原值，避免通知渠道密钥等敏感信息出现在审计记录中
+type PropertyAudit struct {
+	ID           int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	PropertyID   string `gorm:"index" json:"propertyId"` // 属性ID
+	Action       string `json:"action"`                  // 操作: set, delete
+	OldValueHash string `json:"oldValueHash,omitempty"`  // 变更前的值哈希，新建时为空
+	NewValueHash string `json:"newValueHash,omitempty"`  // 变更后的值哈希，删除时为空
+	Actor        string `gorm:"index" json:"actor"`      // 操作人，系统内部变更为 system
+	Timestamp    int64  `gorm:"index" json:"timestamp"`  // 变更时间（时间戳毫秒）
+}
+
+func (PropertyAudit) TableName() string {
+	return "property_audit"
+}
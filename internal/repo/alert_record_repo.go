@@ -55,6 +55,16 @@ func (r *AlertRecordRepo) GetLatestAlertRecord(ctx context.Context, configID str
 	return &record, nil
 }
 
+// FindInRange 获取在指定时间范围内触发或恢复的告警记录
+func (r *AlertRecordRepo) FindInRange(ctx context.Context, start, end int64) ([]models.AlertRecord, error) {
+	var records []models.AlertRecord
+	err := r.db.WithContext(ctx).
+		Where("(fired_at >= ? AND fired_at < ?) OR (resolved_at >= ? AND resolved_at < ?)", start, end, start, end).
+		Order("fired_at ASC").
+		Find(&records).Error
+	return records, err
+}
+
 func (r *AlertRecordRepo) Clear(ctx context.Context) error {
 	return r.db.WithContext(ctx).Where("1=1").Delete(&models.AlertRecord{}).Error
 }
@@ -0,0 +1,38 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type NotificationChannelRepo struct {
+	orz.Repository[models.NotificationChannel, string]
+	db *gorm.DB
+}
+
+func NewNotificationChannelRepo(db *gorm.DB) *NotificationChannelRepo {
+	return &NotificationChannelRepo{
+		Repository: orz.NewRepository[models.NotificationChannel, string](db),
+		db:         db,
+	}
+}
+
+// FindAllOrdered 按创建时间获取所有通知渠道
+func (r *NotificationChannelRepo) FindAllOrdered(ctx context.Context) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := r.GetDB(ctx).WithContext(ctx).
+		Order("created_at ASC").
+		Find(&channels).Error
+	return channels, err
+}
+
+// UpdateEnabled 更新启用状态
+func (r *NotificationChannelRepo) UpdateEnabled(ctx context.Context, id string, enabled bool) error {
+	return r.GetDB(ctx).WithContext(ctx).
+		Model(&models.NotificationChannel{}).
+		Where("id = ?", id).
+		Update("enabled", enabled).Error
+}
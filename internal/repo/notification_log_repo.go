@@ -0,0 +1,69 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type NotificationLogRepo struct {
+	orz.Repository[models.NotificationLog, int64]
+	db *gorm.DB
+}
+
+func NewNotificationLogRepo(db *gorm.DB) *NotificationLogRepo {
+	return &NotificationLogRepo{
+		Repository: orz.NewRepository[models.NotificationLog, int64](db),
+		db:         db,
+	}
+}
+
+// NotificationLogFilter 通知发送记录的查询条件，零值表示不限制
+type NotificationLogFilter struct {
+	AgentID     string
+	ChannelType string
+	Start       int64 // 开始时间（时间戳毫秒，含）
+	End         int64 // 结束时间（时间戳毫秒，不含）
+	Success     *bool
+}
+
+// CreateLog 创建通知发送记录
+func (r *NotificationLogRepo) CreateLog(ctx context.Context, log *models.NotificationLog) error {
+	return r.db.WithContext(ctx).Create(log).Error
+}
+
+// FindLogs 按条件分页查询通知发送记录，按发送时间倒序
+func (r *NotificationLogRepo) FindLogs(ctx context.Context, filter NotificationLogFilter, limit, offset int) ([]models.NotificationLog, int64, error) {
+	var logs []models.NotificationLog
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.NotificationLog{})
+	if filter.AgentID != "" {
+		query = query.Where("agent_id = ?", filter.AgentID)
+	}
+	if filter.ChannelType != "" {
+		query = query.Where("channel_type = ?", filter.ChannelType)
+	}
+	if filter.Start > 0 {
+		query = query.Where("timestamp >= ?", filter.Start)
+	}
+	if filter.End > 0 {
+		query = query.Where("timestamp < ?", filter.End)
+	}
+	if filter.Success != nil {
+		query = query.Where("success = ?", *filter.Success)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("timestamp DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&logs).Error
+
+	return logs, total, err
+}
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type NotificationRetryRepo struct {
+	orz.Repository[models.NotificationRetry, int64]
+	db *gorm.DB
+}
+
+func NewNotificationRetryRepo(db *gorm.DB) *NotificationRetryRepo {
+	return &NotificationRetryRepo{
+		Repository: orz.NewRepository[models.NotificationRetry, int64](db),
+		db:         db,
+	}
+}
+
+// FindDue 获取已到重试时间的待重试通知
+func (r *NotificationRetryRepo) FindDue(ctx context.Context, now int64, limit int) ([]models.NotificationRetry, error) {
+	var retries []models.NotificationRetry
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.NotificationRetryPending, now).
+		Order("next_attempt_at ASC").
+		Limit(limit).
+		Find(&retries).Error
+	return retries, err
+}
+
+// SaveRetry 保存重试状态
+func (r *NotificationRetryRepo) SaveRetry(ctx context.Context, retry *models.NotificationRetry) error {
+	return r.db.WithContext(ctx).Save(retry).Error
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"gorm.io/gorm"
+)
+
+type PropertyAuditRepo struct {
+	orz.Repository[models.PropertyAudit, int64]
+	db *gorm.DB
+}
+
+func NewPropertyAuditRepo(db *gorm.DB) *PropertyAuditRepo {
+	return &PropertyAuditRepo{
+		Repository: orz.NewRepository[models.PropertyAudit, int64](db),
+		db:         db,
+	}
+}
+
+// CreateAudit 创建审计记录
+func (r *PropertyAuditRepo) CreateAudit(ctx context.Context, audit *models.PropertyAudit) error {
+	return r.db.WithContext(ctx).Create(audit).Error
+}
+
+// FindByPropertyID 分页获取指定属性的审计记录，按时间倒序
+func (r *PropertyAuditRepo) FindByPropertyID(ctx context.Context, propertyID string, limit, offset int) ([]models.PropertyAudit, int64, error) {
+	var audits []models.PropertyAudit
+	var total int64
+
+	query := r.db.WithContext(ctx).Model(&models.PropertyAudit{}).Where("property_id = ?", propertyID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("timestamp DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&audits).Error
+
+	return audits, total, err
+}
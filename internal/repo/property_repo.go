@@ -1,6 +1,8 @@
 package repo
 
 import (
+	"context"
+
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/go-orz/orz"
 	"gorm.io/gorm"
@@ -17,3 +19,19 @@ func NewPropertyRepo(db *gorm.DB) *PropertyRepo {
 		db:         db,
 	}
 }
+
+// UpdateValue 更新属性的名称和值并递增版本号，expectedVersion 不为 nil 时仅在版本号一致时更新
+// 返回是否更新了记录，属性不存在或版本号不一致时为 false
+func (r *PropertyRepo) UpdateValue(ctx context.Context, property *models.Property, expectedVersion *int64) (bool, error) {
+	query := r.db.WithContext(ctx).Model(&models.Property{}).Where("id = ?", property.ID)
+	if expectedVersion != nil {
+		query = query.Where("version = ?", *expectedVersion)
+	}
+	result := query.Updates(map[string]interface{}{
+		"name":       property.Name,
+		"value":      property.Value,
+		"updated_at": property.UpdatedAt,
+		"version":    gorm.Expr("version + 1"),
+	})
+	return result.RowsAffected > 0, result.Error
+}
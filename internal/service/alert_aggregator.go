@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultAggregationWindow 默认聚合窗口
+	defaultAggregationWindow = 30 * time.Second
+	// defaultAggregationSampleSize 汇总消息中默认列出的探针个数
+	defaultAggregationSampleSize = 5
+)
+
+// aggregatedAlert 聚合窗口内的一条告警
+type aggregatedAlert struct {
+	record *models.AlertRecord
+	agent  *models.Agent
+}
+
+// alertGroup 同一告警类型、级别和状态的一组告警
+type alertGroup struct {
+	alerts []aggregatedAlert
+}
+
+// alertAggregator 跨探针告警聚合
+// 同一告警类型、级别和状态的告警在窗口期内合并，窗口结束后一次性交给 flush 处理
+type alertAggregator struct {
+	mu     sync.Mutex
+	groups map[string]*alertGroup
+	flush  func(alerts []aggregatedAlert)
+}
+
+func newAlertAggregator(flush func(alerts []aggregatedAlert)) *alertAggregator {
+	return &alertAggregator{
+		groups: make(map[string]*alertGroup),
+		flush:  flush,
+	}
+}
+
+// Add 添加一条告警，窗口期的起点为该分组的第一条告警
+func (a *alertAggregator) Add(record *models.AlertRecord, agent *models.Agent, window time.Duration) {
+	key := record.AlertType + ":" + record.Level + ":" + record.Status
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	group, ok := a.groups[key]
+	if !ok {
+		group = &alertGroup{}
+		a.groups[key] = group
+		time.AfterFunc(window, func() {
+			a.flushKey(key, group)
+		})
+	}
+	group.alerts = append(group.alerts, aggregatedAlert{record: record, agent: agent})
+}
+
+// flushKey 窗口到期时取出分组并发送
+func (a *alertAggregator) flushKey(key string, group *alertGroup) {
+	a.mu.Lock()
+	if a.groups[key] != group {
+		a.mu.Unlock()
+		return
+	}
+	delete(a.groups, key)
+	a.mu.Unlock()
+
+	a.flush(group.alerts)
+}
+
+// buildAggregateMessage 构建多个探针同时触发同一告警时的汇总消息
+func (n *Notifier) buildAggregateMessage(alerts []aggregatedAlert, sampleSize int, opts messageOptions) string {
+	first := alerts[0].record
+	alertTypeName := alertTypeDisplayName(first.AlertType)
+	if alertTypeName == "" {
+		alertTypeName = first.AlertType
+	}
+
+	var b strings.Builder
+	if first.Status == "resolved" {
+		fmt.Fprintf(&b, "%s %s已恢复，涉及 %d 个探针\n", opts.resolvedIcon(), alertTypeName, len(alerts))
+	} else {
+		fmt.Fprintf(&b, "%s %s影响 %d 个探针\n", opts.levelIcon(first.Level), alertTypeName, len(alerts))
+	}
+
+	if sampleSize <= 0 {
+		sampleSize = defaultAggregationSampleSize
+	}
+	names := make([]string, 0, sampleSize)
+	for i, alert := range alerts {
+		if i >= sampleSize {
+			break
+		}
+		names = append(names, alert.agent.Name)
+	}
+	fmt.Fprintf(&b, "\n探针: %s", strings.Join(names, ", "))
+	if len(alerts) > sampleSize {
+		fmt.Fprintf(&b, " 等，另有 %d 个", len(alerts)-sampleSize)
+	}
+
+	fmt.Fprintf(&b, "\n告警类型: %s", first.AlertType)
+	if first.Status == "resolved" {
+		fmt.Fprintf(&b, "\n恢复时间: %s", time.Unix(first.ResolvedAt/1000, 0).Format("2006-01-02 15:04:05"))
+	} else {
+		fmt.Fprintf(&b, "\n触发时间: %s", time.Unix(first.FiredAt/1000, 0).Format("2006-01-02 15:04:05"))
+	}
+	return b.String()
+}
+
+// SendAggregatedByConfigs 向多个渠道发送跨探针的汇总告警，alerts 应已经过 skipRecord 过滤
+// 每个渠道只汇总其接收的告警（路由、恢复通知、生效时间段等由 channelAccepts 判断）；
+// 聊天类渠道发送一条汇总消息，只剩一条时按普通告警发送；自定义Webhook面向程序处理，仍逐条发送以保留完整的结构化数据
+// 没有任何渠道发送成功的告警撤销去重记录
+func (n *Notifier) SendAggregatedByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, alerts []aggregatedAlert, sampleSize int) error {
+	var errs []error
+	now := time.Now()
+	delivered := make(map[*models.AlertRecord]bool, len(alerts))
+	for _, channelConfig := range channelConfigs {
+		if !channelConfig.Enabled || channelConfig.Fallback {
+			continue
+		}
+		accepted := make([]aggregatedAlert, 0, len(alerts))
+		for _, alert := range alerts {
+			if n.channelAccepts(channelConfig, alert.record, alert.agent, now) {
+				accepted = append(accepted, alert)
+			}
+		}
+		if len(accepted) == 0 {
+			continue
+		}
+
+		var err error
+		switch {
+		case len(accepted) > 1 && (channelConfig.Type == "dingtalk" || channelConfig.Type == "wecom" || channelConfig.Type == "feishu"):
+			// 经 SendRawByConfig 发送，使用渠道的代理、超时、重试和并发限制
+			message := n.buildAggregateMessage(accepted, sampleSize, n.messageOptions(ctx, channelConfig.Config))
+			_, err = n.SendRawByConfig(ctx, &channelConfig, message)
+			if err == nil {
+				for _, alert := range accepted {
+					delivered[alert.record] = true
+				}
+			}
+		default:
+			for _, alert := range accepted {
+				switch sendErr := n.SendNotificationByConfig(ctx, &channelConfig, alert.record, alert.agent); {
+				case sendErr == nil, errors.Is(sendErr, errWebhookQueued):
+					delivered[alert.record] = true
+				case !errors.Is(sendErr, errRateLimited):
+					err = sendErr
+				}
+			}
+		}
+		if err != nil {
+			n.logger.Error("发送汇总告警失败",
+				zap.String("channelType", channelConfig.Type),
+				zap.Int("count", len(accepted)),
+				zap.String("error", deliveryErrorMessage(err)),
+			)
+			errs = append(errs, err)
+		}
+	}
+
+	for _, alert := range alerts {
+		if !delivered[alert.record] {
+			n.dedup.Release(alert.record)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("部分通知发送失败: %v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// defaultDigestTime 未配置时每日摘要的发送时间
+const defaultDigestTime = "09:00"
+
+// channelDigest 渠道的每日摘要配置
+// 配置示例: "digest": {"enabled": true, "time": "09:00", "timezone": "Asia/Shanghai"}
+// 开启后该渠道不再接收实时告警，每天在指定时间收到过去 24 小时的告警汇总
+type channelDigest struct {
+	minute   int // 发送时间，自 0 点起的分钟数
+	location *time.Location
+}
+
+// parseChannelDigest 解析渠道配置中的 digest，未开启时返回 nil
+func parseChannelDigest(config map[string]interface{}) (*channelDigest, error) {
+	raw, ok := config["digest"].(map[string]interface{})
+	if !ok {
+		return nil, nil
+	}
+	if enabled, _ := raw["enabled"].(bool); !enabled {
+		return nil, nil
+	}
+
+	at, _ := raw["time"].(string)
+	if at == "" {
+		at = defaultDigestTime
+	}
+	minute, err := parseClock(at)
+	if err != nil {
+		return nil, fmt.Errorf("无效的摘要发送时间 %q: %w", at, err)
+	}
+
+	digest := &channelDigest{minute: minute, location: time.Local}
+	if tz, _ := raw["timezone"].(string); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %q: %w", tz, err)
+		}
+		digest.location = loc
+	}
+	return digest, nil
+}
+
+// isDigestChannel 渠道是否为每日摘要模式
+func isDigestChannel(config map[string]interface{}) bool {
+	digest, err := parseChannelDigest(config)
+	return err == nil && digest != nil
+}
+
+// dueAt 返回 now 所在日期（渠道时区）的摘要发送时间
+func (d *channelDigest) dueAt(now time.Time) time.Time {
+	local := now.In(d.location)
+	return time.Date(local.Year(), local.Month(), local.Day(), d.minute/60, d.minute%60, 0, 0, d.location)
+}
+
+// digestGroup 摘要中按探针和告警类型分组的统计
+type digestGroup struct {
+	agentName string
+	alertType string
+	firing    int
+	resolved  int
+}
+
+// buildDigestMessage 构建每日告警摘要，按探针、告警类型分组统计触发和恢复次数
+func (n *Notifier) buildDigestMessage(records []models.AlertRecord, start, end time.Time, opts messageOptions) string {
+	groups := make(map[string]*digestGroup)
+	var firing, resolved int
+	for _, record := range records {
+		key := record.AgentID + ":" + record.AlertType
+		group, ok := groups[key]
+		if !ok {
+			name := record.AgentName
+			if name == "" {
+				name = record.AgentID
+			}
+			group = &digestGroup{agentName: name, alertType: record.AlertType}
+			groups[key] = group
+		}
+		if record.FiredAt >= start.UnixMilli() && record.FiredAt < end.UnixMilli() {
+			group.firing++
+			firing++
+		}
+		if record.Status == "resolved" && record.ResolvedAt >= start.UnixMilli() && record.ResolvedAt < end.UnixMilli() {
+			group.resolved++
+			resolved++
+		}
+	}
+
+	sorted := make([]*digestGroup, 0, len(groups))
+	for _, group := range groups {
+		sorted = append(sorted, group)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].agentName != sorted[j].agentName {
+			return sorted[i].agentName < sorted[j].agentName
+		}
+		return sorted[i].alertType < sorted[j].alertType
+	})
+
+	icon := "📋"
+	if opts.Plain {
+		icon = "[DIGEST]"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s 每日告警摘要\n", icon)
+	fmt.Fprintf(&b, "\n统计时间: %s ~ %s", start.Format("2006-01-02 15:04"), end.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "\n触发: %d 次，恢复: %d 次", firing, resolved)
+	if len(sorted) == 0 {
+		b.WriteString("\n\n期间没有告警")
+		return b.String()
+	}
+
+	b.WriteString("\n")
+	lastAgent := ""
+	for _, group := range sorted {
+		if group.agentName != lastAgent {
+			fmt.Fprintf(&b, "\n%s", group.agentName)
+			lastAgent = group.agentName
+		}
+		alertTypeName := alertTypeDisplayName(group.alertType)
+		if alertTypeName == "" {
+			alertTypeName = group.alertType
+		}
+		fmt.Fprintf(&b, "\n  - %s: 触发 %d 次，恢复 %d 次", alertTypeName, group.firing, group.resolved)
+	}
+	return b.String()
+}
+
+// StartDigestTask 启动每日告警摘要任务，每分钟检查一次是否有渠道到达摘要发送时间
+func (s *AlertService) StartDigestTask(ctx context.Context) {
+	s.logger.Info("启动每日告警摘要任务")
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	// 记录每个渠道最近一次发送摘要的时间，避免同一天重复发送（重启后从当前时刻开始计算）
+	lastSent := make(map[string]time.Time)
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("每日告警摘要任务已停止")
+			return
+		case now := <-ticker.C:
+			s.sendDueDigests(ctx, now, lastSent)
+		}
+	}
+}
+
+// sendDueDigests 向到达发送时间的摘要渠道发送过去 24 小时的告警汇总
+func (s *AlertService) sendDueDigests(ctx context.Context, now time.Time, lastSent map[string]time.Time) {
+	channels, err := s.getEnabledChannels(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, channel := range channels {
+		digest, err := parseChannelDigest(channel.Config)
+		if err != nil {
+			s.logger.Warn("渠道摘要配置无效，跳过", zap.String("channelId", channel.ID), zap.Error(err))
+			continue
+		}
+		if digest == nil {
+			continue
+		}
+
+		due := digest.dueAt(now)
+		if now.Before(due) || !lastSent[channel.ID].Before(due) {
+			continue
+		}
+		// 服务在发送时间之后才启动时，不补发当天的摘要
+		if _, ok := lastSent[channel.ID]; !ok && now.Sub(due) > 2*time.Minute {
+			lastSent[channel.ID] = now
+			continue
+		}
+		lastSent[channel.ID] = now
+
+		start := due.Add(-24 * time.Hour)
+		records, err := s.AlertRecordRepo.FindInRange(ctx, start.UnixMilli(), due.UnixMilli())
+		if err != nil {
+			s.logger.Error("查询告警记录失败", zap.Error(err))
+			continue
+		}
+
+		message := s.notifier.buildDigestMessage(records, start, due, s.notifier.messageOptions(ctx, channel.Config))
+		if _, err := s.notifier.SendRawByConfig(ctx, &channel, message); err != nil {
+			s.logger.Error("发送每日告警摘要失败",
+				zap.String("channelType", channel.Type),
+				zap.String("channelId", channel.ID),
+				zap.Error(err),
+			)
+			continue
+		}
+		s.logger.Info("每日告警摘要已发送",
+			zap.String("channelType", channel.Type),
+			zap.String("channelId", channel.ID),
+			zap.Int("recordCount", len(records)),
+		)
+	}
+}
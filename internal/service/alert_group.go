@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// isGroupedChannel 渠道是否开启 "groupAlerts": true，将同一探针同时产生的多条告警合并为一条消息
+func isGroupedChannel(config map[string]interface{}) bool {
+	grouped, _ := config["groupAlerts"].(bool)
+	return grouped
+}
+
+// buildGroupedMessage 构建同一探针多条告警的合并消息：探针信息在前，每条告警一行，告警中的排在已恢复的前面
+func (n *Notifier) buildGroupedMessage(agent *models.Agent, records []*models.AlertRecord, opts messageOptions) string {
+	sorted := make([]*models.AlertRecord, len(records))
+	copy(sorted, records)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Status == "firing" && sorted[j].Status != "firing"
+	})
+
+	var b strings.Builder
+	b.WriteString(opts.textf("title.grouped", agent.Name, len(records)))
+	b.WriteString("\n")
+	b.WriteString("\n" + opts.text("label.host") + ": " + agent.Hostname)
+	b.WriteString("\n" + opts.text("label.ip") + ": " + agent.IP)
+	b.WriteString("\n")
+	for _, record := range sorted {
+		if record.Status == "firing" {
+			fmt.Fprintf(&b, "\n- %s [%s] %s", opts.levelIcon(record.Level), opts.text("status.firing"), opts.alertTypeName(record.AlertType))
+		} else {
+			fmt.Fprintf(&b, "\n- %s [%s] %s", opts.resolvedIcon(), opts.text("status.resolved"), opts.alertTypeName(record.AlertType))
+		}
+		if !hasMetricValue(record.AlertType) {
+			continue
+		}
+		fmt.Fprintf(&b, "  %s: %s", opts.text("label.value"), formatAlertValue(record.AlertType, record.ActualValue, opts))
+		if record.Status == "firing" {
+			fmt.Fprintf(&b, ", %s: %s", opts.text("label.threshold"), formatAlertValue(record.AlertType, record.Threshold, opts))
+		}
+	}
+	return b.String()
+}
+
+// SendGroupedByConfigs 发送同一探针同时产生的多条告警
+// 开启 groupAlerts 的主渠道将该渠道接收的告警合并为一条消息发送，其余渠道仍逐条发送
+func (n *Notifier) SendGroupedByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, records []*models.AlertRecord, agent *models.Agent) ([]ChannelResult, error) {
+	var active []*models.AlertRecord
+	for _, record := range records {
+		if !n.skipRecord(ctx, record) {
+			active = append(active, n.withDefaultLevel(ctx, record))
+		}
+	}
+	if len(active) == 0 {
+		return nil, nil
+	}
+
+	var grouped, others []models.NotificationChannelConfig
+	for _, channelConfig := range channelConfigs {
+		if isGroupedChannel(channelConfig.Config) && !channelConfig.Fallback {
+			grouped = append(grouped, channelConfig)
+		} else {
+			others = append(others, channelConfig)
+		}
+	}
+
+	var results []ChannelResult
+	var errs []error
+	delivered := make(map[*models.AlertRecord]bool, len(active))
+	defer func() { n.releaseUndelivered(active, delivered) }()
+	for _, record := range active {
+		recordResults, err := n.sendToChannels(ctx, others, record, agent)
+		results = append(results, recordResults...)
+		delivered[record] = anyDelivered(recordResults)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	now := time.Now()
+	for _, channelConfig := range grouped {
+		var accepted []*models.AlertRecord
+		for _, record := range active {
+			if n.channelAccepts(channelConfig, record, agent, now) {
+				accepted = append(accepted, record)
+			}
+		}
+		if len(accepted) == 0 {
+			continue
+		}
+
+		start := time.Now()
+		var err error
+		suppressed, queued := false, false
+		if len(accepted) == 1 {
+			err = n.SendNotificationByConfig(ctx, &channelConfig, accepted[0], agent)
+			switch {
+			case errors.Is(err, errRateLimited):
+				suppressed, err = true, nil
+			case errors.Is(err, errWebhookQueued):
+				queued, err = true, nil
+			}
+		} else {
+			message := n.buildGroupedMessage(agent, accepted, n.messageOptions(ctx, channelConfig.Config))
+			_, err = n.SendRawByConfig(ctx, &channelConfig, message)
+			n.stats.Record(channelConfig.ID, channelConfig.Type, channelConfig.Name, err == nil, time.Now())
+		}
+		result := ChannelResult{
+			ChannelID:  channelConfig.ID,
+			Name:       channelConfig.Name,
+			Type:       channelConfig.Type,
+			Success:    err == nil && !suppressed && !queued,
+			Suppressed: suppressed,
+			Queued:     queued,
+			DurationMs: time.Since(start).Milliseconds(),
+		}
+		if err != nil {
+			result.Error = deliveryErrorMessage(err)
+			n.logger.Error("发送合并告警失败",
+				zap.String("channelType", channelConfig.Type),
+				zap.String("channel", channelConfig.DisplayName()),
+				zap.Int("count", len(accepted)),
+				zap.String("error", deliveryErrorMessage(err)),
+			)
+			errs = append(errs, channelError(channelConfig, err))
+			// 失败后按单条告警进入重试
+			for _, record := range accepted {
+				n.deliveryFailed(channelConfig, record, err)
+			}
+		} else if !suppressed {
+			for _, record := range accepted {
+				delivered[record] = true
+			}
+		}
+		results = append(results, result)
+	}
+
+	if len(errs) > 0 {
+		return results, fmt.Errorf("部分通知发送失败: %v", errs)
+	}
+	return results, nil
+}
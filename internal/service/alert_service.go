@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/dushixiang/pika/internal/models"
@@ -17,24 +18,80 @@ type AlertService struct {
 	Service         *orz.Service
 	AlertRecordRepo *repo.AlertRecordRepo
 	AlertStateRepo  *repo.AlertStateRepo
-	agentRepo       *repo.AgentRepo
-	metricRepo      *repo.MetricRepo
-	propertyService *PropertyService
-	notifier        *Notifier
-	logger          *zap.Logger
+	// 发送失败待重试的通知
+	notificationRetryRepo *repo.NotificationRetryRepo
+	notificationLogRepo   *repo.NotificationLogRepo
+	agentRepo             *repo.AgentRepo
+	metricRepo            *repo.MetricRepo
+	propertyService       *PropertyService
+	channelService        *NotificationChannelService
+	notifier              *Notifier
+	logger                *zap.Logger
+
+	// 各告警状态的近期采样值（仅内存），用于在告警消息中展示趋势
+	recentValues   map[string][]float64
+	recentValuesMu sync.Mutex
+
+	// 串行化暂停通知列表的读改写
+	snoozeMu sync.Mutex
+	// 串行化静默规则列表的读改写
+	silenceMu sync.Mutex
+
+	// 跨探针告警聚合
+	aggregator *alertAggregator
 }
 
-func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, notifier *Notifier) *AlertService {
-	return &AlertService{
-		Service:         orz.NewService(db),
-		AlertRecordRepo: repo.NewAlertRecordRepo(db),
-		AlertStateRepo:  repo.NewAlertStateRepo(db),
-		agentRepo:       repo.NewAgentRepo(db),
-		metricRepo:      repo.NewMetricRepo(db),
-		propertyService: propertyService,
-		notifier:        notifier,
-		logger:          logger,
+// maxRecentValues 每个告警状态最多保留的近期采样值个数
+const maxRecentValues = 20
+
+// defaultTrendSamples 告警消息中默认附带的近期采样值个数
+const defaultTrendSamples = 5
+
+func NewAlertService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService, channelService *NotificationChannelService, notifier *Notifier) *AlertService {
+	s := &AlertService{
+		Service:               orz.NewService(db),
+		AlertRecordRepo:       repo.NewAlertRecordRepo(db),
+		AlertStateRepo:        repo.NewAlertStateRepo(db),
+		notificationRetryRepo: repo.NewNotificationRetryRepo(db),
+		notificationLogRepo:   repo.NewNotificationLogRepo(db),
+		agentRepo:             repo.NewAgentRepo(db),
+		metricRepo:            repo.NewMetricRepo(db),
+		propertyService:       propertyService,
+		channelService:        channelService,
+		notifier:              notifier,
+		logger:                logger,
+		recentValues:          make(map[string][]float64),
 	}
+	s.aggregator = newAlertAggregator(s.flushAggregatedAlerts)
+	notifier.onDeliveryFailed = s.enqueueNotificationRetry
+	notifier.onDeliveryAttempt = s.saveNotificationLog
+	return s
+}
+
+// recordRecentValue 记录一次采样值
+func (s *AlertService) recordRecentValue(stateKey string, value float64) {
+	s.recentValuesMu.Lock()
+	defer s.recentValuesMu.Unlock()
+
+	values := append(s.recentValues[stateKey], value)
+	if len(values) > maxRecentValues {
+		values = values[len(values)-maxRecentValues:]
+	}
+	s.recentValues[stateKey] = values
+}
+
+// getRecentValues 获取最近 n 个采样值（从旧到新）
+func (s *AlertService) getRecentValues(stateKey string, n int) []float64 {
+	s.recentValuesMu.Lock()
+	defer s.recentValuesMu.Unlock()
+
+	values := s.recentValues[stateKey]
+	if n > 0 && len(values) > n {
+		values = values[len(values)-n:]
+	}
+	result := make([]float64, len(values))
+	copy(result, values)
+	return result
 }
 
 // Clear 清空告警记录
@@ -78,32 +135,46 @@ func (s *AlertService) CheckMetrics(ctx context.Context, agentID string, cpu, me
 	}
 
 	now := time.Now().UnixMilli()
+	// 本次检查中触发或恢复的告警，检查完成后一并发送
+	var pending []*models.AlertRecord
 
 	// 检查 CPU 告警
 	if alertConfig.Rules.CPUEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "cpu", cpu, alertConfig.Rules.CPUThreshold, alertConfig.Rules.CPUDuration, now)
+		pending = appendAlertRecord(pending, s.checkAlert(ctx, alertConfig, &agent, "cpu", cpu, alertConfig.Rules.CPUThreshold, alertConfig.Rules.CPUDuration, now))
 	}
 
 	// 检查内存告警
 	if alertConfig.Rules.MemoryEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "memory", memory, alertConfig.Rules.MemoryThreshold, alertConfig.Rules.MemoryDuration, now)
+		pending = appendAlertRecord(pending, s.checkAlert(ctx, alertConfig, &agent, "memory", memory, alertConfig.Rules.MemoryThreshold, alertConfig.Rules.MemoryDuration, now))
 	}
 
 	// 检查磁盘告警
 	if alertConfig.Rules.DiskEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "disk", disk, alertConfig.Rules.DiskThreshold, alertConfig.Rules.DiskDuration, now)
+		pending = appendAlertRecord(pending, s.checkAlert(ctx, alertConfig, &agent, "disk", disk, alertConfig.Rules.DiskThreshold, alertConfig.Rules.DiskDuration, now))
 	}
 
 	// 检查网速告警
 	if alertConfig.Rules.NetworkEnabled {
-		s.checkAlert(ctx, alertConfig, &agent, "network", networkSpeed, alertConfig.Rules.NetworkThreshold, alertConfig.Rules.NetworkDuration, now)
+		pending = appendAlertRecord(pending, s.checkAlert(ctx, alertConfig, &agent, "network", networkSpeed, alertConfig.Rules.NetworkThreshold, alertConfig.Rules.NetworkDuration, now))
 	}
 
+	if len(pending) > 0 {
+		// 发送通知 - 使用新的 context 避免父 context 取消影响通知发送
+		go s.sendAlertNotifications(pending, &agent)
+	}
 	return nil
 }
 
-// checkAlert 检查单个告警规则
-func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, alertType string, currentValue, threshold float64, duration int, now int64) {
+// appendAlertRecord 追加需要发送通知的告警记录，忽略 nil
+func appendAlertRecord(records []*models.AlertRecord, record *models.AlertRecord) []*models.AlertRecord {
+	if record == nil {
+		return records
+	}
+	return append(records, record)
+}
+
+// checkAlert 检查单个告警规则，返回需要发送通知的告警记录（触发或恢复），无需通知时返回 nil
+func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, alertType string, currentValue, threshold float64, duration int, now int64) *models.AlertRecord {
 	stateKey := fmt.Sprintf("%s:global:%s", agent.ID, alertType)
 
 	var shouldFire, shouldResolve bool
@@ -127,6 +198,8 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 	state.Value = currentValue
 	state.LastCheckTime = now
 
+	s.recordRecentValue(stateKey, currentValue)
+
 	if currentValue >= threshold {
 		if state.StartTime == 0 {
 			state.StartTime = now
@@ -150,16 +223,17 @@ func (s *AlertService) checkAlert(ctx context.Context, config *models.AlertConfi
 	}
 
 	if shouldFire {
-		s.fireAlert(ctx, config, agent, state)
+		return s.fireAlert(ctx, config, agent, state)
 	}
 
 	if shouldResolve {
-		s.resolveAlert(ctx, config, agent, state)
+		return s.resolveAlert(ctx, config, agent, state)
 	}
+	return nil
 }
 
-// fireAlert 触发告警
-func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) {
+// fireAlert 触发告警，返回新建的告警记录
+func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) *models.AlertRecord {
 	s.logger.Info("触发告警",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -184,12 +258,22 @@ func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig
 		CreatedAt:   now,
 	}
 
+	// 附带近期采样值，便于在消息中展示趋势
+	if config.IncludeTrend {
+		samples := config.TrendSamples
+		if samples <= 0 {
+			samples = defaultTrendSamples
+		}
+		record.RecentValues = s.getRecentValues(state.ID, samples)
+	}
+
+	record.Labels = config.Rules.LabelsFor(record.AlertType)
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建告警记录失败", zap.Error(err))
 		// 不回滚 IsFiring 状态,避免下次检查时重复触发
 		// 记录创建失败不影响状态机,下次检查时会重试
-		return
+		return nil
 	}
 
 	// 更新状态
@@ -198,12 +282,11 @@ func (s *AlertService) fireAlert(ctx context.Context, config *models.AlertConfig
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 
-	// 发送通知 - 使用新的 context 避免父 context 取消影响通知发送
-	go s.sendAlertNotification(record, agent)
+	return record
 }
 
-// resolveAlert 恢复告警
-func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) {
+// resolveAlert 恢复告警，返回已恢复的告警记录
+func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState) *models.AlertRecord {
 	s.logger.Info("告警恢复",
 		zap.String("agentId", agent.ID),
 		zap.String("agentName", agent.Name),
@@ -211,6 +294,7 @@ func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertCon
 		zap.Float64("value", state.Value),
 	)
 
+	var resolved *models.AlertRecord
 	if state.LastRecordID > 0 {
 		existingRecord, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, state.LastRecordID)
 		if err != nil {
@@ -233,8 +317,8 @@ func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertCon
 				if err != nil {
 					s.logger.Error("更新告警记录失败", zap.Error(err))
 				} else {
-					// 发送恢复通知
-					go s.sendAlertNotification(existingRecord, agent)
+					// 需要发送恢复通知
+					resolved = existingRecord
 				}
 			}
 		}
@@ -246,6 +330,7 @@ func (s *AlertService) resolveAlert(ctx context.Context, config *models.AlertCon
 	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
+	return resolved
 }
 
 // buildAlertMessage 构建告警消息
@@ -307,10 +392,69 @@ func (s *AlertService) sendAlertNotification(record *models.AlertRecord, agent *
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	channelConfigs, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+	// 开启聚合时先进入聚合窗口，由 flushAggregatedAlerts 统一发送
+	if alertConfig, err := s.propertyService.GetAlertConfig(ctx); err == nil && alertConfig.Aggregation.Enabled {
+		window := defaultAggregationWindow
+		if alertConfig.Aggregation.WindowSeconds > 0 {
+			window = time.Duration(alertConfig.Aggregation.WindowSeconds) * time.Second
+		}
+		s.aggregator.Add(s.notifier.withDefaultLevel(ctx, record), agent, window)
+		return
+	}
+
+	enabledChannels, err := s.getEnabledChannels(ctx)
+	if err != nil || len(enabledChannels) == 0 {
+		return
+	}
+
+	if results, err := s.notifier.SendNotificationByConfigs(ctx, enabledChannels, record, agent); err != nil {
+		s.logger.Error("发送告警通知失败", zap.Any("results", results), zap.Error(err))
+	}
+}
+
+// sendAlertNotifications 发送同一探针一次检查中产生的多条告警(带panic恢复)
+// 只有一条或开启了跨探针聚合时按单条告警发送，否则由开启 groupAlerts 的渠道合并为一条消息
+func (s *AlertService) sendAlertNotifications(records []*models.AlertRecord, agent *models.Agent) {
+	if len(records) == 1 {
+		s.sendAlertNotification(records[0], agent)
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("发送告警通知时发生panic",
+				zap.Any("panic", r),
+				zap.String("agentId", agent.ID),
+			)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if alertConfig, err := s.propertyService.GetAlertConfig(ctx); err == nil && alertConfig.Aggregation.Enabled {
+		for _, record := range records {
+			s.sendAlertNotification(record, agent)
+		}
+		return
+	}
+
+	enabledChannels, err := s.getEnabledChannels(ctx)
+	if err != nil || len(enabledChannels) == 0 {
+		return
+	}
+
+	if results, err := s.notifier.SendGroupedByConfigs(ctx, enabledChannels, records, agent); err != nil {
+		s.logger.Error("发送告警通知失败", zap.Any("results", results), zap.Error(err))
+	}
+}
+
+// getEnabledChannels 获取已启用的通知渠道
+func (s *AlertService) getEnabledChannels(ctx context.Context) ([]models.NotificationChannelConfig, error) {
+	channelConfigs, err := s.channelService.GetChannelConfigs(ctx)
 	if err != nil {
 		s.logger.Error("获取通知渠道配置失败", zap.Error(err))
-		return
+		return nil, err
 	}
 
 	var enabledChannels []models.NotificationChannelConfig
@@ -319,17 +463,151 @@ func (s *AlertService) sendAlertNotification(record *models.AlertRecord, agent *
 			enabledChannels = append(enabledChannels, channel)
 		}
 	}
+	return enabledChannels, nil
+}
+
+// flushAggregatedAlerts 聚合窗口结束后发送告警
+// 窗口内只有一个探针时按普通告警发送，多个探针时发送一条汇总消息
+func (s *AlertService) flushAggregatedAlerts(alerts []aggregatedAlert) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("发送汇总告警时发生panic", zap.Any("panic", r))
+		}
+	}()
 
-	if len(enabledChannels) == 0 {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// 全局静音、暂停通知、命中静默规则或去重的告警不计入汇总
+	active := alerts[:0]
+	for _, alert := range alerts {
+		if !s.notifier.skipRecord(ctx, alert.record) {
+			active = append(active, alert)
+		}
+	}
+	if len(active) == 0 {
 		return
 	}
 
-	if err := s.notifier.SendNotificationByConfigs(ctx, enabledChannels, record, agent); err != nil {
-		s.logger.Error("发送告警通知失败", zap.Error(err))
+	enabledChannels, err := s.getEnabledChannels(ctx)
+	if err != nil || len(enabledChannels) == 0 {
+		for _, alert := range active {
+			s.notifier.dedup.Release(alert.record)
+		}
+		return
 	}
+
+	if len(active) == 1 {
+		// 已经过 skipRecord 过滤，直接发送，避免被去重窗口误判为重复
+		results, err := s.notifier.sendToChannels(ctx, enabledChannels, active[0].record, active[0].agent)
+		if err != nil {
+			s.logger.Error("发送告警通知失败", zap.Any("results", results), zap.Error(err))
+		}
+		if !anyDelivered(results) {
+			s.notifier.dedup.Release(active[0].record)
+		}
+		return
+	}
+
+	sampleSize := defaultAggregationSampleSize
+	if alertConfig, err := s.propertyService.GetAlertConfig(ctx); err == nil && alertConfig.Aggregation.SampleSize > 0 {
+		sampleSize = alertConfig.Aggregation.SampleSize
+	}
+
+	s.logger.Info("发送汇总告警",
+		zap.String("alertType", active[0].record.AlertType),
+		zap.String("level", active[0].record.Level),
+		zap.Int("count", len(active)),
+	)
+	if err := s.notifier.SendAggregatedByConfigs(ctx, enabledChannels, active, sampleSize); err != nil {
+		s.logger.Error("发送汇总告警失败", zap.Error(err))
+	}
+}
+
+// GetAlertRecord 根据ID获取告警记录
+func (s *AlertService) GetAlertRecord(ctx context.Context, id int64) (*models.AlertRecord, error) {
+	return s.AlertRecordRepo.GetAlertRecordByID(ctx, id)
 }
 
-// CheckMonitorAlerts 检查监控相关告警（证书和服务下线）
+// ReplayNotification 将历史告警重新发送到指定通知渠道（用于复盘时验证消息格式）
+func (s *AlertService) ReplayNotification(ctx context.Context, recordID int64, channelID string) error {
+	record, err := s.GetAlertRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+
+	agent, err := s.agentRepo.FindById(ctx, record.AgentID)
+	if err != nil {
+		return err
+	}
+
+	channel, err := s.channelService.Get(ctx, channelID)
+	if err != nil {
+		return err
+	}
+
+	record.Replay = true
+
+	s.logger.Info("重放告警通知",
+		zap.Int64("recordId", record.ID),
+		zap.String("channelId", channel.ID),
+		zap.String("channelType", channel.Type),
+	)
+	return ignoreQueued(s.notifier.SendNotificationByConfig(ctx, &channel.NotificationChannelConfig, record, &agent))
+}
+
+// SnoozeAlert 暂停指定探针某类告警的通知，到期后自动恢复
+// 同一探针同一告警类型重复暂停时以最新的到期时间为准
+func (s *AlertService) SnoozeAlert(ctx context.Context, agentID, alertType string, duration time.Duration) (*models.AlertSnooze, error) {
+	if agentID == "" || alertType == "" {
+		return nil, orz.NewError(400, "探针ID和告警类型不能为空")
+	}
+	if duration <= 0 {
+		return nil, orz.NewError(400, "暂停时长必须大于0")
+	}
+	if _, err := s.agentRepo.FindById(ctx, agentID); err != nil {
+		return nil, err
+	}
+
+	s.snoozeMu.Lock()
+	defer s.snoozeMu.Unlock()
+
+	snoozes, err := s.propertyService.GetAlertSnoozes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	snooze := models.AlertSnooze{
+		AgentID:   agentID,
+		AlertType: alertType,
+		ExpiresAt: now + duration.Milliseconds(),
+		CreatedAt: now,
+	}
+
+	// 顺带清理已过期和被覆盖的暂停项
+	kept := make([]models.AlertSnooze, 0, len(snoozes)+1)
+	for _, item := range snoozes {
+		if !item.Active(now) || (item.AgentID == agentID && item.AlertType == alertType) {
+			continue
+		}
+		kept = append(kept, item)
+	}
+	kept = append(kept, snooze)
+
+	if err := s.propertyService.SetAlertSnoozes(ctx, kept); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("告警通知已暂停",
+		zap.String("agentId", agentID),
+		zap.String("alertType", alertType),
+		zap.Duration("duration", duration),
+	)
+	return &snooze, nil
+}
+
+// CheckMonitorAlerts 检查监控相关告警（证书、服务下线、探针离线和版本落后）
 func (s *AlertService) CheckMonitorAlerts(ctx context.Context) error {
 	// 获取全局告警配置
 	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
@@ -366,6 +644,13 @@ func (s *AlertService) CheckMonitorAlerts(ctx context.Context) error {
 		}
 	}
 
+	// 检查探针版本落后告警
+	if alertConfig.Rules.VersionEnabled && alertConfig.Rules.MinVersion != "" {
+		if err := s.checkVersionAlerts(ctx, alertConfig, now); err != nil {
+			s.logger.Error("检查探针版本告警失败", zap.Error(err))
+		}
+	}
+
 	return nil
 }
 
@@ -454,6 +739,7 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		AgentID:     agent.ID,
 		AgentName:   agent.Name,
 		AlertType:   "cert",
+		MonitorID:   monitor.MonitorId,
 		Message:     fmt.Sprintf("监控项 %s 的HTTPS证书剩余天数%.0f天，低于阈值%.0f天", monitor.Target, certDaysLeft, config.Rules.CertThreshold),
 		Threshold:   config.Rules.CertThreshold,
 		ActualValue: certDaysLeft,
@@ -463,6 +749,7 @@ func (s *AlertService) checkCertAlert(ctx context.Context, config *models.AlertC
 		CreatedAt:   now,
 	}
 
+	record.Labels = config.Rules.LabelsFor(record.AlertType)
 	err = s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建证书告警记录失败", zap.Error(err))
@@ -616,6 +903,7 @@ func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.
 		AgentID:     agent.ID,
 		AgentName:   agent.Name,
 		AlertType:   "service",
+		MonitorID:   monitor.MonitorId,
 		Message:     fmt.Sprintf("监控项 %s 持续离线%d秒", monitor.Target, state.Duration),
 		Threshold:   0,
 		ActualValue: float64(state.Duration),
@@ -625,6 +913,7 @@ func (s *AlertService) fireServiceDownAlert(ctx context.Context, config *models.
 		CreatedAt:   now,
 	}
 
+	record.Labels = config.Rules.LabelsFor(record.AlertType)
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建服务下线告警记录失败", zap.Error(err))
@@ -763,6 +1052,7 @@ func (s *AlertService) fireAgentOfflineAlert(ctx context.Context, config *models
 		CreatedAt:   now,
 	}
 
+	record.Labels = config.Rules.LabelsFor(record.AlertType)
 	err := s.AlertRecordRepo.CreateAlertRecord(ctx, record)
 	if err != nil {
 		s.logger.Error("创建探针离线告警记录失败", zap.Error(err))
@@ -812,3 +1102,133 @@ func (s *AlertService) resolveAgentOfflineAlert(ctx context.Context, config *mod
 		s.logger.Error("保存告警状态失败", zap.Error(err))
 	}
 }
+
+// checkVersionAlerts 检查探针版本落后告警
+func (s *AlertService) checkVersionAlerts(ctx context.Context, config *models.AlertConfig, now int64) error {
+	agents, err := s.agentRepo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	minVersion := config.Rules.MinVersion
+	for _, agent := range agents {
+		// 版本号无法解析（如开发版本）时跳过，不改变已有告警状态
+		cmp, ok := compareVersions(agent.Version, minVersion)
+		if !ok {
+			continue
+		}
+
+		stateKey := fmt.Sprintf("%s:global:version", agent.ID)
+
+		// 从数据库加载状态
+		state, err := s.AlertStateRepo.GetAlertState(ctx, stateKey)
+		if err != nil {
+			// 状态不存在，创建新状态
+			state = &models.AlertState{
+				ID:        stateKey,
+				AgentID:   agent.ID,
+				AlertType: "version",
+			}
+		}
+
+		state.AgentID = agent.ID
+		state.AlertType = "version"
+		state.LastCheckTime = now
+
+		var shouldFire, shouldResolve bool
+		if cmp < 0 {
+			if !state.IsFiring {
+				shouldFire = true
+				state.IsFiring = true
+			}
+		} else if state.IsFiring {
+			shouldResolve = true
+		}
+
+		// 保存状态到数据库
+		if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+			s.logger.Error("保存告警状态失败", zap.Error(err))
+		}
+
+		if shouldFire {
+			s.fireVersionAlert(ctx, config, &agent, state, minVersion, now)
+		}
+
+		if shouldResolve {
+			s.resolveVersionAlert(ctx, &agent, state)
+		}
+	}
+
+	return nil
+}
+
+// fireVersionAlert 触发探针版本落后告警
+func (s *AlertService) fireVersionAlert(ctx context.Context, config *models.AlertConfig, agent *models.Agent, state *models.AlertState, minVersion string, now int64) {
+	s.logger.Info("触发探针版本落后告警",
+		zap.String("agentId", agent.ID),
+		zap.String("agentName", agent.Name),
+		zap.String("version", agent.Version),
+		zap.String("minVersion", minVersion),
+	)
+
+	// 创建告警记录
+	record := &models.AlertRecord{
+		AgentID:   agent.ID,
+		AgentName: agent.Name,
+		AlertType: "version",
+		Message:   fmt.Sprintf("探针 %s 版本 %s 低于要求的最低版本 %s", agent.Name, agent.Version, minVersion),
+		Level:     "warning",
+		Status:    "firing",
+		FiredAt:   now,
+		CreatedAt: now,
+	}
+
+	record.Labels = config.Rules.LabelsFor(record.AlertType)
+	if err := s.AlertRecordRepo.CreateAlertRecord(ctx, record); err != nil {
+		s.logger.Error("创建探针版本告警记录失败", zap.Error(err))
+		return
+	}
+
+	state.LastRecordID = record.ID
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+	}
+
+	// 发送通知
+	go s.sendAlertNotification(record, agent)
+}
+
+// resolveVersionAlert 探针升级后恢复版本落后告警
+func (s *AlertService) resolveVersionAlert(ctx context.Context, agent *models.Agent, state *models.AlertState) {
+	s.logger.Info("探针版本落后告警恢复",
+		zap.String("agentId", agent.ID),
+		zap.String("agentName", agent.Name),
+		zap.String("version", agent.Version),
+	)
+
+	if state.LastRecordID > 0 {
+		existingRecord, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, state.LastRecordID)
+		if err != nil {
+			s.logger.Error("获取探针版本告警记录失败", zap.Error(err))
+		} else if existingRecord != nil && existingRecord.Status == "firing" {
+			now := time.Now().UnixMilli()
+			existingRecord.Status = "resolved"
+			existingRecord.Message = fmt.Sprintf("探针 %s 已升级到版本 %s", agent.Name, agent.Version)
+			existingRecord.ResolvedAt = now
+			existingRecord.UpdatedAt = now
+
+			if err := s.AlertRecordRepo.UpdateAlertRecord(ctx, existingRecord); err != nil {
+				s.logger.Error("更新探针版本告警记录失败", zap.Error(err))
+			} else {
+				// 发送恢复通知
+				go s.sendAlertNotification(existingRecord, agent)
+			}
+		}
+	}
+
+	state.IsFiring = false
+	state.LastRecordID = 0
+	if err := s.AlertStateRepo.SaveAlertState(ctx, state); err != nil {
+		s.logger.Error("保存告警状态失败", zap.Error(err))
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+)
+
+// ListSuppressions 列出当前生效的告警抑制项，便于排查告警为何没有发出
+// 暂停通知按到期时间升序排列，其后为生效中的静默规则，全局静音排在最后
+func (s *AlertService) ListSuppressions(ctx context.Context) ([]models.AlertSuppression, error) {
+	now := time.Now().UnixMilli()
+
+	snoozes, err := s.propertyService.GetAlertSnoozes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snoozes, func(i, j int) bool {
+		return snoozes[i].ExpiresAt < snoozes[j].ExpiresAt
+	})
+
+	suppressions := make([]models.AlertSuppression, 0, len(snoozes))
+	for _, snooze := range snoozes {
+		if !snooze.Active(now) {
+			continue
+		}
+		suppressions = append(suppressions, models.AlertSuppression{
+			Kind:        "snooze",
+			AgentID:     snooze.AgentID,
+			AlertType:   snooze.AlertType,
+			ExpiresAt:   snooze.ExpiresAt,
+			RemainingMs: snooze.ExpiresAt - now,
+			CreatedAt:   snooze.CreatedAt,
+		})
+	}
+
+	rules, err := s.propertyService.GetSilenceRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if active, err := silenceRuleActive(rule, time.UnixMilli(now)); err != nil || !active {
+			continue
+		}
+		suppression := models.AlertSuppression{
+			Kind:      "silence",
+			AgentID:   rule.AgentID,
+			AlertType: rule.AlertType,
+			ExpiresAt: rule.EndAt,
+			CreatedAt: rule.CreatedAt,
+		}
+		if rule.EndAt > 0 {
+			suppression.RemainingMs = rule.EndAt - now
+		}
+		suppressions = append(suppressions, suppression)
+	}
+
+	systemConfig, err := s.propertyService.GetSystemConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, alertType := range systemConfig.MutedAlertTypes {
+		suppressions = append(suppressions, models.AlertSuppression{
+			Kind:      "mute",
+			AlertType: alertType,
+		})
+	}
+	return suppressions, nil
+}
+
+// ClearSnooze 提前解除指定探针某类告警的暂停通知
+func (s *AlertService) ClearSnooze(ctx context.Context, agentID, alertType string) error {
+	if agentID == "" || alertType == "" {
+		return orz.NewError(400, "探针ID和告警类型不能为空")
+	}
+
+	s.snoozeMu.Lock()
+	defer s.snoozeMu.Unlock()
+
+	snoozes, err := s.propertyService.GetAlertSnoozes(ctx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UnixMilli()
+	found := false
+	kept := make([]models.AlertSnooze, 0, len(snoozes))
+	for _, item := range snoozes {
+		if item.AgentID == agentID && item.AlertType == alertType {
+			found = found || item.Active(now)
+			continue
+		}
+		if item.Active(now) {
+			kept = append(kept, item)
+		}
+	}
+	if !found {
+		return orz.NewError(404, "暂停通知不存在或已过期")
+	}
+
+	if err := s.propertyService.SetAlertSnoozes(ctx, kept); err != nil {
+		return err
+	}
+	s.logger.Info("告警暂停通知已解除",
+		zap.String("agentId", agentID),
+		zap.String("alertType", alertType),
+	)
+	return nil
+}
@@ -0,0 +1,138 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// defaultBarkServer 未配置 server 时使用的官方 Bark 服务
+const defaultBarkServer = "https://api.day.app"
+
+// barkLevels 告警级别对应的 Bark 中断级别
+// critical 为重要警告，静音和专注模式下仍会响铃；timeSensitive 为时效性通知，可在专注模式下显示
+var barkLevels = map[string]string{
+	"info":     "active",
+	"warning":  "timeSensitive",
+	"critical": "critical",
+}
+
+// BarkConfig Bark 渠道配置
+type BarkConfig struct {
+	Server    string // 服务地址，默认 https://api.day.app
+	DeviceKey string // 设备 Key
+	Group     string // 通知分组，可为空
+	Sound     string // 铃声，可为空
+	BaseURL   string // 详情页地址，点击通知时打开对应探针
+}
+
+// ParseBarkConfig 解析并校验 Bark 渠道配置
+// 配置格式: { "server": "https://api.day.app", "deviceKey": "xxx", "group": "pika", "sound": "alarm", "baseUrl": "https://pika.example.com" }
+func ParseBarkConfig(config map[string]interface{}) (BarkConfig, error) {
+	var cfg BarkConfig
+	cfg.Server, _ = config["server"].(string)
+	cfg.Server = strings.TrimRight(strings.TrimSpace(cfg.Server), "/")
+	if cfg.Server == "" {
+		cfg.Server = defaultBarkServer
+	}
+	if u, err := url.Parse(cfg.Server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return cfg, fmt.Errorf("Bark server 无效: %s", cfg.Server)
+	}
+
+	cfg.DeviceKey, _ = config["deviceKey"].(string)
+	cfg.DeviceKey = strings.TrimSpace(cfg.DeviceKey)
+	if cfg.DeviceKey == "" {
+		return cfg, fmt.Errorf("Bark 配置缺少 deviceKey")
+	}
+	cfg.Group, _ = config["group"].(string)
+	cfg.Sound, _ = config["sound"].(string)
+	cfg.BaseURL = channelBaseURL(config)
+	return cfg, nil
+}
+
+// BarkResult Bark 接口返回结果，出错时同样返回 {code, message}
+type BarkResult struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// sendBark 推送一条 Bark 通知
+func (n *Notifier) sendBark(ctx context.Context, cfg BarkConfig, body map[string]interface{}) error {
+	if cfg.Group != "" {
+		body["group"] = cfg.Group
+	}
+	if cfg.Sound != "" {
+		body["sound"] = cfg.Sound
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	endpoint := cfg.Server + "/" + url.PathEscape(cfg.DeviceKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := n.doHTTP(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	var result BarkResult
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+		}
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Code != http.StatusOK {
+		return fmt.Errorf("Bark 返回错误: %d %s", result.Code, result.Message)
+	}
+	return nil
+}
+
+// sendBarkByConfig 根据配置发送 Bark 通知，中断级别按告警级别设置，恢复通知使用普通级别
+func (n *Notifier) sendBarkByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseBarkConfig(config)
+	if err != nil {
+		return err
+	}
+
+	body := map[string]interface{}{"body": message}
+	if title, rest, ok := strings.Cut(message, "\n"); ok {
+		body["title"] = title
+		body["body"] = strings.TrimLeft(rest, "\n")
+	}
+	level := barkLevels[record.Level]
+	if record.Status == "resolved" || level == "" {
+		level = "active"
+	}
+	body["level"] = level
+	if agent != nil {
+		if detailURL := agentDetailURL(cfg.BaseURL, agent.ID); detailURL != "" {
+			body["url"] = detailURL
+		}
+	}
+	return n.sendBark(ctx, cfg, body)
+}
+
+// sendBarkRaw 发送已构建好的纯文本消息
+func (n *Notifier) sendBarkRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseBarkConfig(config)
+	if err != nil {
+		return err
+	}
+	return n.sendBark(ctx, cfg, map[string]interface{}{"body": message})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// channelLimiter 按渠道限制同时进行的发送数量
+// 渠道配置 "maxConcurrent": 2 时同一渠道最多 2 个请求并发，未配置时不限制；
+// 各渠道的信号量互相独立，受限的慢渠道不会占用其他渠道的并发
+type channelLimiter struct {
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+func newChannelLimiter() *channelLimiter {
+	return &channelLimiter{sems: make(map[string]chan struct{})}
+}
+
+// parseMaxConcurrent 解析渠道配置中的 maxConcurrent，未配置时返回 0
+func parseMaxConcurrent(config map[string]interface{}) (int, error) {
+	raw, ok := config["maxConcurrent"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	value, ok := raw.(float64)
+	if !ok || value < 1 || value != float64(int(value)) {
+		return 0, fmt.Errorf("maxConcurrent 必须为正整数")
+	}
+	return int(value), nil
+}
+
+// channelLimiterKey 渠道信号量的键，优先使用渠道ID
+func channelLimiterKey(channelConfig *models.NotificationChannelConfig) string {
+	if channelConfig.ID != "" {
+		return channelConfig.ID
+	}
+	return channelConfig.Type + "/" + channelConfig.Name
+}
+
+// acquire 获取渠道的发送名额，返回释放函数；等待期间遵循 ctx 的取消
+func (l *channelLimiter) acquire(ctx context.Context, channelConfig *models.NotificationChannelConfig) (func(), error) {
+	limit, err := parseMaxConcurrent(channelConfig.Config)
+	if err != nil || limit == 0 {
+		return func() {}, nil
+	}
+
+	key := channelLimiterKey(channelConfig)
+	l.mu.Lock()
+	sem, ok := l.sems[key]
+	// 并发上限修改后使用新的信号量，已占用旧信号量的请求完成后自然释放
+	if !ok || cap(sem) != limit {
+		sem = make(chan struct{}, limit)
+		l.sems[key] = sem
+	}
+	l.mu.Unlock()
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("等待渠道发送名额超时: %w", ctx.Err())
+	}
+}
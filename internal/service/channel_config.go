@@ -0,0 +1,265 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// DingTalkRobot 钉钉群机器人
+type DingTalkRobot struct {
+	SecretKey  string // Access Token
+	SignSecret string // 加签密钥，可为空
+}
+
+// MentionConfig 告警消息中 @ 的成员
+type MentionConfig struct {
+	Mobiles      []string // 按手机号 @ 的成员
+	All          bool     // 是否 @所有人
+	OnlyOnFiring bool     // 仅告警时 @，恢复通知不 @
+}
+
+// ParseMentionConfig 解析渠道配置中的 @ 成员
+// 配置格式: { "atMobiles": ["13800000000"], "atAll": false, "atOnlyOnFiring": true }
+func ParseMentionConfig(config map[string]interface{}) MentionConfig {
+	var cfg MentionConfig
+	switch mobiles := config["atMobiles"].(type) {
+	case []interface{}:
+		for _, v := range mobiles {
+			if mobile, ok := v.(string); ok && strings.TrimSpace(mobile) != "" {
+				cfg.Mobiles = append(cfg.Mobiles, strings.TrimSpace(mobile))
+			}
+		}
+	case string:
+		for _, mobile := range strings.Split(mobiles, ",") {
+			if strings.TrimSpace(mobile) != "" {
+				cfg.Mobiles = append(cfg.Mobiles, strings.TrimSpace(mobile))
+			}
+		}
+	}
+	cfg.All, _ = config["atAll"].(bool)
+	cfg.OnlyOnFiring, _ = config["atOnlyOnFiring"].(bool)
+	return cfg
+}
+
+// Active 该告警记录是否需要 @ 成员
+func (m MentionConfig) Active(record *models.AlertRecord) bool {
+	if len(m.Mobiles) == 0 && !m.All {
+		return false
+	}
+	if m.OnlyOnFiring && (record == nil || record.Status != "firing") {
+		return false
+	}
+	return true
+}
+
+// DingTalkConfig 钉钉渠道配置
+type DingTalkConfig struct {
+	// Robots 群机器人列表，顶层的 secretKey/signSecret 为第一个
+	Robots []DingTalkRobot
+	// MsgType 消息类型：text(默认), markdown, actionCard
+	MsgType string
+	// BaseURL ActionCard “查看详情”按钮的探针详情页基础地址
+	BaseURL string
+	// Mention 告警时 @ 的成员
+	Mention MentionConfig
+}
+
+// ParseDingTalkConfig 解析并校验钉钉渠道配置
+// 配置格式: { "secretKey": "xxx", "signSecret": "xxx", "robots": [{"secretKey": "xxx", "signSecret": "xxx"}], "msgType": "markdown", "baseUrl": "https://pika.example.com" }
+func ParseDingTalkConfig(config map[string]interface{}) (DingTalkConfig, error) {
+	var cfg DingTalkConfig
+	cfg.MsgType, _ = config["msgType"].(string)
+	switch cfg.MsgType {
+	case "":
+		cfg.MsgType = "text"
+	case "text", "markdown", "actionCard":
+	default:
+		return cfg, fmt.Errorf("不支持的钉钉消息类型: %s", cfg.MsgType)
+	}
+	cfg.BaseURL = channelBaseURL(config)
+	cfg.Mention = ParseMentionConfig(config)
+	if secretKey, _ := config["secretKey"].(string); secretKey != "" {
+		signSecret, _ := config["signSecret"].(string)
+		cfg.Robots = append(cfg.Robots, DingTalkRobot{SecretKey: secretKey, SignSecret: signSecret})
+	}
+	if items, ok := config["robots"].([]interface{}); ok {
+		for _, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			secretKey, _ := m["secretKey"].(string)
+			signSecret, _ := m["signSecret"].(string)
+			if secretKey != "" {
+				cfg.Robots = append(cfg.Robots, DingTalkRobot{SecretKey: secretKey, SignSecret: signSecret})
+			}
+		}
+	}
+	if len(cfg.Robots) == 0 {
+		return cfg, fmt.Errorf("钉钉配置缺少 secretKey")
+	}
+	return cfg, nil
+}
+
+// WeComConfig 企业微信渠道配置
+type WeComConfig struct {
+	Mode       string // 为 appchat 时使用企业应用发送到群聊，否则使用群机器人
+	SecretKey  string // 群机器人 Webhook Key
+	CorpID     string // 企业ID（appchat）
+	CorpSecret string // 应用 Secret（appchat）
+	ChatID     string // 群聊ID（appchat）
+	// Mention 告警时 @ 的成员（仅群机器人模式支持）
+	Mention MentionConfig
+}
+
+// ParseWeComConfig 解析并校验企业微信渠道配置
+// 配置格式: { "secretKey": "xxx" } 或 { "mode": "appchat", "corpId": "xxx", "corpSecret": "xxx", "chatId": "xxx" }
+func ParseWeComConfig(config map[string]interface{}) (WeComConfig, error) {
+	var cfg WeComConfig
+	cfg.Mode, _ = config["mode"].(string)
+	if cfg.Mode == "appchat" {
+		cfg.CorpID, _ = config["corpId"].(string)
+		cfg.CorpSecret, _ = config["corpSecret"].(string)
+		cfg.ChatID, _ = config["chatId"].(string)
+		if cfg.CorpID == "" {
+			return cfg, fmt.Errorf("企业微信群聊配置缺少 corpId")
+		}
+		if cfg.CorpSecret == "" {
+			return cfg, fmt.Errorf("企业微信群聊配置缺少 corpSecret")
+		}
+		if cfg.ChatID == "" {
+			return cfg, fmt.Errorf("企业微信群聊配置缺少 chatId")
+		}
+		return cfg, nil
+	}
+
+	cfg.SecretKey, _ = config["secretKey"].(string)
+	if cfg.SecretKey == "" {
+		return cfg, fmt.Errorf("企业微信配置缺少 secretKey")
+	}
+	cfg.Mention = ParseMentionConfig(config)
+	return cfg, nil
+}
+
+// FeishuConfig 飞书渠道配置
+type FeishuConfig struct {
+	SecretKey         string // Webhook Token
+	SignSecret        string // 签名校验密钥
+	Format            string // 消息格式，post 为富文本
+	MsgType           string // 消息类型：text（默认）、interactive（消息卡片）
+	EncryptKey        string // 卡片回调加密密钥
+	VerificationToken string // 卡片回调校验 Token
+}
+
+// ParseFeishuConfig 解析并校验飞书渠道配置
+// 配置格式: { "secretKey": "xxx", "signSecret": "xxx", "msgType": "interactive", "format": "post", "encryptKey": "xxx", "verificationToken": "xxx" }
+func ParseFeishuConfig(config map[string]interface{}) (FeishuConfig, error) {
+	var cfg FeishuConfig
+	cfg.SecretKey, _ = config["secretKey"].(string)
+	cfg.SignSecret, _ = config["signSecret"].(string)
+	cfg.Format, _ = config["format"].(string)
+	cfg.EncryptKey, _ = config["encryptKey"].(string)
+	cfg.VerificationToken, _ = config["verificationToken"].(string)
+	if cfg.SecretKey == "" {
+		return cfg, fmt.Errorf("飞书配置缺少 secretKey")
+	}
+	cfg.MsgType, _ = config["msgType"].(string)
+	switch cfg.MsgType {
+	case "":
+		cfg.MsgType = "text"
+	case "text", "interactive":
+	default:
+		return cfg, fmt.Errorf("飞书 msgType 仅支持 text、interactive")
+	}
+	return cfg, nil
+}
+
+// webhookMethods 自定义Webhook支持的请求方法
+var webhookMethods = map[string]bool{
+	"GET":    true,
+	"POST":   true,
+	"PUT":    true,
+	"PATCH":  true,
+	"DELETE": true,
+}
+
+// ParseWebhookConfig 解析并校验自定义Webhook渠道配置，未配置的可选项填充默认值
+func ParseWebhookConfig(config map[string]interface{}) (models.WebhookConfig, error) {
+	var cfg models.WebhookConfig
+	cfg.URL, _ = config["url"].(string)
+	if cfg.URL == "" {
+		return cfg, fmt.Errorf("自定义Webhook配置缺少 url")
+	}
+	if u, err := url.Parse(cfg.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return cfg, fmt.Errorf("自定义Webhook的 url 无效: %s", cfg.URL)
+	}
+
+	cfg.Method = "POST"
+	if m, _ := config["method"].(string); m != "" {
+		cfg.Method = strings.ToUpper(m)
+	}
+	if !webhookMethods[cfg.Method] {
+		return cfg, fmt.Errorf("不支持的请求方法: %s", cfg.Method)
+	}
+
+	if h, ok := config["headers"].(map[string]interface{}); ok {
+		cfg.Headers = make(map[string]string, len(h))
+		for k, v := range h {
+			if strVal, ok := v.(string); ok {
+				cfg.Headers[k] = strVal
+			}
+		}
+	}
+
+	cfg.BodyTemplate = "json"
+	if bt, _ := config["bodyTemplate"].(string); bt != "" {
+		cfg.BodyTemplate = bt
+	}
+	cfg.CustomBody, _ = config["customBody"].(string)
+	switch cfg.BodyTemplate {
+	case "json", "form":
+	case "custom":
+		if cfg.CustomBody == "" {
+			return cfg, fmt.Errorf("使用 custom 模板时必须提供 customBody")
+		}
+	default:
+		return cfg, fmt.Errorf("不支持的 bodyTemplate: %s", cfg.BodyTemplate)
+	}
+
+	cfg.BodyFormat, _ = config["bodyFormat"].(string)
+	switch cfg.BodyFormat {
+	case "":
+		cfg.BodyFormat = "text"
+	case "text", "json":
+	default:
+		return cfg, fmt.Errorf("不支持的 bodyFormat: %s", cfg.BodyFormat)
+	}
+
+	cfg.PayloadMode, _ = config["payloadMode"].(string)
+	switch cfg.PayloadMode {
+	case "":
+		cfg.PayloadMode = "text"
+	case "text", "structured":
+	default:
+		return cfg, fmt.Errorf("不支持的 payloadMode: %s", cfg.PayloadMode)
+	}
+
+	cfg.Charset, _ = config["charset"].(string)
+	if charset := strings.ToLower(cfg.Charset); charset != "" && charset != "utf-8" && charset != "utf8" {
+		if _, ok := charsetEncodings[charset]; !ok {
+			return cfg, fmt.Errorf("不支持的字符集: %s", cfg.Charset)
+		}
+	}
+
+	cfg.SigningSecret, _ = config["signingSecret"].(string)
+	cfg.SigningAlgorithm, _ = config["signingAlgorithm"].(string)
+	if cfg.SigningSecret != "" {
+		if _, err := signWebhookBody(nil, cfg.SigningSecret, cfg.SigningAlgorithm); err != nil {
+			return cfg, err
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestParseDingTalkConfig(t *testing.T) {
+	cfg, err := ParseDingTalkConfig(map[string]interface{}{
+		"secretKey":  "token-1",
+		"signSecret": "sign-1",
+		"robots": []interface{}{
+			map[string]interface{}{"secretKey": "token-2"},
+			map[string]interface{}{"signSecret": "ignored"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Robots) != 2 || cfg.Robots[0].SignSecret != "sign-1" || cfg.Robots[1].SecretKey != "token-2" {
+		t.Fatalf("unexpected robots: %+v", cfg.Robots)
+	}
+
+	if _, err := ParseDingTalkConfig(map[string]interface{}{}); err == nil {
+		t.Fatal("expected error for missing secretKey")
+	}
+}
+
+func TestChannelBaseURL(t *testing.T) {
+	tests := []struct {
+		config map[string]interface{}
+		want   string
+	}{
+		{config: map[string]interface{}{"baseUrl": "https://a.example.com"}, want: "https://a.example.com"},
+		// 兼容旧配置中的 dashboardUrl
+		{config: map[string]interface{}{"dashboardUrl": "https://b.example.com"}, want: "https://b.example.com"},
+		{config: map[string]interface{}{"baseUrl": "https://a.example.com", "dashboardUrl": "https://b.example.com"}, want: "https://a.example.com"},
+		{config: map[string]interface{}{}, want: ""},
+	}
+	for _, tt := range tests {
+		if got := channelBaseURL(tt.config); got != tt.want {
+			t.Errorf("channelBaseURL(%v) = %q, want %q", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestParseWeComConfig(t *testing.T) {
+	cfg, err := ParseWeComConfig(map[string]interface{}{"mode": "appchat", "corpId": "c", "corpSecret": "s", "chatId": "g"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.ChatID != "g" {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+	if _, err := ParseWeComConfig(map[string]interface{}{"mode": "appchat", "corpId": "c"}); err == nil {
+		t.Fatal("expected error for incomplete appchat config")
+	}
+	if _, err := ParseWeComConfig(map[string]interface{}{"secretKey": 123}); err == nil {
+		t.Fatal("expected error for non-string secretKey")
+	}
+}
+
+func TestParseWebhookConfig(t *testing.T) {
+	cfg, err := ParseWebhookConfig(map[string]interface{}{
+		"url":     "https://example.com/hook",
+		"method":  "put",
+		"headers": map[string]interface{}{"X-Token": "abc", "X-Bad": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Method != "PUT" || cfg.BodyTemplate != "json" || cfg.BodyFormat != "text" || cfg.Headers["X-Token"] != "abc" || len(cfg.Headers) != 1 {
+		t.Fatalf("unexpected config: %+v", cfg)
+	}
+
+	invalid := []map[string]interface{}{
+		{},
+		{"url": "ftp://example.com"},
+		{"url": "https://example.com", "method": "TRACE"},
+		{"url": "https://example.com", "bodyTemplate": "custom"},
+		{"url": "https://example.com", "bodyTemplate": "xml"},
+		{"url": "https://example.com", "charset": "latin1"},
+		{"url": "https://example.com", "signingSecret": "s", "signingAlgorithm": "md5"},
+		{"url": "https://example.com", "bodyFormat": "xml"},
+	}
+	for _, config := range invalid {
+		if _, err := ParseWebhookConfig(config); err == nil {
+			t.Errorf("expected error for %v", config)
+		}
+	}
+}
+
+func TestParseMentionConfig(t *testing.T) {
+	mention := ParseMentionConfig(map[string]interface{}{
+		"atMobiles":      []interface{}{"13800000000", " ", "13900000000"},
+		"atOnlyOnFiring": true,
+	})
+	if len(mention.Mobiles) != 2 {
+		t.Fatalf("unexpected mobiles: %v", mention.Mobiles)
+	}
+	if !mention.Active(&models.AlertRecord{Status: "firing"}) {
+		t.Fatal("expected mention on firing")
+	}
+	if mention.Active(&models.AlertRecord{Status: "resolved"}) {
+		t.Fatal("expected no mention on resolved")
+	}
+	if ParseMentionConfig(map[string]interface{}{}).Active(&models.AlertRecord{Status: "firing"}) {
+		t.Fatal("expected no mention without mobiles")
+	}
+}
+
+func TestIsTeamsWorkflowURL(t *testing.T) {
+	cases := map[string]bool{
+		"https://prod-12.westus.logic.azure.com:443/workflows/abc/triggers/manual/paths/invoke": true,
+		"https://default123.environment.api.powerplatform.com/powerautomate/automations/direct": true,
+		"https://contoso.webhook.office.com/webhookb2/xxx":                                      false,
+		"https://logic.azure.com.example.com/hook":                                              false,
+	}
+	for u, want := range cases {
+		if got := isTeamsWorkflowURL(u); got != want {
+			t.Errorf("isTeamsWorkflowURL(%q) = %v, want %v", u, got, want)
+		}
+	}
+}
+
+func TestParseFeishuConfigMsgType(t *testing.T) {
+	cfg, err := ParseFeishuConfig(map[string]interface{}{"secretKey": "token"})
+	if err != nil || cfg.MsgType != "text" {
+		t.Fatalf("默认 msgType 应为 text: %+v, %v", cfg, err)
+	}
+	if _, err := ParseFeishuConfig(map[string]interface{}{"secretKey": "token", "msgType": "interactive"}); err != nil {
+		t.Fatalf("interactive 应有效: %v", err)
+	}
+	if _, err := ParseFeishuConfig(map[string]interface{}{"secretKey": "token", "msgType": "card"}); err == nil {
+		t.Fatalf("不支持的 msgType 应返回错误")
+	}
+}
+
+func TestNotificationProxy(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "https://oapi.dingtalk.com/robot/send", nil)
+	// 未配置 proxyUrl 时与环境变量代理一致（环境变量在进程内只读取一次，这里不修改）
+	envProxy, _ := http.ProxyFromEnvironment(req)
+	if proxy, err := notificationProxy(req); err != nil || (proxy == nil) != (envProxy == nil) || (proxy != nil && *proxy != *envProxy) {
+		t.Fatalf("未配置 proxyUrl 时应使用环境变量代理: %v, %v", proxy, err)
+	}
+
+	ctx := WithChannelHTTPOptions(context.Background(), map[string]interface{}{"proxyUrl": "socks5://channel-proxy:1080"})
+	if proxy, err := notificationProxy(req.WithContext(ctx)); err != nil || proxy == nil || proxy.Host != "channel-proxy:1080" {
+		t.Fatalf("渠道 proxyUrl 应优先于环境变量: %v, %v", proxy, err)
+	}
+
+	if _, err := parseProxyURL(map[string]interface{}{"proxyUrl": "ftp://proxy:21"}); err == nil {
+		t.Fatalf("不支持的代理协议应返回错误")
+	}
+}
+
+func TestChannelRouteMatch(t *testing.T) {
+	route, err := parseChannelRoute(map[string]interface{}{"minLevel": "warning"})
+	if err != nil {
+		t.Fatalf("parseChannelRoute() error = %v", err)
+	}
+	for level, want := range map[string]bool{"info": false, "warning": true, "critical": true} {
+		if got := route.Match(&models.AlertRecord{AlertType: "cpu", Level: level}); got != want {
+			t.Errorf("minLevel=warning, level=%s: Match() = %v, want %v", level, got, want)
+		}
+	}
+
+	route, _ = parseChannelRoute(map[string]interface{}{"alertTypes": []interface{}{"cert"}, "minLevel": "critical"})
+	if !route.Match(&models.AlertRecord{AlertType: "cert", Level: "critical", Status: "resolved"}) {
+		t.Errorf("匹配的告警及其恢复通知应被接收")
+	}
+	if route.Match(&models.AlertRecord{AlertType: "cpu", Level: "critical"}) || route.Match(&models.AlertRecord{AlertType: "cert", Level: "warning"}) {
+		t.Errorf("告警类型或级别不匹配时应跳过")
+	}
+
+	// 未配置路由条件时接收所有告警
+	route, _ = parseChannelRoute(map[string]interface{}{})
+	if !route.Match(&models.AlertRecord{AlertType: "disk", Level: "info"}) {
+		t.Errorf("未配置路由条件时应接收所有告警")
+	}
+
+	if _, err := parseChannelRoute(map[string]interface{}{"minLevel": "fatal"}); err == nil {
+		t.Errorf("不支持的 minLevel 应返回错误")
+	}
+}
+
+func TestValidateChannelConfigRequiredFields(t *testing.T) {
+	tests := []struct {
+		channelType string
+		config      map[string]interface{}
+		missing     string
+	}{
+		{"dingtalk", map[string]interface{}{"signSecret": "sign"}, "secretKey"},
+		{"webhook", map[string]interface{}{"method": "POST"}, "url"},
+		{"email", map[string]interface{}{"to": []interface{}{"ops@example.com"}}, "smtpHost"},
+	}
+	for _, tt := range tests {
+		errs := ValidateChannelConfig(models.NotificationChannelConfig{Type: tt.channelType, Config: tt.config})
+		if len(errs) == 0 || !strings.Contains(strings.Join(errs, "; "), tt.missing) {
+			t.Errorf("ValidateChannelConfig(%s) = %v, want error mentioning %s", tt.channelType, errs, tt.missing)
+		}
+	}
+
+	valid := models.NotificationChannelConfig{Type: "dingtalk", Config: map[string]interface{}{"secretKey": "token"}}
+	if errs := ValidateChannelConfig(valid); len(errs) > 0 {
+		t.Errorf("ValidateChannelConfig(valid) = %v, want no errors", errs)
+	}
+}
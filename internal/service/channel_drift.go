@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// channelDriftCheckInterval 检查通知渠道是否全部禁用的间隔
+const channelDriftCheckInterval = time.Hour
+
+// channelDriftMessage 所有通知渠道都被禁用时发送的提醒
+const channelDriftMessage = "⚠️ 所有通知渠道均已禁用\n\nPika 当前不会发送任何告警通知，请检查通知渠道配置。\n此提醒通过已禁用的渠道发送，仅在进入该状态时发送一次。"
+
+// StartChannelDriftCheck 启动时及定期检查是否所有通知渠道都被禁用，避免告警静默失效无人知晓
+func (s *AlertService) StartChannelDriftCheck(ctx context.Context) {
+	ticker := time.NewTicker(channelDriftCheckInterval)
+	defer ticker.Stop()
+
+	// 只在进入“全部禁用”状态时发送一次提醒，恢复后重新计算
+	notified := s.checkChannelDrift(ctx, false)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notified = s.checkChannelDrift(ctx, notified)
+		}
+	}
+}
+
+// checkChannelDrift 检查一次，返回当前是否已就“全部禁用”发送过提醒
+func (s *AlertService) checkChannelDrift(ctx context.Context, notified bool) bool {
+	channels, err := s.channelService.GetChannelConfigs(ctx)
+	if err != nil {
+		s.logger.Error("获取通知渠道配置失败", zap.Error(err))
+		return notified
+	}
+
+	var candidates []models.NotificationChannelConfig
+	for _, channel := range channels {
+		if channel.Enabled && !channel.TestOnly {
+			return false
+		}
+		if !channel.TestOnly {
+			candidates = append(candidates, channel)
+		}
+	}
+
+	s.logger.Warn("没有启用的通知渠道，告警将不会发送任何通知", zap.Int("configuredCount", len(channels)))
+	if notified || len(candidates) == 0 {
+		return notified
+	}
+
+	// 通过优先级最高的已配置渠道发送提醒
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Priority > candidates[j].Priority
+	})
+	channel := candidates[0]
+	channel.Enabled = true
+
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	if _, err := s.notifier.SendRawByConfig(sendCtx, &channel, channelDriftMessage); err != nil {
+		s.logger.Error("发送通知渠道全部禁用提醒失败",
+			zap.String("channelType", channel.Type),
+			zap.String("channel", channel.DisplayName()),
+			zap.String("error", deliveryErrorMessage(err)),
+		)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,488 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// ChannelField 通知渠道配置字段
+type ChannelField struct {
+	Key      string `json:"key"`              // 配置键
+	Label    string `json:"label"`            // 显示名称
+	Required bool   `json:"required"`         // 是否必填
+	Secret   bool   `json:"secret,omitempty"` // 是否为敏感信息（前端以密码框展示）
+}
+
+// ChannelType 支持的通知渠道类型
+type ChannelType struct {
+	Type   string         `json:"type"`   // 类型
+	Name   string         `json:"name"`   // 显示名称
+	Fields []ChannelField `json:"fields"` // 配置字段
+}
+
+// channelSender 按渠道配置发送一条告警消息，message 为 buildMessage 的输出
+type channelSender func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error
+
+// channelRawSender 按渠道配置发送一条已构建好的消息（不经过 buildMessage），返回平台消息ID（如有）
+type channelRawSender func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error)
+
+// channelDefinition 通知渠道定义
+type channelDefinition struct {
+	ChannelType
+	send    channelSender
+	sendRaw channelRawSender
+	// validate 自定义配置校验，为空时按 Fields 中的必填字段校验
+	validate func(config map[string]interface{}) []string
+}
+
+// channelRegistry 通知渠道注册表，新增渠道只需在此注册
+var channelRegistry = []channelDefinition{
+	{
+		ChannelType: ChannelType{
+			Type: "dingtalk",
+			Name: "钉钉",
+			Fields: []ChannelField{
+				{Key: "secretKey", Label: "Access Token", Required: true, Secret: true},
+				{Key: "signSecret", Label: "加签密钥", Secret: true},
+				{Key: "msgType", Label: "消息类型"},
+				{Key: "baseUrl", Label: "详情页地址"},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendDingTalkAlert(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendDingTalkByConfig(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseDingTalkConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "wecom",
+			Name: "企业微信",
+			Fields: []ChannelField{
+				{Key: "secretKey", Label: "Webhook Key", Required: true, Secret: true},
+				{Key: "corpSecret", Label: "应用 Secret（群聊模式）", Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			message = truncateMessage(message, weComTextByteLimit, channelDetailURL(channelConfig.Config, agent))
+			messageID, err := n.sendWeComAlert(ctx, channelConfig.Config, record, message)
+			n.logDelivery(channelConfig.Type, record, messageID, err)
+			return err
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return n.sendWeComByConfig(ctx, config, truncateMessage(message, weComTextByteLimit, ""))
+		},
+		validate: func(config map[string]interface{}) []string {
+			if mode, _ := config["mode"].(string); mode == "appchat" {
+				// 群聊模式一次列出所有缺失字段
+				return missingFields(config, "corpId", "corpSecret", "chatId")
+			}
+			_, err := ParseWeComConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "feishu",
+			Name: "飞书",
+			Fields: []ChannelField{
+				{Key: "secretKey", Label: "Webhook Token", Required: true, Secret: true},
+				{Key: "signSecret", Label: "签名校验密钥", Secret: true},
+				{Key: "msgType", Label: "消息类型"},
+				{Key: "baseUrl", Label: "详情页地址"},
+				{Key: "encryptKey", Label: "回调 Encrypt Key", Secret: true},
+				{Key: "verificationToken", Label: "回调 Verification Token", Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			if msgType, _ := channelConfig.Config["msgType"].(string); msgType == "interactive" {
+				messageID, err := n.sendFeishuCard(ctx, channelConfig.Config, agent, record, message)
+				n.logDelivery(channelConfig.Type, record, messageID, err)
+				return err
+			}
+			if format, _ := channelConfig.Config["format"].(string); format == "post" {
+				messageID, err := n.sendFeishuPost(ctx, channelConfig.Config, agent, record, message)
+				n.logDelivery(channelConfig.Type, record, messageID, err)
+				return err
+			}
+			message = truncateMessage(message, feishuTextByteLimit, channelDetailURL(channelConfig.Config, agent))
+			messageID, err := n.sendFeishuByConfig(ctx, channelConfig.Config, message)
+			n.logDelivery(channelConfig.Type, record, messageID, err)
+			return err
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return n.sendFeishuByConfig(ctx, config, truncateMessage(message, feishuTextByteLimit, ""))
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseFeishuConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "webhook",
+			Name: "自定义Webhook",
+			Fields: []ChannelField{
+				{Key: "url", Label: "URL", Required: true},
+				{Key: "method", Label: "请求方法"},
+				{Key: "headers", Label: "请求头", Secret: true},
+				{Key: "bodyTemplate", Label: "请求体模板"},
+				{Key: "customBody", Label: "自定义请求体"},
+				{Key: "charset", Label: "字符集"},
+				{Key: "signingSecret", Label: "签名密钥", Secret: true},
+				{Key: "signingAlgorithm", Label: "签名算法"},
+				{Key: "schemaVersion", Label: "请求体版本"},
+				{Key: "bodyFormat", Label: "请求体内容"},
+				{Key: "payloadMode", Label: "请求体结构"},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendWebhookByConfig(ctx, channelConfig, agent, record)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendWebhookRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseWebhookConfig(config)
+			errs := errorStrings(err)
+			if _, err := parseWebhookSchemaVersion(config); err != nil {
+				errs = append(errs, err.Error())
+			}
+			return errs
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "telegram",
+			Name: "Telegram",
+			Fields: []ChannelField{
+				{Key: "botToken", Label: "Bot Token", Required: true, Secret: true},
+				{Key: "chatId", Label: "Chat ID", Required: true},
+				{Key: "messageThreadId", Label: "话题ID"},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			message = truncateMessage(message, telegramTextByteLimit, channelDetailURL(channelConfig.Config, agent))
+			messageID, err := n.sendTelegramByConfig(ctx, channelConfig.Config, message)
+			n.logDelivery(channelConfig.Type, record, messageID, err)
+			return err
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return n.sendTelegramByConfig(ctx, config, truncateMessage(message, telegramTextByteLimit, ""))
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseTelegramConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "slack",
+			Name: "Slack",
+			Fields: []ChannelField{
+				{Key: "webhookUrl", Label: "Webhook URL", Required: true, Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendSlackByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendSlackRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseSlackConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "discord",
+			Name: "Discord",
+			Fields: []ChannelField{
+				{Key: "webhookUrl", Label: "Webhook URL", Required: true, Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendDiscordByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendDiscordRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseDiscordConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "teams",
+			Name: "Microsoft Teams",
+			Fields: []ChannelField{
+				{Key: "webhookUrl", Label: "Webhook URL", Required: true, Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendTeamsByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendTeamsRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseTeamsConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "pagerduty",
+			Name: "PagerDuty",
+			Fields: []ChannelField{
+				{Key: "routingKey", Label: "Integration Key", Required: true, Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendPagerDutyByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendPagerDutyRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParsePagerDutyConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "ntfy",
+			Name: "ntfy",
+			Fields: []ChannelField{
+				{Key: "server", Label: "服务地址"},
+				{Key: "topic", Label: "Topic", Required: true},
+				{Key: "username", Label: "用户名"},
+				{Key: "password", Label: "密码", Secret: true},
+				{Key: "accessToken", Label: "Access Token", Secret: true},
+				{Key: "baseUrl", Label: "详情页地址"},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendNtfyByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendNtfyRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseNtfyConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "bark",
+			Name: "Bark",
+			Fields: []ChannelField{
+				{Key: "server", Label: "服务地址"},
+				{Key: "deviceKey", Label: "Device Key", Required: true, Secret: true},
+				{Key: "group", Label: "分组"},
+				{Key: "sound", Label: "铃声"},
+				{Key: "baseUrl", Label: "详情页地址"},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendBarkByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendBarkRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseBarkConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "gotify",
+			Name: "Gotify",
+			Fields: []ChannelField{
+				{Key: "server", Label: "服务地址", Required: true},
+				{Key: "token", Label: "App Token", Required: true, Secret: true},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendGotifyByConfig(ctx, channelConfig.Config, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			return "", n.sendGotifyRaw(ctx, config, message)
+		},
+		validate: func(config map[string]interface{}) []string {
+			_, err := ParseGotifyConfig(config)
+			return errorStrings(err)
+		},
+	},
+	{
+		ChannelType: ChannelType{
+			Type: "email",
+			Name: "邮件",
+			Fields: []ChannelField{
+				{Key: "smtpHost", Label: "SMTP服务器", Required: true},
+				{Key: "smtpPort", Label: "SMTP端口"},
+				{Key: "username", Label: "用户名"},
+				{Key: "password", Label: "密码", Secret: true},
+				{Key: "requireStartTLS", Label: "强制 STARTTLS"},
+				{Key: "from", Label: "发件人", Required: true},
+				{Key: "to", Label: "收件人", Required: true},
+				{Key: "cc", Label: "抄送"},
+			},
+		},
+		send: func(n *Notifier, ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, message string) error {
+			return n.sendEmailByConfig(ctx, channelConfig.Config, agent, record, message)
+		},
+		sendRaw: func(n *Notifier, ctx context.Context, config map[string]interface{}, message string) (string, error) {
+			cfg, err := ParseEmailConfig(config)
+			if err != nil {
+				return "", err
+			}
+			// 以消息首行作为邮件主题
+			subject, _, _ := strings.Cut(message, "\n")
+			return "", n.sendEmail(ctx, cfg, subject, message, "")
+		},
+		validate: func(config map[string]interface{}) []string {
+			errs := missingFields(config, "smtpHost", "to")
+			// 未填写发件人时使用用户名
+			if !hasConfigValue(config["from"]) && !hasConfigValue(config["username"]) {
+				errs = append(errs, "缺少 from")
+			} else if _, err := ParseEmailConfig(config); err != nil && len(errs) == 0 {
+				errs = append(errs, err.Error())
+			}
+			return errs
+		},
+	},
+}
+
+// missingFields 检查必填字段，返回缺失字段的错误信息
+func missingFields(config map[string]interface{}, keys ...string) []string {
+	var errs []string
+	for _, key := range keys {
+		if !hasConfigValue(config[key]) {
+			errs = append(errs, "缺少 "+key)
+		}
+	}
+	return errs
+}
+
+// errorStrings 将单个解析错误转换为校验错误列表
+func errorStrings(err error) []string {
+	if err == nil {
+		return nil
+	}
+	return []string{err.Error()}
+}
+
+// hasConfigValue 配置值是否非空
+func hasConfigValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// ValidateChannelConfig 校验通知渠道配置，返回所有校验错误
+func ValidateChannelConfig(channel models.NotificationChannelConfig) []string {
+	if strings.TrimSpace(channel.Type) == "" {
+		return []string{"通知渠道类型不能为空"}
+	}
+	def, ok := lookupChannel(channel.Type)
+	if !ok {
+		return []string{"不支持的通知渠道类型: " + channel.Type}
+	}
+	var errs []string
+	if def.validate != nil {
+		errs = def.validate(channel.Config)
+	} else {
+		var required []string
+		for _, field := range def.Fields {
+			if field.Required {
+				required = append(required, field.Key)
+			}
+		}
+		errs = missingFields(channel.Config, required...)
+	}
+	if _, err := parseChannelSchedule(channel.Config); err != nil {
+		errs = append(errs, "生效时间配置无效: "+err.Error())
+	}
+	if _, err := parseChannelDigest(channel.Config); err != nil {
+		errs = append(errs, "每日摘要配置无效: "+err.Error())
+	}
+	if _, err := parseTimeoutSeconds(channel.Config); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseMaxRetries(channel.Config); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseMaxConcurrent(channel.Config); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseRatePerMinute(channel.Config); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseProxyURL(channel.Config); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if _, err := parseChannelRoute(channel.Config); err != nil {
+		errs = append(errs, err.Error())
+	}
+	errs = append(errs, validateMessageTemplates(channel.Config)...)
+	return errs
+}
+
+// ValidateChannelConfigs 校验多个通知渠道配置，错误信息带上渠道序号和名称
+func ValidateChannelConfigs(channels []models.NotificationChannelConfig) []string {
+	var errs []string
+	for i, channel := range channels {
+		name := channel.Name
+		if name == "" {
+			name = channel.Type
+		}
+		for _, err := range ValidateChannelConfig(channel) {
+			errs = append(errs, fmt.Sprintf("渠道 %d（%s）: %s", i+1, name, err))
+		}
+	}
+	return errs
+}
+
+// ErrUnsupportedChannelType 渠道类型未在注册表中注册
+var ErrUnsupportedChannelType = errors.New("不支持的通知渠道类型")
+
+// lookupChannel 按类型查找通知渠道定义
+func lookupChannel(channelType string) (*channelDefinition, bool) {
+	for i := range channelRegistry {
+		if channelRegistry[i].Type == channelType {
+			return &channelRegistry[i], true
+		}
+	}
+	return nil, false
+}
+
+// SupportedChannelTypes 获取支持的通知渠道类型及其配置字段
+func SupportedChannelTypes() []ChannelType {
+	types := make([]ChannelType, 0, len(channelRegistry))
+	for _, def := range channelRegistry {
+		types = append(types, def.ChannelType)
+	}
+	return types
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// alertLevelRanks 告警级别由低到高：info < warning < critical
+var alertLevelRanks = map[string]int{
+	"info":     1,
+	"warning":  2,
+	"critical": 3,
+}
+
+// channelRoute 渠道的告警路由条件，均为空时接收所有告警
+type channelRoute struct {
+	alertTypes map[string]bool // 接收的告警类型
+	minLevel   string          // 最低告警级别
+}
+
+// parseChannelRoute 解析渠道配置中的 alertTypes 和 minLevel
+func parseChannelRoute(config map[string]interface{}) (channelRoute, error) {
+	var route channelRoute
+	switch alertTypes := config["alertTypes"].(type) {
+	case nil:
+	case []interface{}:
+		for _, v := range alertTypes {
+			alertType, ok := v.(string)
+			if !ok || alertType == "" {
+				return route, fmt.Errorf("alertTypes 必须为告警类型字符串列表")
+			}
+			if route.alertTypes == nil {
+				route.alertTypes = make(map[string]bool)
+			}
+			route.alertTypes[alertType] = true
+		}
+	default:
+		return route, fmt.Errorf("alertTypes 必须为告警类型字符串列表")
+	}
+
+	route.minLevel, _ = config["minLevel"].(string)
+	if route.minLevel != "" {
+		if _, ok := alertLevelRanks[route.minLevel]; !ok {
+			return route, fmt.Errorf("minLevel 仅支持 info、warning、critical")
+		}
+	}
+	return route, nil
+}
+
+// Match 告警是否满足渠道的路由条件，恢复通知与对应告警的级别相同，随告警一同路由
+func (r channelRoute) Match(record *models.AlertRecord) bool {
+	if len(r.alertTypes) > 0 && !r.alertTypes[record.AlertType] {
+		return false
+	}
+	if r.minLevel != "" && alertLevelRanks[record.Level] < alertLevelRanks[r.minLevel] {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+// channelSchedule 通知渠道的生效时间段（免打扰时间之外）
+// 配置示例: "schedule": {"start": "08:00", "end": "22:00", "days": [1, 2, 3, 4, 5], "timezone": "Asia/Shanghai"}
+// start 晚于 end 时表示跨天，如 22:00-06:00；days 为空表示每天，0 为周日
+type channelSchedule struct {
+	start    int // 自 0 点起的分钟数
+	end      int
+	days     map[time.Weekday]bool
+	location *time.Location
+}
+
+// parseChannelSchedule 解析渠道配置中的 schedule，未配置时返回 nil
+func parseChannelSchedule(config map[string]interface{}) (*channelSchedule, error) {
+	raw, ok := config["schedule"].(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		return nil, nil
+	}
+
+	startStr, _ := raw["start"].(string)
+	endStr, _ := raw["end"].(string)
+	tz, _ := raw["timezone"].(string)
+	var days []int
+	if items, ok := raw["days"].([]interface{}); ok {
+		for _, d := range items {
+			day, ok := d.(float64)
+			if !ok || day != float64(int(day)) {
+				return nil, fmt.Errorf("无效的星期 %v", d)
+			}
+			days = append(days, int(day))
+		}
+	}
+	return newChannelSchedule(startStr, endStr, days, tz)
+}
+
+// newChannelSchedule 按开始/结束时间（HH:MM）、星期和时区构造时间段，timezone 为空时使用本地时区
+func newChannelSchedule(startStr, endStr string, days []int, timezone string) (*channelSchedule, error) {
+	start, err := parseClock(startStr)
+	if err != nil {
+		return nil, fmt.Errorf("无效的开始时间 %q: %w", startStr, err)
+	}
+	end, err := parseClock(endStr)
+	if err != nil {
+		return nil, fmt.Errorf("无效的结束时间 %q: %w", endStr, err)
+	}
+
+	schedule := &channelSchedule{start: start, end: end, location: time.Local}
+	if timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %q: %w", timezone, err)
+		}
+		schedule.location = loc
+	}
+	if len(days) > 0 {
+		schedule.days = make(map[time.Weekday]bool, len(days))
+		for _, day := range days {
+			if day < 0 || day > 6 {
+				return nil, fmt.Errorf("无效的星期 %v", day)
+			}
+			schedule.days[time.Weekday(day)] = true
+		}
+	}
+	return schedule, nil
+}
+
+// parseClock 解析 HH:MM，返回自 0 点起的分钟数
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Active 判断指定时间是否在生效时间段内
+func (s *channelSchedule) Active(now time.Time) bool {
+	now = now.In(s.location)
+	minute := now.Hour()*60 + now.Minute()
+	if s.start == s.end {
+		return s.dayAllowed(now.Weekday())
+	}
+	if s.start < s.end {
+		return s.dayAllowed(now.Weekday()) && minute >= s.start && minute < s.end
+	}
+	// 跨天时段：凌晨部分属于前一天的时段
+	if minute >= s.start {
+		return s.dayAllowed(now.Weekday())
+	}
+	if minute < s.end {
+		return s.dayAllowed(now.AddDate(0, 0, -1).Weekday())
+	}
+	return false
+}
+
+// NextActive 计算下一次进入生效时间段的时间，当前已生效时返回 now
+func (s *channelSchedule) NextActive(now time.Time) time.Time {
+	if s.Active(now) {
+		return now
+	}
+	local := now.In(s.location)
+	// 生效时段总是从 start 开始，逐天查找第一个允许的 start 时刻
+	for i := 0; i <= 7; i++ {
+		day := local.AddDate(0, 0, i)
+		candidate := time.Date(day.Year(), day.Month(), day.Day(), s.start/60, s.start%60, 0, 0, s.location)
+		if candidate.After(now) && s.dayAllowed(candidate.Weekday()) {
+			return candidate
+		}
+	}
+	return now
+}
+
+func (s *channelSchedule) dayAllowed(day time.Weekday) bool {
+	return len(s.days) == 0 || s.days[day]
+}
+
+// ChannelScheduleStatus 渠道生效时间状态
+type ChannelScheduleStatus struct {
+	Scheduled    bool  `json:"scheduled"`              // 是否配置了生效时间段
+	Active       bool  `json:"active"`                 // 当前是否生效
+	NextActiveAt int64 `json:"nextActiveAt,omitempty"` // 下一次生效时间（毫秒时间戳），当前生效时为空
+}
+
+// GetChannelScheduleStatus 获取渠道当前是否处于生效时间段及下一次生效时间
+func GetChannelScheduleStatus(config map[string]interface{}, now time.Time) (*ChannelScheduleStatus, error) {
+	schedule, err := parseChannelSchedule(config)
+	if err != nil {
+		return nil, err
+	}
+	if schedule == nil {
+		return &ChannelScheduleStatus{Active: true}, nil
+	}
+	status := &ChannelScheduleStatus{Scheduled: true, Active: schedule.Active(now)}
+	if !status.Active {
+		status.NextActiveAt = schedule.NextActive(now).UnixMilli()
+	}
+	return status, nil
+}
+
+// holdNotification 渠道不在生效时间段内时暂缓通知，到下一次生效时间再发送
+func (n *Notifier) holdNotification(channelConfig models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, until time.Time) {
+	n.logger.Info(fmt.Sprintf("渠道不在生效时间内，暂缓通知直到 %s", until.Format("15:04")),
+		zap.String("channelType", channelConfig.Type),
+		zap.String("channelName", channelConfig.Name),
+		zap.Int64("recordId", record.ID),
+		zap.Time("until", until),
+	)
+	time.AfterFunc(time.Until(until), func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		if err := ignoreQueued(n.SendNotificationByConfig(ctx, &channelConfig, record, agent)); err != nil {
+			n.logger.Error("发送暂缓的通知失败",
+				zap.String("channelType", channelConfig.Type),
+				zap.Int64("recordId", record.ID),
+				zap.String("error", deliveryErrorMessage(err)),
+			)
+		}
+	})
+}
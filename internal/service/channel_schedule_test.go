@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestChannelScheduleNextActive(t *testing.T) {
+	schedule, err := parseChannelSchedule(map[string]interface{}{
+		"schedule": map[string]interface{}{
+			"start":    "08:00",
+			"end":      "22:00",
+			"days":     []interface{}{float64(1), float64(2), float64(3), float64(4), float64(5)},
+			"timezone": "UTC",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want time.Time
+	}{
+		{"生效时间内", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)},
+		{"当天早于开始时间", time.Date(2024, 1, 2, 6, 30, 0, 0, time.UTC), time.Date(2024, 1, 2, 8, 0, 0, 0, time.UTC)},
+		{"当天晚于结束时间", time.Date(2024, 1, 2, 23, 0, 0, 0, time.UTC), time.Date(2024, 1, 3, 8, 0, 0, 0, time.UTC)},
+		{"周五晚上顺延到周一", time.Date(2024, 1, 5, 23, 0, 0, 0, time.UTC), time.Date(2024, 1, 8, 8, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := schedule.NextActive(tt.now); !got.Equal(tt.want) {
+			t.Errorf("%s: NextActive() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestChannelScheduleOvernight(t *testing.T) {
+	schedule, err := parseChannelSchedule(map[string]interface{}{
+		"schedule": map[string]interface{}{"start": "22:00", "end": "06:00", "timezone": "UTC"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !schedule.Active(time.Date(2024, 1, 1, 23, 0, 0, 0, time.UTC)) || !schedule.Active(time.Date(2024, 1, 2, 5, 0, 0, 0, time.UTC)) {
+		t.Fatal("expected overnight window to be active")
+	}
+	want := time.Date(2024, 1, 2, 22, 0, 0, 0, time.UTC)
+	if got := schedule.NextActive(time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC)); !got.Equal(want) {
+		t.Fatalf("NextActive() = %v, want %v", got, want)
+	}
+}
+
+func TestMatchSilenceRule(t *testing.T) {
+	// 2024-01-07 为周日
+	now := time.Date(2024, 1, 7, 3, 30, 0, 0, time.UTC)
+	rules := []models.SilenceRule{
+		{ID: "expired", AlertType: "cpu", EndAt: now.Add(-time.Minute).UnixMilli()},
+		{ID: "other-agent", AgentID: "agent-2", EndAt: now.Add(time.Hour).UnixMilli()},
+		{ID: "weekly", AgentID: "agent-1", Schedule: &models.SilenceWindow{Start: "02:00", End: "04:00", Days: []int{0}, Timezone: "UTC"}},
+	}
+
+	record := &models.AlertRecord{AgentID: "agent-1", AlertType: "cpu"}
+	if rule := matchSilenceRule(rules, record, now); rule == nil || rule.ID != "weekly" {
+		t.Fatalf("matchSilenceRule() = %v, want weekly", rule)
+	}
+	if rule := matchSilenceRule(rules, record, now.Add(time.Hour)); rule != nil {
+		t.Fatalf("matchSilenceRule() outside window = %v, want nil", rule.ID)
+	}
+	if rule := matchSilenceRule(rules, &models.AlertRecord{AgentID: "agent-2", AlertType: "disk"}, now); rule == nil || rule.ID != "other-agent" {
+		t.Fatalf("matchSilenceRule() = %v, want other-agent", rule)
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/dushixiang/pika/internal/models"
+	"gorm.io/gorm"
+)
+
+// RedactedSecret 返回给前端的敏感字段占位值，保存时表示“保留原值”
+const RedactedSecret = "********"
+
+// RedactChannelSecrets 将渠道配置中已设置的敏感字段替换为占位值，返回副本
+func RedactChannelSecrets(channel models.NotificationChannelConfig) models.NotificationChannelConfig {
+	redacted, _ := transformChannelSecrets(channel, func(value string) (string, error) {
+		if value == "" {
+			return value, nil
+		}
+		return RedactedSecret, nil
+	})
+	return redacted
+}
+
+// restoreRedactedSecrets 将提交的渠道配置中仍为占位值的敏感字段还原为已保存的值
+// 有 ID 的渠道按 ID 匹配已保存的渠道，旧配置没有 ID 时按相同位置且类型相同匹配；
+// 找不到原值的占位值清空，由配置校验提示缺少字段
+func restoreRedactedSecrets(channels, existing []models.NotificationChannelConfig) []models.NotificationChannelConfig {
+	restored := make([]models.NotificationChannelConfig, len(channels))
+	for i, channel := range channels {
+		var previous map[string]interface{}
+		for j, item := range existing {
+			if (channel.ID != "" && item.ID == channel.ID) || (channel.ID == "" && j == i && item.Type == channel.Type) {
+				previous = item.Config
+				break
+			}
+		}
+		keys := channelSecretKeys[channel.Type]
+		if len(keys) > 0 && channel.Config != nil {
+			channel.Config = restoreRedactedValue(channel.Config, previous, false, keys).(map[string]interface{})
+		}
+		restored[i] = channel
+	}
+	return restored
+}
+
+func restoreRedactedValue(value, previous interface{}, secret bool, keys map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		previousMap, _ := previous.(map[string]interface{})
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			result[k] = restoreRedactedValue(item, previousMap[k], secret || keys[k], keys)
+		}
+		return result
+	case []interface{}:
+		previousSlice, _ := previous.([]interface{})
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			var previousItem interface{}
+			if i < len(previousSlice) {
+				previousItem = previousSlice[i]
+			}
+			result[i] = restoreRedactedValue(item, previousItem, secret, keys)
+		}
+		return result
+	case string:
+		if secret && v == RedactedSecret {
+			previousValue, _ := previous.(string)
+			return previousValue
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// RestoreRedactedChannelSecrets 保存旧版通知渠道配置前，将占位值还原为已保存的敏感字段
+func (s *PropertyService) RestoreRedactedChannelSecrets(ctx context.Context, channels []models.NotificationChannelConfig) ([]models.NotificationChannelConfig, error) {
+	existing, err := s.GetNotificationChannelConfigs(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return restoreRedactedSecrets(channels, existing), nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// renderDingTalkMarkdown 将告警消息渲染为钉钉 Markdown：加粗标题和字段表格
+func renderDingTalkMarkdown(content messageContent, opts messageOptions) string {
+	var b strings.Builder
+	b.WriteString("#### **" + escapeMarkdown(content.Title) + "**\n\n")
+	b.WriteString("| " + opts.text("table.field") + " | " + opts.text("table.value") + " |\n")
+	b.WriteString("| --- | --- |\n")
+	for _, line := range content.Lines {
+		b.WriteString("| " + escapeMarkdown(line.Label) + " | " + escapeMarkdown(line.Value) + " |\n")
+	}
+	return b.String()
+}
+
+// buildDingTalkBody 按渠道配置的消息类型构造钉钉消息体
+// 配置了自定义模板或无法结构化的消息以 message 原文作为 Markdown 正文；
+// ActionCard 未配置详情页地址时退化为 Markdown
+func (n *Notifier) buildDingTalkBody(ctx context.Context, cfg DingTalkConfig, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) map[string]interface{} {
+	if cfg.MsgType == "text" {
+		return withDingTalkMention(dingTalkTextBody(message+dingTalkMentionText(cfg.Mention, record, "\n")), cfg.Mention, record)
+	}
+
+	opts := n.messageOptions(ctx, config)
+	title, _, _ := strings.Cut(message, "\n")
+	text := message
+	if content, ok := n.buildMessageContent(agent, record, opts); ok && !opts.hasMessageTemplate(record.Status) {
+		title = content.Title
+		text = renderDingTalkMarkdown(content, opts)
+	}
+	text += dingTalkMentionText(cfg.Mention, record, "\n\n")
+
+	detailURL := ""
+	if agent != nil {
+		detailURL = agentDetailURL(cfg.BaseURL, agent.ID)
+	}
+	if cfg.MsgType == "actionCard" && detailURL != "" {
+		// ActionCard 不支持 at，仅在正文中展示被 @ 的手机号
+		return map[string]interface{}{
+			"msgtype": "actionCard",
+			"actionCard": map[string]interface{}{
+				"title":       title,
+				"text":        text,
+				"singleTitle": opts.text("link.detail"),
+				"singleURL":   detailURL,
+			},
+		}
+	}
+	return withDingTalkMention(map[string]interface{}{
+		"msgtype": "markdown",
+		"markdown": map[string]string{
+			"title": title,
+			"text":  text,
+		},
+	}, cfg.Mention, record)
+}
+
+// dingTalkMentionText 钉钉仅在正文中包含 @手机号 时才会 @ 对应成员，返回需追加到正文的内容
+func dingTalkMentionText(mention MentionConfig, record *models.AlertRecord, separator string) string {
+	if !mention.Active(record) || len(mention.Mobiles) == 0 {
+		return ""
+	}
+	return separator + "@" + strings.Join(mention.Mobiles, " @")
+}
+
+// withDingTalkMention 为消息体附加 at 对象
+func withDingTalkMention(body map[string]interface{}, mention MentionConfig, record *models.AlertRecord) map[string]interface{} {
+	if !mention.Active(record) {
+		return body
+	}
+	body["at"] = map[string]interface{}{
+		"atMobiles": mention.Mobiles,
+		"isAtAll":   mention.All,
+	}
+	return body
+}
+
+// sendDingTalkAlert 按渠道配置的消息类型发送钉钉告警通知
+func (n *Notifier) sendDingTalkAlert(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseDingTalkConfig(config)
+	if err != nil {
+		return err
+	}
+	return n.sendDingTalkToRobots(ctx, cfg, n.buildDingTalkBody(ctx, cfg, config, agent, record, message))
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// discordDescriptionLimit Discord embed 描述最多 4096 个字符，按字节截断可保证不超限
+const discordDescriptionLimit = 4096
+
+// discordLevelColors 告警级别对应的 embed 颜色
+var discordLevelColors = map[string]int{
+	"info":     0x439FE0,
+	"warning":  0xFFA500,
+	"critical": 0xE74C3C,
+}
+
+// DiscordConfig Discord 渠道配置
+type DiscordConfig struct {
+	WebhookURL string // Webhook 地址
+}
+
+// ParseDiscordConfig 解析并校验 Discord 渠道配置
+// 配置格式: { "webhookUrl": "https://discord.com/api/webhooks/xxx/xxx" }
+func ParseDiscordConfig(config map[string]interface{}) (DiscordConfig, error) {
+	var cfg DiscordConfig
+	cfg.WebhookURL, _ = config["webhookUrl"].(string)
+	cfg.WebhookURL = strings.TrimSpace(cfg.WebhookURL)
+	if cfg.WebhookURL == "" {
+		return cfg, fmt.Errorf("Discord 配置缺少 webhookUrl")
+	}
+	if !strings.HasPrefix(cfg.WebhookURL, "https://") && !strings.HasPrefix(cfg.WebhookURL, "http://") {
+		return cfg, fmt.Errorf("Discord webhookUrl 必须以 http:// 或 https:// 开头")
+	}
+	return cfg, nil
+}
+
+// sendDiscordByConfig 根据配置发送 Discord 通知，探针信息和阈值放在 embed 字段中
+// Discord 成功时返回 204 空响应体
+func (n *Notifier) sendDiscordByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseDiscordConfig(config)
+	if err != nil {
+		return err
+	}
+
+	opts := n.messageOptions(ctx, config)
+	title := record.AlertType
+	if content, ok := n.buildMessageContent(agent, record, opts); ok {
+		title = content.Title
+	}
+
+	field := func(name, value string, inline bool) map[string]interface{} {
+		// 字段值不能为空
+		if value == "" {
+			value = "-"
+		}
+		return map[string]interface{}{"name": name, "value": value, "inline": inline}
+	}
+	fields := []interface{}{
+		field(opts.text("label.probe"), agent.Name, false),
+		field(opts.text("label.host"), agent.Hostname, false),
+		field(opts.text("label.ip"), agent.IP, false),
+	}
+	if hasMetricValue(record.AlertType) {
+		fields = append(fields,
+			field(opts.text("label.threshold"), formatAlertValue(record.AlertType, record.Threshold, opts), true),
+			field(opts.text("label.value"), formatAlertValue(record.AlertType, record.ActualValue, opts), true),
+		)
+	}
+	embed := map[string]interface{}{
+		"title":       title,
+		"description": truncateMessage(message, discordDescriptionLimit, channelDetailURL(config, agent)),
+		"fields":      fields,
+	}
+	if color, ok := discordLevelColors[record.Level]; ok {
+		embed["color"] = color
+	}
+
+	_, err = n.sendJSONRequest(ctx, cfg.WebhookURL, map[string]interface{}{
+		"embeds": []interface{}{embed},
+	})
+	return err
+}
+
+// sendDiscordRaw 发送已构建好的纯文本消息
+func (n *Notifier) sendDiscordRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseDiscordConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = n.sendJSONRequest(ctx, cfg.WebhookURL, map[string]interface{}{
+		"embeds": []interface{}{
+			map[string]interface{}{"description": truncateMessage(message, discordDescriptionLimit, "")},
+		},
+	})
+	return err
+}
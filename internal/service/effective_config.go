@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// EffectiveChannel 某条告警在单个渠道上实际生效的配置
+type EffectiveChannel struct {
+	ID       string                 `json:"id"`
+	Name     string                 `json:"name"`
+	Type     string                 `json:"type"`
+	Priority int                    `json:"priority"`
+	Fallback bool                   `json:"fallback"`           // 备用渠道：仅在所有主渠道失败时发送
+	Notify   bool                   `json:"notify"`             // 是否会发送（备用渠道仅在主渠道全部失败时发送）
+	Reasons  []string               `json:"reasons,omitempty"`  // 不发送或延迟发送的原因
+	Locale   string                 `json:"locale"`             // 消息语言
+	Schedule *ChannelScheduleStatus `json:"schedule,omitempty"` // 生效时间段状态
+}
+
+// EffectiveNotificationConfig 某个探针、告警类型和级别最终生效的通知配置
+type EffectiveNotificationConfig struct {
+	AgentID       string             `json:"agentId"`
+	AlertType     string             `json:"alertType,omitempty"`
+	Level         string             `json:"level,omitempty"`
+	Status        string             `json:"status"`
+	AlertEnabled  bool               `json:"alertEnabled"`     // 全局告警开关
+	AggregationOn bool               `json:"aggregationOn"`    // 是否开启跨探针聚合（实际发送会延迟到聚合窗口结束）
+	Muted         bool               `json:"muted"`            // 告警类型是否被全局静音
+	Snoozed       bool               `json:"snoozed"`          // 该探针的告警类型是否处于暂停通知期
+	Labels        map[string]string  `json:"labels,omitempty"` // 告警规则附加的标签
+	Channels      []EffectiveChannel `json:"channels"`         // 按发送顺序排列
+}
+
+// explainChannels 按 SendNotificationByConfigs 的过滤规则说明每个渠道是否会收到通知
+func (n *Notifier) explainChannels(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, now time.Time) []EffectiveChannel {
+	channels := make([]EffectiveChannel, 0, len(channelConfigs))
+	for _, channelConfig := range channelConfigs {
+		channel := EffectiveChannel{
+			ID:       channelConfig.ID,
+			Name:     channelConfig.DisplayName(),
+			Type:     channelConfig.Type,
+			Priority: channelConfig.Priority,
+			Fallback: channelConfig.Fallback,
+			Locale:   n.messageOptions(ctx, channelConfig.Config).locale(),
+		}
+		if !channelConfig.Enabled {
+			channel.Reasons = append(channel.Reasons, "渠道已禁用")
+		}
+		if channelConfig.TestOnly {
+			channel.Reasons = append(channel.Reasons, "仅测试渠道，不接收真实告警")
+		}
+		if isDigestChannel(channelConfig.Config) {
+			channel.Reasons = append(channel.Reasons, "每日摘要渠道，不接收实时告警")
+		}
+		if suppressOfflineResolve(channelConfig.Config, record, agent) {
+			channel.Reasons = append(channel.Reasons, "探针已离线，不发送指标告警的恢复通知")
+		}
+		if record.Status == "resolved" && !notifyOnResolve(channelConfig.Config, record.Level) {
+			channel.Reasons = append(channel.Reasons, "未开启该级别的恢复通知")
+		}
+		channel.Notify = len(channel.Reasons) == 0
+
+		if status, err := GetChannelScheduleStatus(channelConfig.Config, now); err != nil {
+			channel.Reasons = append(channel.Reasons, "生效时间配置无效: "+err.Error())
+		} else if status.Scheduled {
+			channel.Schedule = status
+			if !status.Active {
+				channel.Reasons = append(channel.Reasons, "不在生效时间内，通知将暂缓到下一次生效时间")
+			}
+		}
+		channels = append(channels, channel)
+	}
+
+	// 与实际发送顺序一致：主渠道在前，各自按优先级从高到低
+	sort.SliceStable(channels, func(i, j int) bool {
+		if channels[i].Fallback != channels[j].Fallback {
+			return !channels[i].Fallback
+		}
+		return channels[i].Priority > channels[j].Priority
+	})
+	return channels
+}
+
+// EffectiveNotificationConfig 计算指定探针、告警类型和级别实际生效的通知配置，便于排查告警为何（未）发送通知
+// level 为空时使用告警类型的默认级别，status 为空时按 firing 计算
+func (s *AlertService) EffectiveNotificationConfig(ctx context.Context, agentID, alertType, level, status string) (*EffectiveNotificationConfig, error) {
+	agent, err := s.agentRepo.FindById(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	if status == "" {
+		status = "firing"
+	}
+
+	record := &models.AlertRecord{
+		AgentID:   agent.ID,
+		AgentName: agent.Name,
+		AlertType: alertType,
+		Level:     level,
+		Status:    status,
+	}
+	result := &EffectiveNotificationConfig{
+		AgentID:   agent.ID,
+		AlertType: alertType,
+		Status:    status,
+	}
+
+	alertConfig, err := s.propertyService.GetAlertConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.AlertEnabled = alertConfig.Enabled
+	result.AggregationOn = alertConfig.Aggregation.Enabled
+	if alertType != "" {
+		record.Labels = alertConfig.Rules.LabelsFor(alertType)
+		record = s.notifier.withDefaultLevel(ctx, record)
+		result.Muted = s.notifier.isMutedAlertType(ctx, alertType)
+		result.Snoozed = s.notifier.isSnoozed(ctx, record)
+	}
+	result.Level = record.Level
+	result.Labels = record.Labels
+
+	channelConfigs, err := s.channelService.GetChannelConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result.Channels = s.notifier.explainChannels(ctx, channelConfigs, record, &agent, time.Now())
+	return result, nil
+}
@@ -0,0 +1,341 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultSMTPTimeout ctx 未设置截止时间时 SMTP 会话的最长时间
+const defaultSMTPTimeout = 30 * time.Second
+
+// EmailConfig 邮件渠道配置
+type EmailConfig struct {
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	From        string   // 发件人，可为 "Pika <pika@example.com>" 形式
+	FromAddress string   // 发件人邮箱地址，用于 SMTP MAIL FROM
+	To          []string // 收件人邮箱地址
+	Cc          []string // 抄送邮箱地址
+	// RequireStartTLS 非 465 端口时要求服务器支持 STARTTLS，不支持时拒绝以明文发送
+	RequireStartTLS bool
+}
+
+// parseAddressList 解析收件人列表，支持数组或逗号分隔的字符串，返回纯邮箱地址
+func parseAddressList(key string, raw interface{}) ([]string, error) {
+	var values []string
+	switch v := raw.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if addr, ok := item.(string); ok {
+				values = append(values, addr)
+			}
+		}
+	case string:
+		values = strings.Split(v, ",")
+	}
+
+	var addrs []string
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		addr, err := mail.ParseAddress(value)
+		if err != nil {
+			return nil, fmt.Errorf("邮件配置 %s 中的地址无效: %s", key, value)
+		}
+		addrs = append(addrs, addr.Address)
+	}
+	return addrs, nil
+}
+
+// ParseEmailConfig 解析邮件渠道配置，地址格式在连接 SMTP 服务器之前校验
+// 配置格式: { "smtpHost": "smtp.example.com", "smtpPort": 587, "username": "xxx", "password": "xxx", "from": "Pika <pika@example.com>", "to": ["ops@example.com"], "cc": ["leader@example.com"], "requireStartTLS": true }
+func ParseEmailConfig(config map[string]interface{}) (*EmailConfig, error) {
+	cfg := &EmailConfig{}
+	cfg.Host, _ = config["smtpHost"].(string)
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("邮件配置缺少 smtpHost")
+	}
+
+	switch port := config["smtpPort"].(type) {
+	case float64:
+		cfg.Port = int(port)
+	case string:
+		cfg.Port, _ = strconv.Atoi(port)
+	}
+	if cfg.Port <= 0 {
+		cfg.Port = 587
+	}
+
+	cfg.Username, _ = config["username"].(string)
+	cfg.Password, _ = config["password"].(string)
+	cfg.RequireStartTLS, _ = config["requireStartTLS"].(bool)
+	cfg.From, _ = config["from"].(string)
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("邮件配置缺少 from")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("邮件配置 from 地址无效: %s", cfg.From)
+	}
+	cfg.From = from.String()
+	cfg.FromAddress = from.Address
+
+	if cfg.To, err = parseAddressList("to", config["to"]); err != nil {
+		return nil, err
+	}
+	if cfg.Cc, err = parseAddressList("cc", config["cc"]); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// dialSMTP 建立 SMTP 连接并完成 TLS 与认证
+// net/smtp 本身不支持 context，这里使用支持 context 的 Dialer 建立连接，
+// 并为底层连接设置截止时间、在 ctx 取消时立即中断，避免 SMTP 服务器无响应时无限阻塞
+func dialSMTP(ctx context.Context, cfg *EmailConfig) (*smtp.Client, func(), error) {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接SMTP服务器失败: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultSMTPTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+	// ctx 取消时将截止时间设为过去，使阻塞中的读写立即返回
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetDeadline(time.Unix(1, 0))
+	})
+
+	tlsConfig := &tls.Config{ServerName: cfg.Host, MinVersion: tls.VersionTLS12}
+
+	// 465 端口使用隐式 TLS
+	if cfg.Port == 465 {
+		tlsConn := tls.Client(conn, tlsConfig)
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			stop()
+			conn.Close()
+			return nil, nil, fmt.Errorf("SMTP TLS握手失败: %w", err)
+		}
+		conn = tlsConn
+	}
+
+	client, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		stop()
+		conn.Close()
+		return nil, nil, fmt.Errorf("SMTP会话建立失败: %w", err)
+	}
+	cleanup := func() {
+		stop()
+		client.Close()
+	}
+
+	if cfg.Port != 465 {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(tlsConfig); err != nil {
+				cleanup()
+				return nil, nil, fmt.Errorf("SMTP STARTTLS失败: %w", err)
+			}
+		} else if cfg.RequireStartTLS {
+			cleanup()
+			return nil, nil, fmt.Errorf("SMTP服务器不支持STARTTLS，已拒绝以明文发送")
+		}
+	}
+
+	// 配置了用户名但服务器未提供认证时直接失败，避免以未认证身份发信
+	if cfg.Username != "" {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			cleanup()
+			return nil, nil, fmt.Errorf("SMTP服务器未提供认证（AUTH），无法使用用户名登录")
+		}
+		auth := smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+		if err := client.Auth(auth); err != nil {
+			cleanup()
+			return nil, nil, fmt.Errorf("SMTP认证失败: %w", err)
+		}
+	}
+
+	return client, cleanup, nil
+}
+
+// buildEmailSubject 根据告警级别和类型生成邮件主题
+func buildEmailSubject(icon, alertTypeName, status string) string {
+	subject := strings.TrimSpace(icon + " " + alertTypeName)
+	if status != "" {
+		subject += " - " + status
+	}
+	return subject
+}
+
+// buildEmailMessage 构造邮件内容（RFC 5322）：multipart/alternative，包含纯文本和 HTML 两个部分
+// 邮件客户端优先展示 HTML，不支持时展示纯文本；正文使用 quoted-printable 编码，避免超长行被服务器截断
+func buildEmailMessage(cfg *EmailConfig, subject, text, htmlBody string, now time.Time) []byte {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	writePart := func(contentType, content string) {
+		part, _ := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType + "; charset=UTF-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		qp := quotedprintable.NewWriter(part)
+		_, _ = qp.Write([]byte(strings.ReplaceAll(content, "\n", "\r\n")))
+		_ = qp.Close()
+	}
+	writePart("text/plain", text)
+	writePart("text/html", htmlBody)
+	_ = mw.Close()
+
+	var b strings.Builder
+	b.WriteString("From: " + cfg.From + "\r\n")
+	b.WriteString("To: " + strings.Join(cfg.To, ", ") + "\r\n")
+	if len(cfg.Cc) > 0 {
+		b.WriteString("Cc: " + strings.Join(cfg.Cc, ", ") + "\r\n")
+	}
+	b.WriteString("Subject: " + mimeEncodeHeader(subject) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("Message-ID: " + newMessageID(cfg.FromAddress, now) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: multipart/alternative; boundary=\"" + mw.Boundary() + "\"\r\n")
+	b.WriteString("\r\n")
+	b.Write(body.Bytes())
+	return []byte(b.String())
+}
+
+// newMessageID 生成邮件的 Message-ID，域名取自发件人地址
+func newMessageID(fromAddress string, now time.Time) string {
+	domain := "pika.localhost"
+	if i := strings.LastIndex(fromAddress, "@"); i >= 0 && i < len(fromAddress)-1 {
+		domain = fromAddress[i+1:]
+	}
+	random := make([]byte, 8)
+	_, _ = rand.Read(random)
+	return fmt.Sprintf("<%d.%s@%s>", now.UnixNano(), hex.EncodeToString(random), domain)
+}
+
+// mimeEncodeHeader 对包含非 ASCII 字符的邮件头做 MIME 编码，并去除换行防止邮件头注入
+func mimeEncodeHeader(s string) string {
+	s = strings.NewReplacer("\r", " ", "\n", " ").Replace(s)
+	for _, r := range s {
+		if r > 127 {
+			return mime.BEncoding.Encode("UTF-8", s)
+		}
+	}
+	return s
+}
+
+// TestSMTPConnection 测试 SMTP 连通性：建立连接并完成 STARTTLS 与认证，随后发送 NOOP/QUIT，不发送邮件
+func (n *Notifier) TestSMTPConnection(ctx context.Context, config map[string]interface{}) error {
+	cfg, err := ParseEmailConfig(config)
+	if err != nil {
+		return err
+	}
+
+	client, cleanup, err := dialSMTP(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := client.Noop(); err != nil {
+		return fmt.Errorf("SMTP NOOP失败: %w", err)
+	}
+	if err := client.Quit(); err != nil {
+		return fmt.Errorf("SMTP QUIT失败: %w", err)
+	}
+	return nil
+}
+
+// sendEmail 发送邮件，遵循 ctx 的取消与超时；htmlBody 为空时由纯文本生成
+// 所有收件人（含抄送）在同一个 SMTP 会话中提交，只发送一次邮件内容
+func (n *Notifier) sendEmail(ctx context.Context, cfg *EmailConfig, subject, body, htmlBody string) error {
+	if len(cfg.To) == 0 {
+		return fmt.Errorf("邮件配置缺少收件人 to")
+	}
+
+	if htmlBody == "" {
+		htmlBody = renderEmailHTML(emailHTMLData{Title: subject, Color: emailDefaultColor, Text: body})
+	}
+	message := buildEmailMessage(cfg, subject, body, htmlBody, time.Now())
+	if recorder := dryRunFrom(ctx); recorder != nil {
+		recorder.add(RenderedRequest{
+			Method: "SMTP",
+			URL:    fmt.Sprintf("smtp://%s:%d", cfg.Host, cfg.Port),
+			Body:   string(message),
+		})
+		return errDryRun
+	}
+
+	client, cleanup, err := dialSMTP(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	if err := client.Mail(cfg.FromAddress); err != nil {
+		return fmt.Errorf("SMTP服务器拒绝发件人 %s: %w", cfg.From, err)
+	}
+
+	// 逐个提交收件人，被拒绝的收件人不影响其他收件人
+	recipients := append(append([]string{}, cfg.To...), cfg.Cc...)
+	results := make([]RecipientResult, 0, len(recipients))
+	var accepted []string
+	for _, to := range recipients {
+		if err := client.Rcpt(to); err != nil {
+			results = append(results, RecipientResult{Recipient: to, Error: fmt.Sprintf("SMTP服务器拒绝收件人: %v", err)})
+			continue
+		}
+		accepted = append(accepted, to)
+	}
+	if len(accepted) == 0 {
+		if len(results) == 1 {
+			return fmt.Errorf("SMTP服务器拒绝收件人 %s: %s", results[0].Recipient, results[0].Error)
+		}
+		return &RecipientError{Results: results}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP发送数据失败: %w", err)
+	}
+	if _, err := w.Write(message); err != nil {
+		return fmt.Errorf("SMTP发送数据失败: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("SMTP发送数据失败: %w", err)
+	}
+	for _, to := range accepted {
+		results = append(results, RecipientResult{Recipient: to, Success: true})
+	}
+	if err := client.Quit(); err != nil {
+		n.logger.Debug("SMTP QUIT 失败", zap.Error(err))
+	}
+	return recipientResultsError(results)
+}
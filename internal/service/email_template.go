@@ -0,0 +1,81 @@
+package service
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// emailDefaultColor 非告警邮件（测试、公告等）的标题栏颜色
+const emailDefaultColor = "#439FE0"
+
+// emailLevelColors 告警级别对应的邮件标题栏颜色，与 Discord/Teams 等渠道一致
+var emailLevelColors = map[string]string{
+	"info":     "#439FE0",
+	"warning":  "#FFA500",
+	"critical": "#E74C3C",
+}
+
+// emailResolvedColor 恢复通知的标题栏颜色
+const emailResolvedColor = "#2ECC71"
+
+// emailHTMLData HTML 邮件模板数据
+type emailHTMLData struct {
+	Title string        // 标题
+	Color string        // 标题栏颜色
+	Lines []messageLine // 字段，可为空
+	Text  string        // 正文，保留换行
+}
+
+// emailHTMLTemplate HTML 邮件模板，邮件客户端对 CSS 支持有限，统一使用表格布局和内联样式
+var emailHTMLTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="UTF-8"></head>
+<body style="margin:0;padding:16px;background:#f5f5f5;font-family:-apple-system,'Segoe UI',Helvetica,Arial,sans-serif;">
+<table role="presentation" width="100%" cellpadding="0" cellspacing="0" style="max-width:600px;margin:0 auto;background:#ffffff;border-radius:6px;">
+<tr><td style="background:{{.Color}};color:#ffffff;padding:16px 20px;font-size:18px;font-weight:bold;border-radius:6px 6px 0 0;">{{.Title}}</td></tr>
+{{- if .Lines}}
+<tr><td style="padding:12px 20px;">
+<table role="presentation" cellpadding="0" cellspacing="0" style="font-size:14px;">
+{{- range .Lines}}
+<tr><td style="padding:6px 16px 6px 0;color:#666666;white-space:nowrap;vertical-align:top;">{{.Label}}</td><td style="padding:6px 0;color:#222222;">{{.Value}}</td></tr>
+{{- end}}
+</table>
+</td></tr>
+{{- end}}
+{{- if .Text}}
+<tr><td style="padding:12px 20px;font-size:14px;color:#222222;white-space:pre-wrap;">{{.Text}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+`))
+
+// renderEmailHTML 渲染 HTML 邮件正文，字段值均经过 HTML 转义
+func renderEmailHTML(data emailHTMLData) string {
+	var b strings.Builder
+	if err := emailHTMLTemplate.Execute(&b, data); err != nil {
+		return ""
+	}
+	return b.String()
+}
+
+// buildEmailHTML 构建告警邮件的 HTML 正文：标题栏按告警级别着色（恢复为绿色），字段以表格展示
+// 配置了自定义模板或无法结构化的消息以 message 原文作为正文
+func (n *Notifier) buildEmailHTML(agent *models.Agent, record *models.AlertRecord, opts messageOptions, message string) string {
+	color := emailLevelColors[record.Level]
+	if record.Status == "resolved" {
+		color = emailResolvedColor
+	}
+	if color == "" {
+		color = emailDefaultColor
+	}
+
+	content, ok := n.buildMessageContent(agent, record, opts)
+	if !ok || opts.hasMessageTemplate(record.Status) {
+		title, body, _ := strings.Cut(message, "\n")
+		return renderEmailHTML(emailHTMLData{Title: title, Color: color, Text: strings.TrimLeft(body, "\n")})
+	}
+	return renderEmailHTML(emailHTMLData{Title: content.Title, Color: color, Lines: content.Lines})
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseEmailConfigAddresses(t *testing.T) {
+	cfg, err := ParseEmailConfig(map[string]interface{}{
+		"smtpHost": "smtp.example.com",
+		"from":     "Pika <pika@example.com>",
+		"to":       "ops@example.com, Dev <dev@example.com>",
+		"cc":       []interface{}{"leader@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("ParseEmailConfig() error = %v", err)
+	}
+	if cfg.FromAddress != "pika@example.com" || len(cfg.To) != 2 || cfg.To[1] != "dev@example.com" || len(cfg.Cc) != 1 {
+		t.Fatalf("地址解析错误: %+v", cfg)
+	}
+
+	if _, err := ParseEmailConfig(map[string]interface{}{"smtpHost": "smtp.example.com", "from": "not-an-address", "to": "ops@example.com"}); err == nil {
+		t.Fatalf("无效的 from 应在连接前返回错误")
+	}
+}
+
+// startPlainSMTPServer 启动一个不支持 STARTTLS 和 AUTH 的 SMTP 服务器，返回端口
+func startPlainSMTPServer(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				_, _ = io.WriteString(conn, "220 localhost ESMTP\r\n")
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					switch {
+					case strings.HasPrefix(line, "EHLO"):
+						_, _ = io.WriteString(conn, "250-localhost\r\n250 8BITMIME\r\n")
+					case strings.HasPrefix(line, "QUIT"):
+						_, _ = io.WriteString(conn, "221 bye\r\n")
+						return
+					default:
+						_, _ = io.WriteString(conn, "250 ok\r\n")
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+func TestDialSMTPRejectsMissingSecurity(t *testing.T) {
+	port := startPlainSMTPServer(t)
+	tests := []struct {
+		name string
+		cfg  EmailConfig
+	}{
+		{name: "配置用户名但服务器不支持AUTH", cfg: EmailConfig{Host: "127.0.0.1", Port: port, Username: "pika", Password: "secret"}},
+		{name: "要求STARTTLS但服务器不支持", cfg: EmailConfig{Host: "127.0.0.1", Port: port, RequireStartTLS: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := dialSMTP(context.Background(), &tt.cfg); err == nil {
+				t.Fatal("dialSMTP() error = nil, want error")
+			}
+		})
+	}
+
+	client, cleanup, err := dialSMTP(context.Background(), &EmailConfig{Host: "127.0.0.1", Port: port})
+	if err != nil {
+		t.Fatalf("未配置认证时 dialSMTP() error = %v", err)
+	}
+	_ = client.Quit()
+	cleanup()
+}
+
+func TestBuildEmailMessage(t *testing.T) {
+	cfg := &EmailConfig{From: "<pika@example.com>", FromAddress: "pika@example.com", To: []string{"ops@example.com"}, Cc: []string{"leader@example.com"}}
+	data := buildEmailMessage(cfg, "🚨 CPU告警", "探针: web-1", "<p>web-1</p>", time.Now())
+
+	msg, err := mail.ReadMessage(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("邮件格式错误: %v", err)
+	}
+	if msg.Header.Get("Cc") != "leader@example.com" || !strings.HasSuffix(msg.Header.Get("Message-ID"), "@example.com>") {
+		t.Fatalf("邮件头错误: %v", msg.Header)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %s", msg.Header.Get("Content-Type"))
+	}
+
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	var types, bodies []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("读取邮件正文失败: %v", err)
+		}
+		body, _ := io.ReadAll(part)
+		types = append(types, part.Header.Get("Content-Type"))
+		bodies = append(bodies, string(body))
+	}
+	if len(types) != 2 || !strings.HasPrefix(types[0], "text/plain") || !strings.HasPrefix(types[1], "text/html") {
+		t.Fatalf("邮件应包含纯文本和 HTML 两部分: %v", types)
+	}
+	if bodies[0] != "探针: web-1" || bodies[1] != "<p>web-1</p>" {
+		t.Fatalf("正文解码错误: %q", bodies)
+	}
+}
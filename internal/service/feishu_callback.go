@@ -0,0 +1,162 @@
+package service
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// feishuCallbackMaxSkew 回调请求时间戳允许的最大偏差，防止重放
+const feishuCallbackMaxSkew = 5 * time.Minute
+
+// ErrFeishuCallbackUnauthorized 飞书回调校验失败
+var ErrFeishuCallbackUnauthorized = errors.New("飞书回调校验失败")
+
+// FeishuCallback 飞书卡片回调（已校验、已解密）
+type FeishuCallback struct {
+	Type      string `json:"type"`      // 回调类型，url_verification 为地址校验
+	Challenge string `json:"challenge"` // 地址校验时需原样返回
+	Token     string `json:"token"`     // 校验 Token（1.0 协议）
+	Header    struct {
+		Token     string `json:"token"`      // 校验 Token（2.0 协议）
+		EventType string `json:"event_type"` // 事件类型
+	} `json:"header"`
+	// 卡片交互（1.0 协议）
+	Action *FeishuCardAction `json:"action"`
+	// 卡片交互（2.0 协议）
+	Event struct {
+		Action *FeishuCardAction `json:"action"`
+	} `json:"event"`
+}
+
+// FeishuCardAction 卡片按钮交互
+type FeishuCardAction struct {
+	Tag   string                 `json:"tag"`
+	Value map[string]interface{} `json:"value"`
+}
+
+// CardAction 获取卡片交互内容（兼容 1.0/2.0 协议）
+func (c *FeishuCallback) CardAction() *FeishuCardAction {
+	if c.Action != nil {
+		return c.Action
+	}
+	return c.Event.Action
+}
+
+// VerifyFeishuCallback 校验并解析飞书卡片回调
+// 渠道配置中的 encryptKey 用于签名校验和解密，verificationToken 用于 Token 校验，至少需配置其一
+func VerifyFeishuCallback(config map[string]interface{}, header http.Header, body []byte, now time.Time) (*FeishuCallback, error) {
+	encryptKey, _ := config["encryptKey"].(string)
+	verificationToken, _ := config["verificationToken"].(string)
+	if encryptKey == "" && verificationToken == "" {
+		return nil, fmt.Errorf("%w: 渠道未配置 encryptKey 或 verificationToken", ErrFeishuCallbackUnauthorized)
+	}
+
+	payload := body
+	// 是否经过签名和时间戳校验
+	var signed bool
+	var envelope struct {
+		Encrypt string `json:"encrypt"`
+	}
+	_ = json.Unmarshal(body, &envelope)
+
+	if encryptKey != "" {
+		signature := header.Get("X-Lark-Signature")
+		timestamp := header.Get("X-Lark-Request-Timestamp")
+		nonce := header.Get("X-Lark-Request-Nonce")
+		if signature != "" {
+			if !verifyFeishuSignature(timestamp, nonce, encryptKey, body, signature) {
+				return nil, fmt.Errorf("%w: 签名不匹配", ErrFeishuCallbackUnauthorized)
+			}
+			ts, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: 无效的时间戳", ErrFeishuCallbackUnauthorized)
+			}
+			if skew := now.Sub(time.Unix(ts, 0)); skew > feishuCallbackMaxSkew || skew < -feishuCallbackMaxSkew {
+				return nil, fmt.Errorf("%w: 请求已过期", ErrFeishuCallbackUnauthorized)
+			}
+		} else if envelope.Encrypt == "" {
+			// 配置了 encryptKey 时，未签名的请求必须是能用密钥解密的加密地址校验请求
+			return nil, fmt.Errorf("%w: 缺少签名", ErrFeishuCallbackUnauthorized)
+		}
+		signed = signature != ""
+
+		if envelope.Encrypt != "" {
+			decrypted, err := decryptFeishuPayload(envelope.Encrypt, encryptKey)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrFeishuCallbackUnauthorized, err)
+			}
+			payload = decrypted
+		}
+	}
+
+	var callback FeishuCallback
+	if err := json.Unmarshal(payload, &callback); err != nil {
+		return nil, fmt.Errorf("解析飞书回调失败: %w", err)
+	}
+	// 未签名的加密请求没有时间戳，截获后可被无限重放，仅允许无副作用的地址校验
+	if encryptKey != "" && !signed && callback.Type != "url_verification" {
+		return nil, fmt.Errorf("%w: 缺少签名", ErrFeishuCallbackUnauthorized)
+	}
+
+	if verificationToken != "" {
+		token := callback.Token
+		if token == "" {
+			token = callback.Header.Token
+		}
+		if !hmac.Equal([]byte(token), []byte(verificationToken)) {
+			return nil, fmt.Errorf("%w: Token 不匹配", ErrFeishuCallbackUnauthorized)
+		}
+	}
+
+	return &callback, nil
+}
+
+// verifyFeishuSignature 校验飞书回调签名: sha256(timestamp + nonce + encryptKey + body)
+func verifyFeishuSignature(timestamp, nonce, encryptKey string, body []byte, signature string) bool {
+	h := sha256.New()
+	h.Write([]byte(timestamp + nonce + encryptKey))
+	h.Write(body)
+	expected := hex.EncodeToString(h.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// decryptFeishuPayload 解密飞书回调内容（AES-256-CBC，密钥为 sha256(encryptKey)，前 16 字节为 IV）
+func decryptFeishuPayload(encrypted, encryptKey string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return nil, fmt.Errorf("解码加密内容失败: %w", err)
+	}
+	if len(data) < aes.BlockSize*2 || len(data)%aes.BlockSize != 0 {
+		return nil, errors.New("加密内容长度无效")
+	}
+
+	key := sha256.Sum256([]byte(encryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	iv, ciphertext := data[:aes.BlockSize], data[aes.BlockSize:]
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plaintext, ciphertext)
+
+	// 去除 PKCS7 填充
+	padding := int(plaintext[len(plaintext)-1])
+	if padding == 0 || padding > aes.BlockSize || padding > len(plaintext) {
+		return nil, errors.New("解密失败")
+	}
+	if !bytes.Equal(plaintext[len(plaintext)-padding:], bytes.Repeat([]byte{byte(padding)}, padding)) {
+		return nil, errors.New("解密失败")
+	}
+	return plaintext[:len(plaintext)-padding], nil
+}
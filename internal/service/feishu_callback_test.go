@@ -0,0 +1,109 @@
+package service
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func encryptFeishuPayload(t *testing.T, plaintext []byte, encryptKey string) string {
+	t.Helper()
+	key := sha256.Sum256([]byte(encryptKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	padding := aes.BlockSize - len(plaintext)%aes.BlockSize
+	plaintext = append(plaintext, bytes.Repeat([]byte{byte(padding)}, padding)...)
+	iv := bytes.Repeat([]byte{1}, aes.BlockSize)
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(ciphertext, plaintext)
+	return base64.StdEncoding.EncodeToString(append(iv, ciphertext...))
+}
+
+func signFeishu(timestamp, nonce, encryptKey string, body []byte) string {
+	sum := sha256.Sum256(append([]byte(timestamp+nonce+encryptKey), body...))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestVerifyFeishuCallbackSignedAndEncrypted(t *testing.T) {
+	const encryptKey = "test-encrypt-key"
+	config := map[string]interface{}{"encryptKey": encryptKey, "verificationToken": "token-1"}
+	now := time.Unix(1700000000, 0)
+
+	payload := []byte(`{"token":"token-1","action":{"tag":"button","value":{"action":"ack"}}}`)
+	body := []byte(`{"encrypt":"` + encryptFeishuPayload(t, payload, encryptKey) + `"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	header := http.Header{}
+	header.Set("X-Lark-Request-Timestamp", timestamp)
+	header.Set("X-Lark-Request-Nonce", "nonce")
+	header.Set("X-Lark-Signature", signFeishu(timestamp, "nonce", encryptKey, body))
+
+	callback, err := VerifyFeishuCallback(config, header, body, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if action := callback.CardAction(); action == nil || action.Value["action"] != "ack" {
+		t.Fatalf("unexpected card action: %+v", callback.CardAction())
+	}
+
+	// 篡改请求体后签名校验失败
+	header.Set("X-Lark-Signature", signFeishu(timestamp, "nonce", "wrong-key", body))
+	if _, err := VerifyFeishuCallback(config, header, body, now); !errors.Is(err, ErrFeishuCallbackUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+
+	// 过期的请求被拒绝
+	header.Set("X-Lark-Signature", signFeishu(timestamp, "nonce", encryptKey, body))
+	if _, err := VerifyFeishuCallback(config, header, body, now.Add(10*time.Minute)); !errors.Is(err, ErrFeishuCallbackUnauthorized) {
+		t.Fatalf("expected stale request to be rejected, got %v", err)
+	}
+}
+
+func TestVerifyFeishuCallbackRequiresSignature(t *testing.T) {
+	const encryptKey = "test-encrypt-key"
+	config := map[string]interface{}{"encryptKey": encryptKey}
+	now := time.Unix(1700000000, 0)
+
+	// 未签名的加密卡片回调无法校验时间戳，必须拒绝
+	action := []byte(`{"action":{"tag":"button","value":{"action":"ack"}}}`)
+	body := []byte(`{"encrypt":"` + encryptFeishuPayload(t, action, encryptKey) + `"}`)
+	if _, err := VerifyFeishuCallback(config, http.Header{}, body, now); !errors.Is(err, ErrFeishuCallbackUnauthorized) {
+		t.Fatalf("expected unsigned card action to be rejected, got %v", err)
+	}
+
+	// 地址校验请求不带签名，解密成功即可通过
+	challenge := []byte(`{"type":"url_verification","challenge":"c"}`)
+	body = []byte(`{"encrypt":"` + encryptFeishuPayload(t, challenge, encryptKey) + `"}`)
+	callback, err := VerifyFeishuCallback(config, http.Header{}, body, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if callback.Challenge != "c" {
+		t.Fatalf("unexpected challenge: %q", callback.Challenge)
+	}
+}
+
+func TestVerifyFeishuCallbackToken(t *testing.T) {
+	config := map[string]interface{}{"verificationToken": "token-1"}
+	now := time.Now()
+
+	if _, err := VerifyFeishuCallback(config, http.Header{}, []byte(`{"type":"url_verification","token":"token-1","challenge":"c"}`), now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := VerifyFeishuCallback(config, http.Header{}, []byte(`{"token":"spoofed"}`), now); !errors.Is(err, ErrFeishuCallbackUnauthorized) {
+		t.Fatalf("expected unauthorized, got %v", err)
+	}
+	if _, err := VerifyFeishuCallback(map[string]interface{}{}, http.Header{}, []byte(`{}`), now); !errors.Is(err, ErrFeishuCallbackUnauthorized) {
+		t.Fatalf("expected unconfigured channel to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// gotifyPriorities 告警级别对应的 Gotify 优先级，8 及以上在 Android 客户端以高优先级弹出
+var gotifyPriorities = map[string]int{
+	"info":     4,
+	"warning":  6,
+	"critical": 8,
+}
+
+// GotifyConfig Gotify 渠道配置
+type GotifyConfig struct {
+	Server string // 服务地址
+	Token  string // 应用 Token
+}
+
+// ParseGotifyConfig 解析并校验 Gotify 渠道配置
+// 配置格式: { "server": "https://gotify.example.com", "token": "xxx" }
+func ParseGotifyConfig(config map[string]interface{}) (GotifyConfig, error) {
+	var cfg GotifyConfig
+	cfg.Server, _ = config["server"].(string)
+	cfg.Server = strings.TrimRight(strings.TrimSpace(cfg.Server), "/")
+	if cfg.Server == "" {
+		return cfg, fmt.Errorf("Gotify 配置缺少 server")
+	}
+	if u, err := url.Parse(cfg.Server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return cfg, fmt.Errorf("Gotify server 无效: %s", cfg.Server)
+	}
+	cfg.Token, _ = config["token"].(string)
+	cfg.Token = strings.TrimSpace(cfg.Token)
+	if cfg.Token == "" {
+		return cfg, fmt.Errorf("Gotify 配置缺少 token")
+	}
+	return cfg, nil
+}
+
+// GotifyError Gotify 接口的错误响应
+type GotifyError struct {
+	Error            string `json:"error"`
+	ErrorCode        int    `json:"errorCode"`
+	ErrorDescription string `json:"errorDescription"`
+}
+
+// sendGotify 发送一条 Gotify 消息，成功时返回创建的消息对象，任意 2xx 均视为成功
+// Token 通过 X-Gotify-Key 请求头传递（与 ?token= 等效），避免出现在重试日志和错误信息中的地址里
+func (n *Notifier) sendGotify(ctx context.Context, cfg GotifyConfig, title, message string, priority int) error {
+	data, err := json.Marshal(map[string]interface{}{
+		"title":    title,
+		"message":  message,
+		"priority": priority,
+	})
+	if err != nil {
+		return fmt.Errorf("序列化请求体失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.Server+"/message", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", cfg.Token)
+
+	resp, err := n.doHTTP(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	respBody, _ := io.ReadAll(resp.Body)
+	var gotifyErr GotifyError
+	if err := json.Unmarshal(respBody, &gotifyErr); err == nil && gotifyErr.Error != "" {
+		return fmt.Errorf("Gotify 返回错误: %d %s: %s", resp.StatusCode, gotifyErr.Error, gotifyErr.ErrorDescription)
+	}
+	return fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+}
+
+// sendGotifyByConfig 根据配置发送 Gotify 通知，优先级按告警级别设置，恢复通知使用普通优先级
+func (n *Notifier) sendGotifyByConfig(ctx context.Context, config map[string]interface{}, record *models.AlertRecord, message string) error {
+	cfg, err := ParseGotifyConfig(config)
+	if err != nil {
+		return err
+	}
+
+	title, body, ok := strings.Cut(message, "\n")
+	if !ok {
+		title, body = "", message
+	}
+	priority, ok := gotifyPriorities[record.Level]
+	if record.Status == "resolved" || !ok {
+		priority = gotifyPriorities["info"]
+	}
+	return n.sendGotify(ctx, cfg, title, strings.TrimLeft(body, "\n"), priority)
+}
+
+// sendGotifyRaw 发送已构建好的纯文本消息
+func (n *Notifier) sendGotifyRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseGotifyConfig(config)
+	if err != nil {
+		return err
+	}
+	return n.sendGotify(ctx, cfg, "", message, gotifyPriorities["info"])
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultRequestTimeout 渠道未配置 timeoutSeconds 时单次请求的超时时间
+	defaultRequestTimeout = 10 * time.Second
+	// defaultMaxRetries 渠道未配置 maxRetries 时的最大重试次数
+	defaultMaxRetries = 3
+	// defaultRetryBackoff 首次重试的退避时间（按重试次数翻倍并附加随机抖动）
+	defaultRetryBackoff = time.Second
+	// maxRetryAfter 愿意等待的最长时间，超过则放弃重试直接返回限流响应
+	maxRetryAfter = time.Minute
+)
+
+// parseRetryAfter 解析 Retry-After 响应头，支持秒数和 HTTP 日期两种格式
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		wait := t.Sub(now)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// requestTimeoutKey 单次请求超时时间在 ctx 中的键
+type requestTimeoutKey struct{}
+
+// withRequestTimeout 设置后续通知请求的单次超时时间
+func withRequestTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	if timeout <= 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, requestTimeoutKey{}, timeout)
+}
+
+// requestTimeout 获取单次请求的超时时间，未设置时使用默认值
+func requestTimeout(ctx context.Context) time.Duration {
+	if timeout, ok := ctx.Value(requestTimeoutKey{}).(time.Duration); ok && timeout > 0 {
+		return timeout
+	}
+	return defaultRequestTimeout
+}
+
+// parseTimeoutSeconds 解析渠道配置中的 timeoutSeconds，未配置时返回 0
+func parseTimeoutSeconds(config map[string]interface{}) (time.Duration, error) {
+	raw, ok := config["timeoutSeconds"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	seconds, ok := raw.(float64)
+	if !ok || seconds <= 0 {
+		return 0, fmt.Errorf("timeoutSeconds 必须为正数")
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// maxRetriesKey 最大重试次数在 ctx 中的键
+type maxRetriesKey struct{}
+
+// requestMaxRetries 获取失败请求的最大重试次数，未设置时使用默认值
+func requestMaxRetries(ctx context.Context) int {
+	if retries, ok := ctx.Value(maxRetriesKey{}).(int); ok {
+		return retries
+	}
+	return defaultMaxRetries
+}
+
+// parseMaxRetries 解析渠道配置中的 maxRetries，未配置时返回 -1
+func parseMaxRetries(config map[string]interface{}) (int, error) {
+	raw, ok := config["maxRetries"]
+	if !ok || raw == nil {
+		return -1, nil
+	}
+	retries, ok := raw.(float64)
+	if !ok || retries < 0 || retries != float64(int(retries)) {
+		return -1, fmt.Errorf("maxRetries 必须为非负整数")
+	}
+	return int(retries), nil
+}
+
+// WithChannelHTTPOptions 按渠道配置设置后续通知请求的单次超时时间（timeoutSeconds）、最大重试次数（maxRetries）
+// 和代理地址（proxyUrl），实际超时取 timeoutSeconds 与 ctx 截止时间中较早的一个
+func WithChannelHTTPOptions(ctx context.Context, config map[string]interface{}) context.Context {
+	if timeout, err := parseTimeoutSeconds(config); err == nil {
+		ctx = withRequestTimeout(ctx, timeout)
+	}
+	if retries, err := parseMaxRetries(config); err == nil && retries >= 0 {
+		ctx = context.WithValue(ctx, maxRetriesKey{}, retries)
+	}
+	if proxy, err := parseProxyURL(config); err == nil {
+		ctx = withProxyURL(ctx, proxy)
+	}
+	return ctx
+}
+
+// retryableStatus 是否为可重试的响应状态码：429 和 5xx，其他 4xx 表示配置错误不重试
+func retryableStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= 500
+}
+
+// retryBackoff 第 attempt 次重试前的等待时间：指数退避并附加最多一半的随机抖动
+func retryBackoff(attempt int) time.Duration {
+	backoff := defaultRetryBackoff << attempt
+	return backoff + rand.N(backoff/2+1)
+}
+
+// cancelOnCloseBody 响应体关闭时释放单次请求的 ctx
+type cancelOnCloseBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnCloseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// doAttempt 按单次请求超时发送一次请求
+func (n *Notifier) doAttempt(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), requestTimeout(req.Context()))
+	resp, err := n.client.Do(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnCloseBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// doHTTP 发送请求，网络错误和 429/5xx 响应按指数退避重试，被限流时优先按 Retry-After 等待
+// 其他 4xx 表示配置错误，直接返回不重试；等待期间遵循 ctx 的取消；演练模式下只记录请求不发送
+func (n *Notifier) doHTTP(req *http.Request) (*http.Response, error) {
+	if recorder := dryRunFrom(req.Context()); recorder != nil {
+		if err := recorder.recordHTTP(req); err != nil {
+			return nil, err
+		}
+		return nil, errDryRun
+	}
+
+	// 请求体需要在重试时重新读取
+	if req.Body != nil && req.GetBody == nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(data))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(data)), nil
+		}
+	}
+
+	maxRetries := requestMaxRetries(req.Context())
+	for attempt := 0; ; attempt++ {
+		resp, err := n.doAttempt(req)
+
+		var wait time.Duration
+		if err != nil {
+			// 调用方取消或超时不再重试
+			if req.Context().Err() != nil || attempt >= maxRetries {
+				return nil, err
+			}
+			wait = retryBackoff(attempt)
+			n.logger.Warn("通知请求失败，等待后重试",
+				zap.String("url", redactRequestURL(req.URL)),
+				zap.Duration("wait", wait),
+				zap.Int("attempt", attempt+1),
+				zap.String("error", deliveryErrorMessage(err)),
+			)
+		} else {
+			if !retryableStatus(resp.StatusCode) || attempt >= maxRetries {
+				return resp, nil
+			}
+			var ok bool
+			wait, ok = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			if !ok {
+				wait = retryBackoff(attempt)
+			}
+			if wait > maxRetryAfter {
+				return resp, nil
+			}
+
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+
+			n.logger.Warn("通知请求返回可重试的状态码，等待后重试",
+				zap.String("url", redactRequestURL(req.URL)),
+				zap.Int("statusCode", resp.StatusCode),
+				zap.Duration("wait", wait),
+				zap.Int("attempt", attempt+1),
+			)
+		}
+
+		if err := sleepContext(req.Context(), wait); err != nil {
+			return nil, err
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
+}
+
+// sleepContext 等待指定时间，ctx 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
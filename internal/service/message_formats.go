@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// levelCardColors 飞书卡片标题栏颜色
+var levelCardColors = map[string]string{
+	"info":     "blue",
+	"warning":  "orange",
+	"critical": "red",
+}
+
+// markdownEscaper 转义 Markdown 标记字符和 HTML 标签，换行替换为空格以免破坏列表结构
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"*", "\\*",
+	"_", "\\_",
+	"`", "\\`",
+	"[", "\\[",
+	"]", "\\]",
+	"~", "\\~",
+	"|", "\\|",
+	"&", "&amp;",
+	"<", "&lt;",
+	">", "&gt;",
+	"\r\n", " ",
+	"\n", " ",
+	"\r", " ",
+)
+
+// escapeMarkdown 转义字段值，防止主机名、告警消息等来自探针的内容破坏 Markdown 渲染或注入标记
+func escapeMarkdown(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// renderMarkdown 将告警消息渲染为 Markdown，字段值均做转义
+func renderMarkdown(content messageContent) string {
+	var b strings.Builder
+	b.WriteString("### " + content.Title + "\n")
+	for _, line := range content.Lines {
+		b.WriteString("\n- **" + line.Label + "**: " + escapeMarkdown(line.Value))
+	}
+	return b.String()
+}
+
+// renderCard 按平台将告警消息渲染为卡片消息体
+func renderCard(platform string, content messageContent, record *models.AlertRecord) (map[string]interface{}, error) {
+	markdown := renderMarkdown(content)
+	switch platform {
+	case "dingtalk":
+		return map[string]interface{}{
+			"msgtype": "actionCard",
+			"actionCard": map[string]interface{}{
+				"title": content.Title,
+				"text":  markdown,
+			},
+		}, nil
+	case "wecom":
+		// 企业微信群机器人的 Markdown 消息即为其富文本卡片形式
+		return map[string]interface{}{
+			"msgtype": "markdown",
+			"markdown": map[string]string{
+				"content": markdown,
+			},
+		}, nil
+	case "feishu":
+		return renderFeishuCard(content, record, "", messageOptions{}), nil
+	default:
+		return nil, fmt.Errorf("平台 %s 不支持卡片消息", platform)
+	}
+}
+
+// renderFeishuCard 将告警消息渲染为飞书消息卡片：标题栏颜色对应告警级别（恢复为绿色），
+// 字段两列排列，detailURL 非空时附“查看详情”按钮
+func renderFeishuCard(content messageContent, record *models.AlertRecord, detailURL string, opts messageOptions) map[string]interface{} {
+	template := levelCardColors[record.Level]
+	if record.Status == "resolved" {
+		template = "green"
+	}
+	if template == "" {
+		template = "blue"
+	}
+
+	fields := make([]interface{}, 0, len(content.Lines))
+	for _, line := range content.Lines {
+		fields = append(fields, map[string]interface{}{
+			"is_short": true,
+			"text": map[string]string{
+				"tag":     "lark_md",
+				"content": "**" + line.Label + "**\n" + escapeMarkdown(line.Value),
+			},
+		})
+	}
+	elements := []interface{}{
+		map[string]interface{}{"tag": "div", "fields": fields},
+	}
+	if detailURL != "" {
+		elements = append(elements, map[string]interface{}{
+			"tag": "action",
+			"actions": []interface{}{
+				map[string]interface{}{
+					"tag":  "button",
+					"text": map[string]string{"tag": "plain_text", "content": opts.text("link.detail")},
+					"type": "primary",
+					"url":  detailURL,
+				},
+			},
+		})
+	}
+
+	return map[string]interface{}{
+		"msg_type": "interactive",
+		"card": map[string]interface{}{
+			"header": map[string]interface{}{
+				"title": map[string]string{
+					"tag":     "plain_text",
+					"content": content.Title,
+				},
+				"template": template,
+			},
+			"elements": elements,
+		},
+	}
+}
+
+// renderFeishuPost 将告警消息渲染为飞书富文本（post）消息体，字段名加粗，末尾附详情链接
+func renderFeishuPost(content messageContent, detailURL string, opts messageOptions) map[string]interface{} {
+	paragraphs := make([]interface{}, 0, len(content.Lines)+1)
+	for _, line := range content.Lines {
+		paragraphs = append(paragraphs, []interface{}{
+			map[string]interface{}{"tag": "text", "text": line.Label + ": ", "style": []string{"bold"}},
+			map[string]interface{}{"tag": "text", "text": line.Value},
+		})
+	}
+	if detailURL != "" {
+		paragraphs = append(paragraphs, []interface{}{
+			map[string]interface{}{"tag": "a", "text": opts.text("link.detail"), "href": detailURL},
+		})
+	}
+
+	language := "zh_cn"
+	if opts.locale() == "en" {
+		language = "en_us"
+	}
+	return map[string]interface{}{
+		"msg_type": "post",
+		"content": map[string]interface{}{
+			"post": map[string]interface{}{
+				language: map[string]interface{}{
+					"title":   content.Title,
+					"content": paragraphs,
+				},
+			},
+		},
+	}
+}
+
+// MessagePreview 告警消息在各格式下的渲染结果
+type MessagePreview struct {
+	Platform string                 `json:"platform"`       // 平台
+	Text     string                 `json:"text"`           // 纯文本
+	Markdown string                 `json:"markdown"`       // Markdown
+	Card     map[string]interface{} `json:"card,omitempty"` // 卡片消息体（平台不支持时为空）
+}
+
+// PreviewMessage 渲染示例告警在各格式下的消息内容，便于选择渠道的消息格式
+func (n *Notifier) PreviewMessage(ctx context.Context, platform string, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) (*MessagePreview, error) {
+	opts := n.messageOptions(ctx, config)
+	content, ok := n.buildMessageContent(agent, record, opts)
+	if !ok {
+		return nil, fmt.Errorf("不支持的告警状态: %s", record.Status)
+	}
+
+	preview := &MessagePreview{
+		Platform: platform,
+		Text:     n.buildMessage(agent, record, opts),
+		Markdown: renderMarkdown(content),
+	}
+	if card, err := renderCard(platform, content, record); err == nil {
+		preview.Card = card
+	}
+	return preview, nil
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultMessageLocale 渠道和系统配置均未指定（或不支持）时使用的语言
+const defaultMessageLocale = "zh"
+
+// messageCatalogs 告警消息的多语言文案
+var messageCatalogs = map[string]map[string]string{
+	"zh": {
+		"label.probe":          "探针",
+		"label.host":           "主机",
+		"label.ip":             "IP",
+		"label.type":           "告警类型",
+		"label.message":        "告警消息",
+		"label.threshold":      "阈值",
+		"label.value":          "当前值",
+		"label.trend":          "近期趋势",
+		"label.resolvedDetail": "恢复详情",
+		"label.firedAt":        "触发时间",
+		"label.resolvedAt":     "恢复时间",
+		"label.labels":         "标签",
+		"title.resolved":       "%s %s已恢复",
+		"title.replay":         "【重放】",
+		"detail.below":         "恢复至 %s，低于阈值 %s",
+		"detail.above":         "恢复至 %s，高于阈值 %s",
+		"unit.days":            "%.0f天",
+		"unit.seconds":         "%.0f秒",
+		"link.detail":          "查看详情",
+		"table.field":          "字段",
+		"table.value":          "内容",
+		"note.suppressed":      "（此前已抑制 %d 条告警）",
+		"title.grouped":        "%s 同时产生 %d 条告警",
+		"status.firing":        "告警中",
+		"status.resolved":      "已恢复",
+	},
+	"en": {
+		"label.probe":                   "Agent",
+		"label.host":                    "Host",
+		"label.ip":                      "IP",
+		"label.type":                    "Alert Type",
+		"label.message":                 "Message",
+		"label.threshold":               "Threshold",
+		"label.value":                   "Current Value",
+		"label.trend":                   "Recent Trend",
+		"label.resolvedDetail":          "Recovery",
+		"label.firedAt":                 "Fired At",
+		"label.resolvedAt":              "Resolved At",
+		"label.labels":                  "Labels",
+		"title.resolved":                "%s %s resolved",
+		"title.replay":                  "[Replay] ",
+		"detail.below":                  "recovered to %s, below threshold %s",
+		"detail.above":                  "recovered to %s, above threshold %s",
+		"unit.days":                     "%.0f days",
+		"unit.seconds":                  "%.0fs",
+		"link.detail":                   "View Details",
+		"table.field":                   "Field",
+		"table.value":                   "Value",
+		"note.suppressed":               "(%d alerts suppressed)",
+		"title.grouped":                 "%[2]d alerts on %[1]s",
+		"status.firing":                 "Firing",
+		"status.resolved":               "Resolved",
+		"alertType.cpu":                 "CPU Alert",
+		"alertType.memory":              "Memory Alert",
+		"alertType.disk":                "Disk Alert",
+		"alertType.network":             "Network Alert",
+		"alertType.cert":                "Certificate Alert",
+		"alertType.service":             "Service Alert",
+		"alertType.version":             "Outdated Version Alert",
+		"alertType.agent_offline":       "Agent Offline Alert",
+		"alertType.notification_failed": "Notification Delivery Failed",
+	},
+}
+
+// resolveMessageLocale 返回第一个受支持的语言，均不支持时使用 zh
+// 支持 en-US、zh_CN 等带地区的写法，按主语言匹配
+func resolveMessageLocale(candidates ...string) string {
+	for _, locale := range candidates {
+		locale = strings.ToLower(strings.TrimSpace(locale))
+		if i := strings.IndexAny(locale, "-_"); i > 0 {
+			locale = locale[:i]
+		}
+		if _, ok := messageCatalogs[locale]; ok {
+			return locale
+		}
+	}
+	return defaultMessageLocale
+}
+
+// text 按渠道语言获取文案，缺失时回退到 zh
+func (o messageOptions) text(key string) string {
+	if v, ok := messageCatalogs[o.locale()][key]; ok {
+		return v
+	}
+	return messageCatalogs[defaultMessageLocale][key]
+}
+
+// textf 按渠道语言格式化文案
+func (o messageOptions) textf(key string, args ...interface{}) string {
+	return fmt.Sprintf(o.text(key), args...)
+}
+
+// locale 渠道的消息语言
+func (o messageOptions) locale() string {
+	if o.Locale == "" {
+		return defaultMessageLocale
+	}
+	return o.Locale
+}
+
+// alertTypeName 按渠道语言获取告警类型名称
+func (o messageOptions) alertTypeName(alertType string) string {
+	if name, ok := messageCatalogs[o.locale()]["alertType."+alertType]; ok {
+		return name
+	}
+	return alertTypeDisplayName(alertType)
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// messageTemplateKeys 渠道配置中的消息模板键，template 同时用于告警和恢复，
+// firingTemplate/resolvedTemplate 优先于 template
+var messageTemplateKeys = []string{"template", "firingTemplate", "resolvedTemplate"}
+
+// messageTemplateData 自定义消息模板（template/firingTemplate/resolvedTemplate）可用的变量
+type messageTemplateData struct {
+	Agent      *models.Agent       // 探针，如 {{.Agent.Name}}
+	Alert      *models.AlertRecord // 告警记录，如 {{.Alert.Message}}
+	Title      string              // 内置格式的标题（含级别图标）
+	TypeName   string              // 告警类型名称，如 CPU告警
+	Value      string              // 带单位的当前值
+	Threshold  string              // 带单位的阈值
+	FiredAt    string              // 触发时间
+	ResolvedAt string              // 恢复时间
+}
+
+// messageTemplateFuncs 模板可用的辅助函数
+// formatTime 将毫秒时间戳格式化为 2006-01-02 15:04:05，如 {{formatTime .Alert.FiredAt}}；
+// levelIcon 返回告警级别图标，如 {{levelIcon .Alert.Level}}
+func messageTemplateFuncs(opts messageOptions) template.FuncMap {
+	return template.FuncMap{
+		"formatTime": formatTemplateTime,
+		"levelIcon":  opts.levelIcon,
+	}
+}
+
+// formatTemplateTime 格式化毫秒时间戳，0 返回空字符串
+func formatTemplateTime(ms int64) string {
+	if ms <= 0 {
+		return ""
+	}
+	return time.UnixMilli(ms).Format("2006-01-02 15:04:05")
+}
+
+// parseMessageTemplate 解析消息模板，模板为空时返回 nil
+func parseMessageTemplate(name, text string, opts messageOptions) (*template.Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+	t, err := template.New(name).Option("missingkey=zero").Funcs(messageTemplateFuncs(opts)).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s 解析失败: %w", name, err)
+	}
+	return t, nil
+}
+
+// validateMessageTemplates 校验渠道配置中的消息模板，保存配置时即可发现错误
+func validateMessageTemplates(config map[string]interface{}) []string {
+	var errs []string
+	for _, key := range messageTemplateKeys {
+		text, _ := config[key].(string)
+		if _, err := parseMessageTemplate(key, text, messageOptions{}); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	return errs
+}
+
+// messageTemplate 按告警状态选择模板，返回模板键和内容
+func (o messageOptions) messageTemplate(status string) (string, string) {
+	if status == "resolved" && o.ResolvedTemplate != "" {
+		return "resolvedTemplate", o.ResolvedTemplate
+	}
+	if status == "firing" && o.FiringTemplate != "" {
+		return "firingTemplate", o.FiringTemplate
+	}
+	return "template", o.Template
+}
+
+// hasMessageTemplate 该状态的消息是否使用自定义模板
+func (o messageOptions) hasMessageTemplate(status string) bool {
+	_, text := o.messageTemplate(status)
+	return strings.TrimSpace(text) != ""
+}
+
+// executeMessageTemplate 执行模板，模板中的辅助函数 panic 时转为错误，避免影响通知发送
+func executeMessageTemplate(t *template.Template, data messageTemplateData) (message string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("模板执行异常: %v", r)
+		}
+	}()
+	var b strings.Builder
+	if err := t.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// renderMessageTemplate 按告警状态使用渠道配置的模板渲染消息，未配置对应模板时返回 false
+func (n *Notifier) renderMessageTemplate(agent *models.Agent, record *models.AlertRecord, opts messageOptions, content messageContent) (string, bool) {
+	key, text := opts.messageTemplate(record.Status)
+	t, err := parseMessageTemplate(key, text, opts)
+	if err != nil {
+		n.logger.Sugar().Warnf("自定义消息模板无效，使用内置格式: %v", err)
+		return "", false
+	}
+	if t == nil {
+		return "", false
+	}
+
+	data := messageTemplateData{
+		Agent:     agent,
+		Alert:     record,
+		Title:     content.Title,
+		TypeName:  opts.alertTypeName(record.AlertType),
+		Value:     formatAlertValue(record.AlertType, record.ActualValue, opts),
+		Threshold: formatAlertValue(record.AlertType, record.Threshold, opts),
+		FiredAt:   time.Unix(record.FiredAt/1000, 0).Format("2006-01-02 15:04:05"),
+	}
+	if record.ResolvedAt > 0 {
+		data.ResolvedAt = time.Unix(record.ResolvedAt/1000, 0).Format("2006-01-02 15:04:05")
+	}
+
+	message, err := executeMessageTemplate(t, data)
+	if err != nil {
+		n.logger.Sugar().Warnf("渲染自定义消息模板失败，使用内置格式: %v", err)
+		return "", false
+	}
+	return message, true
+}
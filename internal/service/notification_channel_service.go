@@ -0,0 +1,404 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// NotificationChannelService 通知渠道服务
+type NotificationChannelService struct {
+	logger *zap.Logger
+	*orz.Service
+	NotificationChannelRepo *repo.NotificationChannelRepo
+	propertyService         *PropertyService
+}
+
+func NewNotificationChannelService(logger *zap.Logger, db *gorm.DB, propertyService *PropertyService) *NotificationChannelService {
+	return &NotificationChannelService{
+		logger:                  logger,
+		Service:                 orz.NewService(db),
+		NotificationChannelRepo: repo.NewNotificationChannelRepo(db),
+		propertyService:         propertyService,
+	}
+}
+
+// List 获取所有通知渠道
+func (s *NotificationChannelService) List(ctx context.Context) ([]models.NotificationChannel, error) {
+	channels, err := s.NotificationChannelRepo.FindAllOrdered(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range channels {
+		if err := s.decrypt(&channels[i]); err != nil {
+			return nil, err
+		}
+	}
+	return channels, nil
+}
+
+// Get 获取通知渠道
+func (s *NotificationChannelService) Get(ctx context.Context, id string) (*models.NotificationChannel, error) {
+	channel, err := s.NotificationChannelRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decrypt(&channel); err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// decrypt 解密从数据库读取的渠道配置中的敏感字段
+func (s *NotificationChannelService) decrypt(channel *models.NotificationChannel) error {
+	config, err := s.propertyService.DecryptChannelSecrets(channel.NotificationChannelConfig)
+	if err != nil {
+		return fmt.Errorf("通知渠道 %s 的配置解密失败: %w", channel.DisplayName(), err)
+	}
+	channel.NotificationChannelConfig = config
+	return nil
+}
+
+// encrypted 返回敏感字段已加密、用于写入数据库的渠道副本
+func (s *NotificationChannelService) encrypted(channel *models.NotificationChannel) (*models.NotificationChannel, error) {
+	config, err := s.propertyService.EncryptChannelSecrets(channel.NotificationChannelConfig)
+	if err != nil {
+		return nil, fmt.Errorf("通知渠道配置加密失败: %w", err)
+	}
+	stored := *channel
+	stored.NotificationChannelConfig = config
+	return &stored, nil
+}
+
+// create 加密敏感字段后创建渠道
+func (s *NotificationChannelService) create(ctx context.Context, channel *models.NotificationChannel) error {
+	stored, err := s.encrypted(channel)
+	if err != nil {
+		return err
+	}
+	return s.NotificationChannelRepo.Create(ctx, stored)
+}
+
+// GetChannelConfigs 获取所有通知渠道配置（供通知发送使用）
+func (s *NotificationChannelService) GetChannelConfigs(ctx context.Context) ([]models.NotificationChannelConfig, error) {
+	channels, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	configs := make([]models.NotificationChannelConfig, 0, len(channels))
+	for _, channel := range channels {
+		configs = append(configs, channel.NotificationChannelConfig)
+	}
+	return configs, nil
+}
+
+// FindByIDOrType 按 ID 查找通知渠道，找不到时按类型查找（兼容旧的按类型测试接口）
+// 同一类型存在多个渠道时无法确定测试哪一个，要求指定渠道ID
+func (s *NotificationChannelService) FindByIDOrType(ctx context.Context, key string) (*models.NotificationChannel, error) {
+	channels, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range channels {
+		if channels[i].ID == key {
+			return &channels[i], nil
+		}
+	}
+	var matched *models.NotificationChannel
+	for i := range channels {
+		if channels[i].Type != key {
+			continue
+		}
+		if matched != nil {
+			return nil, orz.NewError(400, "存在多个该类型的通知渠道，请指定渠道ID")
+		}
+		matched = &channels[i]
+	}
+	if matched == nil {
+		return nil, orz.NewError(404, "通知渠道不存在，请先配置")
+	}
+	return matched, nil
+}
+
+// Create 创建通知渠道
+func (s *NotificationChannelService) Create(ctx context.Context, req models.NotificationChannelConfig) (*models.NotificationChannel, error) {
+	if errs := ValidateChannelConfig(req); len(errs) > 0 {
+		return nil, orz.NewError(400, strings.Join(errs, "；"))
+	}
+
+	now := time.Now().UnixMilli()
+	channel := &models.NotificationChannel{
+		NotificationChannelConfig: req,
+		CreatedAt:                 now,
+		UpdatedAt:                 now,
+	}
+	channel.ID = uuid.NewString()
+	channel.Name = strings.TrimSpace(req.Name)
+	if channel.Name == "" {
+		channel.Name = req.Type
+	}
+
+	if err := s.create(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// Update 更新通知渠道
+func (s *NotificationChannelService) Update(ctx context.Context, id string, req models.NotificationChannelConfig) (*models.NotificationChannel, error) {
+	channel, err := s.NotificationChannelRepo.FindById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.decrypt(&channel); err != nil {
+		return nil, err
+	}
+	// 仍为占位值的敏感字段保留已保存的值
+	req.ID = id
+	req = restoreRedactedSecrets([]models.NotificationChannelConfig{req}, []models.NotificationChannelConfig{channel.NotificationChannelConfig})[0]
+	if errs := ValidateChannelConfig(req); len(errs) > 0 {
+		return nil, orz.NewError(400, strings.Join(errs, "；"))
+	}
+
+	createdAt := channel.CreatedAt
+	channel.NotificationChannelConfig = req
+	channel.ID = id
+	channel.Name = strings.TrimSpace(req.Name)
+	if channel.Name == "" {
+		channel.Name = req.Type
+	}
+	channel.CreatedAt = createdAt
+
+	stored, err := s.encrypted(&channel)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.NotificationChannelRepo.Save(ctx, stored); err != nil {
+		return nil, err
+	}
+	return &channel, nil
+}
+
+// Clone 复制通知渠道：深拷贝配置，生成新ID，名称追加“副本”，默认禁用
+func (s *NotificationChannelService) Clone(ctx context.Context, id string) (*models.NotificationChannel, error) {
+	source, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := cloneChannelConfig(source.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	channel := &models.NotificationChannel{
+		NotificationChannelConfig: source.NotificationChannelConfig,
+		CreatedAt:                 now,
+		UpdatedAt:                 now,
+	}
+	channel.ID = uuid.NewString()
+	channel.Name = source.Name + " 副本"
+	channel.Enabled = false
+	channel.Config = config
+
+	if err := s.create(ctx, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
+
+// cloneChannelConfig 深拷贝渠道配置，避免副本与原渠道共享嵌套的 map/slice
+func cloneChannelConfig(config map[string]interface{}) (map[string]interface{}, error) {
+	if config == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var cloned map[string]interface{}
+	if err := json.Unmarshal(data, &cloned); err != nil {
+		return nil, err
+	}
+	return cloned, nil
+}
+
+// SetEnabled 启用/禁用通知渠道
+func (s *NotificationChannelService) SetEnabled(ctx context.Context, id string, enabled bool) error {
+	if _, err := s.NotificationChannelRepo.FindById(ctx, id); err != nil {
+		return err
+	}
+	return s.NotificationChannelRepo.UpdateEnabled(ctx, id, enabled)
+}
+
+// Delete 删除通知渠道
+func (s *NotificationChannelService) Delete(ctx context.Context, id string) error {
+	return s.NotificationChannelRepo.DeleteById(ctx, id)
+}
+
+// prepareImport 校验待导入的通知渠道，仍为占位值的敏感字段按渠道ID还原为已保存的值
+// 没有ID的渠道生成新ID，作为新渠道导入
+func (s *NotificationChannelService) prepareImport(ctx context.Context, channels []models.NotificationChannelConfig) ([]models.NotificationChannelConfig, []string, error) {
+	existing, err := s.GetChannelConfigs(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var errs []string
+	prepared := make([]models.NotificationChannelConfig, len(channels))
+	seen := make(map[string]bool, len(channels))
+	for i, channel := range channels {
+		if channel.ID == "" {
+			channel.ID = uuid.NewString()
+		}
+		if seen[channel.ID] {
+			errs = append(errs, fmt.Sprintf("通知渠道 %s 重复", channel.ID))
+		}
+		seen[channel.ID] = true
+		prepared[i] = channel
+	}
+	prepared = restoreRedactedSecrets(prepared, existing)
+	for _, channel := range prepared {
+		for _, e := range ValidateChannelConfig(channel) {
+			errs = append(errs, "通知渠道 "+channel.DisplayName()+": "+e)
+		}
+	}
+	return prepared, errs, nil
+}
+
+// importChannels 按ID更新已存在的渠道并创建新渠道，replace 为 true 时删除不在列表中的渠道
+// 返回删除的渠道数量
+func (s *NotificationChannelService) importChannels(ctx context.Context, channels []models.NotificationChannelConfig, replace bool) (int, error) {
+	removed := 0
+	err := s.Transaction(ctx, func(ctx context.Context) error {
+		var err error
+		removed, err = s.importChannelsTx(ctx, channels, replace)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
+// importChannelsTx 在事务中导入通知渠道
+func (s *NotificationChannelService) importChannelsTx(ctx context.Context, channels []models.NotificationChannelConfig, replace bool) (int, error) {
+	existing, err := s.NotificationChannelRepo.FindAllOrdered(ctx)
+	if err != nil {
+		return 0, err
+	}
+	createdAt := make(map[string]int64, len(existing))
+	for _, channel := range existing {
+		createdAt[channel.ID] = channel.CreatedAt
+	}
+
+	now := time.Now().UnixMilli()
+	imported := make(map[string]bool, len(channels))
+	for i, config := range channels {
+		imported[config.ID] = true
+		channel := &models.NotificationChannel{
+			NotificationChannelConfig: config,
+			// 保持导入文件中的顺序
+			CreatedAt: now + int64(i),
+			UpdatedAt: now,
+		}
+		if strings.TrimSpace(channel.Name) == "" {
+			channel.Name = config.Type
+		}
+		if created, ok := createdAt[config.ID]; ok {
+			channel.CreatedAt = created
+			stored, err := s.encrypted(channel)
+			if err != nil {
+				return 0, err
+			}
+			if err := s.NotificationChannelRepo.Save(ctx, stored); err != nil {
+				return 0, fmt.Errorf("导入通知渠道 %s 失败: %w", channel.DisplayName(), err)
+			}
+			continue
+		}
+		if err := s.create(ctx, channel); err != nil {
+			return 0, fmt.Errorf("导入通知渠道 %s 失败: %w", channel.DisplayName(), err)
+		}
+	}
+
+	removed := 0
+	if replace {
+		for _, channel := range existing {
+			if imported[channel.ID] {
+				continue
+			}
+			if err := s.NotificationChannelRepo.DeleteById(ctx, channel.ID); err != nil {
+				return removed, fmt.Errorf("删除通知渠道 %s 失败: %w", channel.DisplayName(), err)
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// MigrateFromProperty 将旧版存储在 Property 中的通知渠道迁移到独立的表
+// 迁移在事务中执行，完成后记录迁移标记，之后即使渠道被全部删除也不会再次迁移；
+// 旧的 Property 数据保留，在废弃期内仍可读取
+func (s *NotificationChannelService) MigrateFromProperty(ctx context.Context) error {
+	var migrated bool
+	if err := s.propertyService.GetValue(ctx, PropertyIDNotificationChannelsMigrated, &migrated); err == nil && migrated {
+		return nil
+	}
+
+	count, err := s.NotificationChannelRepo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	// 升级前已完成迁移（尚无标记）
+	if count > 0 {
+		return s.markMigrated(ctx)
+	}
+
+	legacyChannels, err := s.propertyService.GetNotificationChannelConfigs(ctx)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	err = s.Transaction(ctx, func(ctx context.Context) error {
+		for i, legacy := range legacyChannels {
+			now := time.Now().UnixMilli()
+			channel := &models.NotificationChannel{
+				NotificationChannelConfig: legacy,
+				// 保持旧配置中的顺序
+				CreatedAt: now + int64(i),
+				UpdatedAt: now,
+			}
+			channel.ID = uuid.NewString()
+			if channel.Name == "" {
+				channel.Name = legacy.Type
+			}
+			if err := s.create(ctx, channel); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(legacyChannels) > 0 {
+		s.logger.Info("通知渠道配置已迁移到独立表", zap.Int("count", len(legacyChannels)))
+	}
+	return s.markMigrated(ctx)
+}
+
+// markMigrated 记录通知渠道已迁移
+func (s *NotificationChannelService) markMigrated(ctx context.Context) error {
+	return s.propertyService.Set(ctx, PropertyIDNotificationChannelsMigrated, "通知渠道迁移标记", true)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// notificationDedupMaxEntries 去重缓存的最大条目数，超出时淘汰最早的记录
+const notificationDedupMaxEntries = 10000
+
+// notificationDedup 在时间窗口内抑制相同的告警通知（同一告警的同一次触发且状态相同）
+// 用于避免上游重试导致同一告警在短时间内重复发送，仅保存在内存中
+type notificationDedup struct {
+	mu      sync.Mutex
+	window  time.Duration
+	max     int
+	entries map[string]time.Time // 键 -> 上次发送时间
+}
+
+func newNotificationDedup(window time.Duration) *notificationDedup {
+	return &notificationDedup{
+		window:  window,
+		max:     notificationDedupMaxEntries,
+		entries: make(map[string]time.Time),
+	}
+}
+
+// notificationDedupKey 告警关联键加上状态和触发时间：同一次触发的重复通知视为重复，
+// 窗口内恢复后再次触发属于新的告警，不去重
+func notificationDedupKey(record *models.AlertRecord) string {
+	return record.DedupKey() + "|" + record.Status + "|" + strconv.FormatInt(record.FiredAt, 10)
+}
+
+// Duplicate 判断该通知是否与窗口内已发送的通知重复，不重复时记录本次发送
+// 窗口为 0 时不去重
+func (d *notificationDedup) Duplicate(record *models.AlertRecord, now time.Time) bool {
+	if d == nil || d.window <= 0 {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := notificationDedupKey(record)
+	if sentAt, ok := d.entries[key]; ok && now.Sub(sentAt) < d.window {
+		return true
+	}
+	if _, ok := d.entries[key]; !ok && len(d.entries) >= d.max {
+		d.evict(now)
+	}
+	d.entries[key] = now
+	return false
+}
+
+// Release 撤销 Duplicate 记录的本次发送，用于所有渠道均发送失败时，使上游重试的相同告警仍能发送
+func (d *notificationDedup) Release(record *models.AlertRecord) {
+	if d == nil || d.window <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.entries, notificationDedupKey(record))
+}
+
+// evict 清理过期条目，仍然已满时淘汰最早的一条
+func (d *notificationDedup) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, sentAt := range d.entries {
+		if now.Sub(sentAt) >= d.window {
+			delete(d.entries, key)
+			continue
+		}
+		if oldestKey == "" || sentAt.Before(oldest) {
+			oldestKey, oldest = key, sentAt
+		}
+	}
+	if len(d.entries) >= d.max && oldestKey != "" {
+		delete(d.entries, oldestKey)
+	}
+}
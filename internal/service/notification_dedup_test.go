@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/config"
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestSendNotificationByConfigsDedup(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), &config.AppConfig{
+		Notification: &config.NotificationConfig{DedupWindowSeconds: 60},
+	}, nil)
+	channels := []models.NotificationChannelConfig{{
+		Type:    "webhook",
+		Enabled: true,
+		Config:  map[string]interface{}{"url": server.URL},
+	}}
+	agent := &models.Agent{ID: "agent-1", Name: "web-1"}
+	firedAt := time.Now().UnixMilli()
+	newRecord := func() *models.AlertRecord {
+		return &models.AlertRecord{
+			AgentID:   agent.ID,
+			AlertType: "cpu",
+			Status:    "firing",
+			Level:     "warning",
+			FiredAt:   firedAt,
+		}
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := n.SendNotificationByConfigs(context.Background(), channels, newRecord(), agent); err != nil {
+			t.Fatalf("SendNotificationByConfigs() error = %v", err)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("去重窗口内应只发送 1 次请求，实际 %d 次", got)
+	}
+
+	// 状态不同不视为重复
+	resolved := newRecord()
+	resolved.Status = "resolved"
+	if _, err := n.SendNotificationByConfigs(context.Background(), channels, resolved, agent); err != nil {
+		t.Fatalf("SendNotificationByConfigs() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("恢复通知应正常发送，实际请求 %d 次", got)
+	}
+
+	// 窗口内恢复后再次触发属于新的告警
+	refired := newRecord()
+	refired.FiredAt = firedAt + 1000
+	if _, err := n.SendNotificationByConfigs(context.Background(), channels, refired, agent); err != nil {
+		t.Fatalf("SendNotificationByConfigs() error = %v", err)
+	}
+	if got := requests.Load(); got != 3 {
+		t.Fatalf("再次触发的告警应正常发送，实际请求 %d 次", got)
+	}
+}
+
+func TestNotificationDedupSeparatesMonitors(t *testing.T) {
+	d := newNotificationDedup(time.Minute)
+	now := time.Now()
+	newRecord := func(monitorID string) *models.AlertRecord {
+		return &models.AlertRecord{AgentID: "agent-1", AlertType: "cert", MonitorID: monitorID, Status: "firing", FiredAt: now.UnixMilli()}
+	}
+
+	if d.Duplicate(newRecord("monitor-a"), now) {
+		t.Fatal("首次发送不应视为重复")
+	}
+	if d.Duplicate(newRecord("monitor-b"), now) {
+		t.Fatal("同一探针上不同监控项的告警不应视为重复")
+	}
+	if !d.Duplicate(newRecord("monitor-a"), now) {
+		t.Fatal("同一监控项的同一次触发应视为重复")
+	}
+}
+
+func TestSendNotificationByConfigsDedupReleasedOnFailure(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 第一次发送失败，之后恢复
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), &config.AppConfig{
+		Notification: &config.NotificationConfig{DedupWindowSeconds: 60},
+	}, nil)
+	channels := []models.NotificationChannelConfig{{
+		Type:    "webhook",
+		Enabled: true,
+		Config:  map[string]interface{}{"url": server.URL, "maxRetries": float64(0)},
+	}}
+	agent := &models.Agent{ID: "agent-1", Name: "web-1"}
+	record := &models.AlertRecord{AgentID: agent.ID, AlertType: "cpu", Status: "firing", Level: "warning"}
+
+	if _, err := n.SendNotificationByConfigs(context.Background(), channels, record, agent); err == nil {
+		t.Fatal("SendNotificationByConfigs() error = nil, want error")
+	}
+	// 所有渠道均失败时不记入去重窗口，上游重试的相同告警仍应发送
+	if _, err := n.SendNotificationByConfigs(context.Background(), channels, record, agent); err != nil {
+		t.Fatalf("SendNotificationByConfigs() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("失败后的相同告警应重新发送，实际请求 %d 次", got)
+	}
+	if _, err := n.SendNotificationByConfigs(context.Background(), channels, record, agent); err != nil {
+		t.Fatalf("SendNotificationByConfigs() error = %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("发送成功后去重窗口内应不再发送，实际请求 %d 次", got)
+	}
+}
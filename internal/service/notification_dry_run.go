@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// errDryRun 演练模式下请求已记录但未发送，发送流程据此提前结束
+var errDryRun = errors.New("演练模式，请求未发送")
+
+// dryRunAccessToken 演练模式下代替企业微信、飞书应用 access_token 的占位值，避免为预览请求真实令牌
+const dryRunAccessToken = "DRY_RUN_ACCESS_TOKEN"
+
+// sensitiveHeaders 演练结果中需要隐藏取值的请求头
+var sensitiveHeaders = map[string]bool{
+	"Authorization": true,
+	"X-Gotify-Key":  true,
+}
+
+// sensitiveQueryParams 演练结果和日志中需要隐藏取值的查询参数（钉钉 access_token、企业微信群机器人 key、应用 corpsecret 等）
+var sensitiveQueryParams = []string{"access_token", "key", "token", "sign", "corpsecret"}
+
+// redactRequestURL 隐藏请求地址中的认证信息：userinfo、敏感查询参数，
+// 以及放在路径中的 Telegram bot token 和飞书机器人 hook token
+func redactRequestURL(u *url.URL) string {
+	redacted := *u
+	params := strings.Split(redacted.RawQuery, "&")
+	for i, param := range params {
+		key, value, ok := strings.Cut(param, "=")
+		if ok && value != "" && value != dryRunAccessToken && slices.Contains(sensitiveQueryParams, key) {
+			params[i] = key + "=******"
+		}
+	}
+	redacted.RawQuery = strings.Join(params, "&")
+
+	segments := strings.Split(redacted.Path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, "bot") && len(segment) > len("bot") && strings.Contains(segment, ":"):
+			// Telegram: /bot<id>:<secret>/sendMessage
+			segments[i] = "bot******"
+		case i > 0 && segments[i-1] == "hook" && segment != "":
+			// 飞书: /open-apis/bot/v2/hook/<token>
+			segments[i] = "******"
+		}
+	}
+	redacted.Path = strings.Join(segments, "/")
+	redacted.RawPath = redacted.Path
+	return redacted.Redacted()
+}
+
+// RenderedRequest 演练模式下渲染出的请求（HTTP 请求或邮件）
+type RenderedRequest struct {
+	Method  string            `json:"method"`            // 请求方法，邮件为 SMTP
+	URL     string            `json:"url"`               // 请求地址，邮件为 smtp://host:port
+	Headers map[string]string `json:"headers,omitempty"` // 请求头，认证信息已隐藏
+	Body    string            `json:"body"`              // 请求体或邮件原文
+}
+
+// dryRunRecorder 收集演练模式下渲染出的请求
+type dryRunRecorder struct {
+	mu       sync.Mutex
+	requests []RenderedRequest
+}
+
+type dryRunKey struct{}
+
+// withDryRun 开启演练模式：后续的通知请求只渲染并记录，不实际发送
+func withDryRun(ctx context.Context) (context.Context, *dryRunRecorder) {
+	recorder := &dryRunRecorder{}
+	return context.WithValue(ctx, dryRunKey{}, recorder), recorder
+}
+
+// dryRunFrom 返回 ctx 中的演练记录器，未开启演练模式时返回 nil
+func dryRunFrom(ctx context.Context) *dryRunRecorder {
+	recorder, _ := ctx.Value(dryRunKey{}).(*dryRunRecorder)
+	return recorder
+}
+
+func (r *dryRunRecorder) add(request RenderedRequest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, request)
+}
+
+// Requests 已记录的请求
+func (r *dryRunRecorder) Requests() []RenderedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RenderedRequest(nil), r.requests...)
+}
+
+// recordHTTP 记录一个 HTTP 请求，读取请求体后不再发送
+func (r *dryRunRecorder) recordHTTP(req *http.Request) error {
+	rendered := RenderedRequest{Method: req.Method, URL: redactRequestURL(req.URL)}
+	if len(req.Header) > 0 {
+		rendered.Headers = make(map[string]string, len(req.Header))
+		for key, values := range req.Header {
+			value := strings.Join(values, ", ")
+			if sensitiveHeaders[http.CanonicalHeaderKey(key)] {
+				value = "******"
+			}
+			rendered.Headers[key] = value
+		}
+	}
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return err
+		}
+		rendered.Body = string(data)
+	}
+	r.add(rendered)
+	return nil
+}
+
+// DryRunNotificationByConfigs 演练模式：按渠道配置渲染示例告警实际会发送的请求，但不发送
+// 不经过静音、去重、路由和生效时间段等过滤，也不计入发送统计和发送记录
+func (n *Notifier) DryRunNotificationByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent) []ChannelResult {
+	results := make([]ChannelResult, 0, len(channelConfigs))
+	for _, channelConfig := range channelConfigs {
+		channelConfig.Enabled = true
+		dryCtx, recorder := withDryRun(ctx)
+		startedAt := time.Now()
+		err := n.SendNotificationByConfig(dryCtx, &channelConfig, record, agent)
+		result := ChannelResult{
+			ChannelID:  channelConfig.ID,
+			Name:       channelConfig.Name,
+			Type:       channelConfig.Type,
+			Fallback:   channelConfig.Fallback,
+			Success:    err == nil,
+			DurationMs: time.Since(startedAt).Milliseconds(),
+			Requests:   recorder.Requests(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/dushixiang/pika/internal/repo"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+)
+
+// saveNotificationLog 持久化一次通知发送记录，失败只记录日志，不影响通知发送
+func (s *AlertService) saveNotificationLog(log *models.NotificationLog) {
+	if err := s.notificationLogRepo.CreateLog(context.Background(), log); err != nil {
+		s.logger.Warn("保存通知发送记录失败",
+			zap.String("channelType", log.ChannelType),
+			zap.Int64("recordId", log.AlertRecordID),
+			zap.Error(err),
+		)
+	}
+}
+
+// ListNotificationLogs 按条件分页查询通知发送记录
+func (s *AlertService) ListNotificationLogs(ctx context.Context, filter repo.NotificationLogFilter, pr *orz.PageRequest) (*orz.PageResult[models.NotificationLog], error) {
+	logs, total, err := s.notificationLogRepo.FindLogs(ctx, filter, pr.PageSize, (pr.PageIndex-1)*pr.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return orz.NewPageResult(logs, total), nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// proxyURLKey 渠道代理地址在 ctx 中的键
+type proxyURLKey struct{}
+
+// parseProxyURL 解析渠道配置中的 proxyUrl，支持 http、https、socks5，未配置时返回 nil
+func parseProxyURL(config map[string]interface{}) (*url.URL, error) {
+	raw, _ := config["proxyUrl"].(string)
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("proxyUrl 无效: %s", raw)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxyUrl 仅支持 http、https、socks5")
+	}
+	return u, nil
+}
+
+// notificationProxy 通知请求使用的代理：渠道配置的 proxyUrl 优先，
+// 否则按 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量选择
+// 所有渠道共用同一个 Transport，连接池按代理地址区分
+func notificationProxy(req *http.Request) (*url.URL, error) {
+	if proxy, ok := req.Context().Value(proxyURLKey{}).(*url.URL); ok && proxy != nil {
+		return proxy, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// withProxyURL 设置后续通知请求使用的代理
+func withProxyURL(ctx context.Context, proxy *url.URL) context.Context {
+	if proxy == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, proxyURLKey{}, proxy)
+}
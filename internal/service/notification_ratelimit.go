@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// rateBucket 令牌桶及被丢弃的消息数
+type rateBucket struct {
+	tokens     float64
+	updatedAt  time.Time
+	suppressed int
+}
+
+// notificationRateLimiter 按渠道（可选再按探针）限制每分钟发送的通知数量，避免告警风暴
+// 超出限制的通知直接丢弃并计数，在下一条允许发送的通知中附带被抑制的数量
+type notificationRateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+func newNotificationRateLimiter() *notificationRateLimiter {
+	return &notificationRateLimiter{buckets: make(map[string]*rateBucket)}
+}
+
+// parseRatePerMinute 解析渠道配置中的 ratePerMinute，未配置时返回 0（不限制）
+func parseRatePerMinute(config map[string]interface{}) (float64, error) {
+	raw, ok := config["ratePerMinute"]
+	if !ok || raw == nil {
+		return 0, nil
+	}
+	rate, ok := raw.(float64)
+	if !ok || rate <= 0 {
+		return 0, fmt.Errorf("ratePerMinute 必须为正数")
+	}
+	return rate, nil
+}
+
+// rateLimitKey 限流的键，渠道配置 "rateLimitPerAgent": true 时每个探针单独计数
+func rateLimitKey(channelConfig *models.NotificationChannelConfig, record *models.AlertRecord) string {
+	key := channelLimiterKey(channelConfig)
+	if perAgent, _ := channelConfig.Config["rateLimitPerAgent"].(bool); perAgent && record != nil {
+		key += "/" + record.AgentID
+	}
+	return key
+}
+
+// Allow 尝试获取一个发送令牌
+// 允许发送时返回此前被抑制的通知数量并清零；超出限制时返回 false
+func (l *notificationRateLimiter) Allow(key string, ratePerMinute float64, now time.Time) (bool, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket, ok := l.buckets[key]
+	if !ok {
+		bucket = &rateBucket{tokens: ratePerMinute, updatedAt: now}
+		l.buckets[key] = bucket
+	}
+
+	// 按经过的时间补充令牌，容量为每分钟的发送数量
+	elapsed := now.Sub(bucket.updatedAt).Minutes()
+	if elapsed > 0 {
+		bucket.tokens = min(ratePerMinute, bucket.tokens+elapsed*ratePerMinute)
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens < 1 {
+		bucket.suppressed++
+		return false, 0
+	}
+	bucket.tokens--
+	suppressed := bucket.suppressed
+	bucket.suppressed = 0
+	return true, suppressed
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestNotificationRateLimiter(t *testing.T) {
+	l := newNotificationRateLimiter()
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("ch", 2, now); !ok {
+			t.Fatalf("第 %d 条通知应允许发送", i+1)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("ch", 2, now); ok {
+			t.Fatalf("超出频率限制的通知应被丢弃")
+		}
+	}
+
+	// 30 秒补充一个令牌，并带回被抑制的数量
+	ok, suppressed := l.Allow("ch", 2, now.Add(30*time.Second))
+	if !ok || suppressed != 3 {
+		t.Fatalf("Allow() = %v, %d, want true, 3", ok, suppressed)
+	}
+	if ok, _ := l.Allow("other", 2, now); !ok {
+		t.Fatalf("不同渠道应分别计数")
+	}
+}
+
+func TestRateLimitedNotificationReportedAsSuppressed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	channels := []models.NotificationChannelConfig{{
+		ID:      "channel-1",
+		Type:    "webhook",
+		Enabled: true,
+		Config:  map[string]interface{}{"url": server.URL, "ratePerMinute": float64(1)},
+	}}
+	agent := &models.Agent{ID: "agent-1"}
+	send := func(firedAt int64) ChannelResult {
+		record := &models.AlertRecord{AgentID: agent.ID, AlertType: "cpu", Status: "firing", Level: "warning", FiredAt: firedAt}
+		results, err := n.SendNotificationByConfigs(context.Background(), channels, record, agent)
+		if err != nil {
+			t.Fatalf("SendNotificationByConfigs() error = %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("应返回 1 个渠道结果，实际 %d 个", len(results))
+		}
+		return results[0]
+	}
+
+	if got := send(1); !got.Success || got.Suppressed {
+		t.Fatalf("首条通知应发送成功: %+v", got)
+	}
+	if got := send(2); got.Success || !got.Suppressed {
+		t.Fatalf("超出频率限制的通知应标记为已丢弃而非成功: %+v", got)
+	}
+	if stats := n.stats.Snapshot(time.Now()); len(stats) != 1 || stats[0].LastMinute.Sent != 1 {
+		t.Fatalf("被丢弃的通知不应计入发送统计: %+v", stats)
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxNotificationRetries 最大重试次数，超过后进入死信队列
+	maxNotificationRetries = 5
+	// notificationRetryBackoff 首次重试的等待时间，之后按次数翻倍
+	notificationRetryBackoff = time.Minute
+	// maxNotificationRetryBackoff 重试等待时间上限
+	maxNotificationRetryBackoff = time.Hour
+	// notificationRetryInterval 重试任务的检查间隔
+	notificationRetryInterval = 30 * time.Second
+	// notificationRetryBatchSize 每次检查最多处理的重试数
+	notificationRetryBatchSize = 50
+)
+
+// notificationRetryDelay 第 attempts 次重试前的等待时间
+func notificationRetryDelay(attempts int) time.Duration {
+	delay := notificationRetryBackoff << attempts
+	if delay <= 0 || delay > maxNotificationRetryBackoff {
+		return maxNotificationRetryBackoff
+	}
+	return delay
+}
+
+// enqueueNotificationRetry 记录发送失败的通知，由重试任务在稍后重新发送
+// 重放和未持久化的告警（如测试消息）不重试
+func (s *AlertService) enqueueNotificationRetry(channelConfig models.NotificationChannelConfig, record *models.AlertRecord, sendErr error) {
+	if channelConfig.ID == "" || record.ID == 0 || record.Replay {
+		return
+	}
+	// 部分接收方已收到，整体重发会造成重复消息
+	if partialDelivery(sendErr) {
+		s.logger.Warn("通知部分接收方投递失败，不再重试",
+			zap.String("channelId", channelConfig.ID),
+			zap.Int64("recordId", record.ID),
+			zap.Error(sendErr),
+		)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	retry := &models.NotificationRetry{
+		ChannelID:     channelConfig.ID,
+		RecordID:      record.ID,
+		RecordStatus:  record.Status,
+		Status:        models.NotificationRetryPending,
+		NextAttemptAt: now.Add(notificationRetryDelay(0)).UnixMilli(),
+		LastError:     deliveryErrorMessage(sendErr),
+		CreatedAt:     now.UnixMilli(),
+		UpdatedAt:     now.UnixMilli(),
+	}
+	if err := s.notificationRetryRepo.Create(ctx, retry); err != nil {
+		s.logger.Error("保存待重试通知失败",
+			zap.String("channelId", channelConfig.ID),
+			zap.Int64("recordId", record.ID),
+			zap.Error(err),
+		)
+	}
+}
+
+// StartNotificationRetryTask 启动通知重试任务，启动时立即处理重启前遗留的待重试通知
+func (s *AlertService) StartNotificationRetryTask(ctx context.Context) {
+	s.logger.Info("启动通知重试任务")
+
+	ticker := time.NewTicker(notificationRetryInterval)
+	defer ticker.Stop()
+
+	s.processNotificationRetries(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("通知重试任务已停止")
+			return
+		case <-ticker.C:
+			s.processNotificationRetries(ctx)
+		}
+	}
+}
+
+// processNotificationRetries 重新发送已到重试时间的通知
+func (s *AlertService) processNotificationRetries(ctx context.Context) {
+	retries, err := s.notificationRetryRepo.FindDue(ctx, time.Now().UnixMilli(), notificationRetryBatchSize)
+	if err != nil {
+		s.logger.Error("获取待重试通知失败", zap.Error(err))
+		return
+	}
+	for i := range retries {
+		s.retryNotification(ctx, &retries[i])
+	}
+}
+
+// retryNotification 重试一条通知：成功或已无需发送时删除，失败时推迟下次重试，超过次数后进入死信队列
+func (s *AlertService) retryNotification(ctx context.Context, retry *models.NotificationRetry) {
+	sendCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	sendErr := s.resendNotification(sendCtx, retry)
+	if partialDelivery(sendErr) {
+		s.logger.Warn("重试时部分接收方投递失败，不再重试",
+			zap.String("channelId", retry.ChannelID),
+			zap.Int64("recordId", retry.RecordID),
+			zap.Error(sendErr),
+		)
+		sendErr = nil
+	}
+	if sendErr == nil {
+		if err := s.notificationRetryRepo.DeleteById(ctx, retry.ID); err != nil {
+			s.logger.Error("删除待重试通知失败", zap.Int64("id", retry.ID), zap.Error(err))
+		}
+		return
+	}
+
+	retry.Attempts++
+	retry.LastError = deliveryErrorMessage(sendErr)
+	retry.UpdatedAt = time.Now().UnixMilli()
+	if retry.Attempts >= maxNotificationRetries {
+		retry.Status = models.NotificationRetryDead
+		s.logger.Error("通知重试次数已用尽，进入死信队列",
+			zap.String("channelId", retry.ChannelID),
+			zap.Int64("recordId", retry.RecordID),
+			zap.Int("attempts", retry.Attempts),
+			zap.Error(sendErr),
+		)
+	} else {
+		retry.NextAttemptAt = time.Now().Add(notificationRetryDelay(retry.Attempts)).UnixMilli()
+		s.logger.Warn("通知重试失败，稍后再试",
+			zap.String("channelId", retry.ChannelID),
+			zap.Int64("recordId", retry.RecordID),
+			zap.Int("attempts", retry.Attempts),
+			zap.Error(sendErr),
+		)
+	}
+	if err := s.notificationRetryRepo.SaveRetry(ctx, retry); err != nil {
+		s.logger.Error("保存通知重试状态失败", zap.Int64("id", retry.ID), zap.Error(err))
+	}
+	if retry.Status == models.NotificationRetryDead {
+		s.notifyRetryExhausted(ctx, retry, sendErr)
+	}
+}
+
+// notifyRetryExhausted 渠道放弃投递后，通过其他已启用的备用渠道告知值班人员，避免告警链路失效无人知晓
+func (s *AlertService) notifyRetryExhausted(ctx context.Context, retry *models.NotificationRetry, sendErr error) {
+	channel, err := s.channelService.Get(ctx, retry.ChannelID)
+	if err != nil {
+		return
+	}
+	record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, retry.RecordID)
+	if err != nil {
+		return
+	}
+	agent, err := s.agentRepo.FindById(ctx, record.AgentID)
+	if err != nil {
+		return
+	}
+	channels, err := s.getEnabledChannels(ctx)
+	if err != nil {
+		return
+	}
+	var fallbacks []models.NotificationChannelConfig
+	for _, channelConfig := range channels {
+		if channelConfig.Fallback && channelConfig.ID != channel.ID {
+			fallbacks = append(fallbacks, channelConfig)
+		}
+	}
+	s.notifier.notifyDeliveryFailure(ctx, fallbacks, &channel.NotificationChannelConfig, record, &agent, sendErr)
+}
+
+// resendNotification 按当前的渠道配置和告警记录重新发送通知
+// 渠道已删除或禁用、告警状态已变化或已被屏蔽时不再发送，返回 nil 以移除该重试
+func (s *AlertService) resendNotification(ctx context.Context, retry *models.NotificationRetry) error {
+	channel, err := s.channelService.Get(ctx, retry.ChannelID)
+	if err != nil || !channel.Enabled {
+		s.logger.Info("通知渠道已删除或禁用，放弃重试", zap.String("channelId", retry.ChannelID))
+		return nil
+	}
+	record, err := s.AlertRecordRepo.GetAlertRecordByID(ctx, retry.RecordID)
+	if err != nil || record.Status != retry.RecordStatus {
+		s.logger.Info("告警记录已删除或状态已变化，放弃重试", zap.Int64("recordId", retry.RecordID))
+		return nil
+	}
+	// 失败后新建的静音、暂停通知和静默规则同样生效
+	if s.notifier.suppressedRecord(ctx, record) {
+		return nil
+	}
+	agent, err := s.agentRepo.FindById(ctx, record.AgentID)
+	if err != nil {
+		return err
+	}
+	// 批量 Webhook 也立即发送，确保本次重试结果计入重试次数
+	err = s.notifier.SendNotificationByConfig(withDirectDelivery(ctx), &channel.NotificationChannelConfig, record, &agent)
+	// 被发送频率限制丢弃属于预期行为，不再重试
+	if errors.Is(err, errRateLimited) {
+		return nil
+	}
+	return err
+}
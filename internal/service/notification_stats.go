@@ -0,0 +1,124 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// notificationStatsBuckets 统计窗口的分钟桶数，保留最近一小时
+const notificationStatsBuckets = 60
+
+// notificationBucket 一分钟内的发送计数
+type notificationBucket struct {
+	minute  int64 // Unix 分钟数
+	success int
+	failed  int
+}
+
+// channelCounters 单个渠道的发送计数（环形分钟桶）
+type channelCounters struct {
+	channelType string
+	name        string
+	buckets     [notificationStatsBuckets]notificationBucket
+}
+
+// notificationStats 通知发送量的内存统计，重启后清零
+type notificationStats struct {
+	mu       sync.Mutex
+	channels map[string]*channelCounters
+}
+
+func newNotificationStats() *notificationStats {
+	return &notificationStats{channels: make(map[string]*channelCounters)}
+}
+
+// Record 记录一次发送结果
+func (s *notificationStats) Record(channelID, channelType, name string, success bool, now time.Time) {
+	if channelID == "" {
+		channelID = channelType
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, ok := s.channels[channelID]
+	if !ok {
+		counters = &channelCounters{}
+		s.channels[channelID] = counters
+	}
+	counters.channelType = channelType
+	counters.name = name
+
+	minute := now.Unix() / 60
+	bucket := &counters.buckets[minute%notificationStatsBuckets]
+	if bucket.minute != minute {
+		*bucket = notificationBucket{minute: minute}
+	}
+	if success {
+		bucket.success++
+	} else {
+		bucket.failed++
+	}
+}
+
+// NotificationVolume 一段时间内的发送量
+type NotificationVolume struct {
+	Sent    int `json:"sent"`    // 发送总数
+	Success int `json:"success"` // 成功数
+	Failed  int `json:"failed"`  // 失败数
+}
+
+// ChannelNotificationStats 渠道近期发送统计
+type ChannelNotificationStats struct {
+	ChannelID   string             `json:"channelId"`
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	LastMinute  NotificationVolume `json:"lastMinute"`  // 最近一分钟
+	LastHour    NotificationVolume `json:"lastHour"`    // 最近一小时
+	SuccessRate float64            `json:"successRate"` // 最近一小时成功率（0-1），无发送时为 1
+}
+
+// Snapshot 获取各渠道最近一分钟、一小时的发送统计
+func (s *notificationStats) Snapshot(now time.Time) []ChannelNotificationStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := now.Unix() / 60
+	result := make([]ChannelNotificationStats, 0, len(s.channels))
+	for id, counters := range s.channels {
+		stats := ChannelNotificationStats{
+			ChannelID: id,
+			Name:      counters.name,
+			Type:      counters.channelType,
+		}
+		for _, bucket := range counters.buckets {
+			age := current - bucket.minute
+			if age < 0 || age >= notificationStatsBuckets {
+				continue
+			}
+			stats.LastHour.Success += bucket.success
+			stats.LastHour.Failed += bucket.failed
+			if age == 0 {
+				stats.LastMinute.Success += bucket.success
+				stats.LastMinute.Failed += bucket.failed
+			}
+		}
+		stats.LastMinute.Sent = stats.LastMinute.Success + stats.LastMinute.Failed
+		stats.LastHour.Sent = stats.LastHour.Success + stats.LastHour.Failed
+		stats.SuccessRate = 1
+		if stats.LastHour.Sent > 0 {
+			stats.SuccessRate = float64(stats.LastHour.Success) / float64(stats.LastHour.Sent)
+		}
+		result = append(result, stats)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ChannelID < result[j].ChannelID
+	})
+	return result
+}
+
+// NotificationStats 获取各渠道近期的通知发送量和成功率
+func (n *Notifier) NotificationStats() []ChannelNotificationStats {
+	return n.stats.Snapshot(time.Now())
+}
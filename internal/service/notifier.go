@@ -5,124 +5,498 @@ import (
 	"context"
 	"crypto/hmac"
 	"crypto/sha256"
+	"crypto/tls"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/dushixiang/pika/internal/config"
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/valyala/fasttemplate"
 	"go.uber.org/zap"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
 )
 
 // Notifier 告警通知服务
 type Notifier struct {
 	logger *zap.Logger
+	// 共享的 HTTP 客户端，复用连接
+	client *http.Client
+	// 访问令牌缓存（企业微信应用、飞书自建应用等）
+	tokens *tokenCache
+	// 自定义Webhook批量发送缓冲
+	batcher *webhookBatcher
+	// 各渠道发送量统计（仅内存）
+	stats *notificationStats
+	// 各渠道独立的并发限制
+	limiter *channelLimiter
+	// 各渠道独立的发送频率限制
+	rateLimiter *notificationRateLimiter
+	// 相同告警通知的去重
+	dedup *notificationDedup
+	// 渠道发送失败时的回调，用于持久化待重试的通知
+	onDeliveryFailed func(channelConfig models.NotificationChannelConfig, record *models.AlertRecord, err error)
+	// 每次向渠道发送告警通知后的回调，用于持久化发送记录
+	onDeliveryAttempt func(log *models.NotificationLog)
+
+	propertyService *PropertyService
+
+	// 当前毫秒时间戳，用于钉钉、飞书签名，测试时可替换为固定时间
+	now func() int64
 }
 
-func NewNotifier(logger *zap.Logger) *Notifier {
-	return &Notifier{
+func NewNotifier(logger *zap.Logger, cfg *config.AppConfig, propertyService *PropertyService) (*Notifier, error) {
+	var notificationConfig config.NotificationConfig
+	if cfg != nil && cfg.Notification != nil {
+		notificationConfig = *cfg.Notification
+	}
+	transport, err := newNotificationTransport(logger, notificationConfig)
+	if err != nil {
+		return nil, err
+	}
+	n := &Notifier{
 		logger: logger,
+		// 超时由 doHTTP 按渠道配置的 timeoutSeconds 控制
+		client: &http.Client{
+			Transport: transport,
+		},
+		tokens:          newTokenCache(),
+		stats:           newNotificationStats(),
+		limiter:         newChannelLimiter(),
+		rateLimiter:     newNotificationRateLimiter(),
+		dedup:           newNotificationDedup(time.Duration(notificationConfig.DedupWindowSeconds) * time.Second),
+		propertyService: propertyService,
+		now:             func() int64 { return time.Now().UnixMilli() },
 	}
+	n.batcher = newWebhookBatcher(n.flushWebhookBatch)
+	return n, nil
 }
 
-// buildMessage 构建告警消息文本
-func (n *Notifier) buildMessage(agent *models.Agent, record *models.AlertRecord) string {
-	var message string
+// newNotificationTransport 根据配置创建通知发送使用的 Transport
+func newNotificationTransport(logger *zap.Logger, cfg config.NotificationConfig) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
 
-	// 告警级别图标
-	levelIcon := ""
-	switch record.Level {
-	case "info":
-		levelIcon = "ℹ️"
-	case "warning":
-		levelIcon = "⚠️"
-	case "critical":
-		levelIcon = "🚨"
+	transport.MaxIdleConns = 100
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+	}
+	transport.MaxIdleConnsPerHost = 10
+	if cfg.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+	}
+	transport.IdleConnTimeout = 90 * time.Second
+	if cfg.IdleConnTimeoutSeconds > 0 {
+		transport.IdleConnTimeout = time.Duration(cfg.IdleConnTimeoutSeconds) * time.Second
+	}
+	tlsConfig, err := newNotificationTLSConfig(logger, cfg)
+	if err != nil {
+		return nil, err
 	}
+	transport.TLSClientConfig = tlsConfig
+	transport.Proxy = notificationProxy
+	return transport, nil
+}
 
-	// 告警类型名称
-	alertTypeName := ""
-	switch record.AlertType {
+// newNotificationTLSConfig 出站通知连接的 TLS 配置，默认最低 TLS 1.2
+// 配置的加密套件全部无效时返回错误，避免静默回退到 Go 默认套件
+func newNotificationTLSConfig(logger *zap.Logger, cfg config.NotificationConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	switch cfg.TLSMinVersion {
+	case "", "1.2":
+	case "1.3":
+		tlsConfig.MinVersion = tls.VersionTLS13
+	default:
+		logger.Warn("不支持的最低 TLS 版本，使用 TLS 1.2", zap.String("version", cfg.TLSMinVersion))
+	}
+
+	if len(cfg.TLSCipherSuites) > 0 {
+		available := make(map[string]uint16)
+		for _, suite := range tls.CipherSuites() {
+			available[suite.Name] = suite.ID
+		}
+		for _, name := range cfg.TLSCipherSuites {
+			id, ok := available[name]
+			if !ok {
+				logger.Warn("忽略不支持或不安全的加密套件", zap.String("cipherSuite", name))
+				continue
+			}
+			tlsConfig.CipherSuites = append(tlsConfig.CipherSuites, id)
+		}
+		if len(tlsConfig.CipherSuites) == 0 {
+			return nil, fmt.Errorf("通知 TLSCipherSuites 配置无效，没有可用的加密套件: %s", strings.Join(cfg.TLSCipherSuites, ", "))
+		}
+	}
+	return tlsConfig, nil
+}
+
+// messageOptions 渠道级别的消息格式选项
+type messageOptions struct {
+	// Plain 纯文本模式，使用 [INFO]/[WARN]/[CRIT]/[OK] 等文本标记代替 emoji，适用于短信等受限渠道
+	Plain bool
+	// Fields 消息中展示的字段及顺序，为空时使用默认的完整布局
+	Fields []string
+	// ShowLabels 是否在消息中附带告警标签
+	ShowLabels bool
+	// Severities 系统配置中自定义的告警级别展示方式
+	Severities map[string]models.SeverityPresentation
+	// ResolvedDetail 恢复消息中是否附带恢复值与阈值的对比，默认开启
+	ResolvedDetail bool
+	// Locale 消息语言，渠道配置优先，其次系统配置，最后为 zh
+	Locale string
+	// Template 告警和恢复消息共用的自定义模板（text/template）
+	Template string
+	// FiringTemplate/ResolvedTemplate 告警/恢复消息的自定义模板，优先于 Template；均未配置时使用内置格式
+	FiringTemplate   string
+	ResolvedTemplate string
+}
+
+// levelIcon 告警级别图标：纯文本模式使用文本标记，其次使用系统配置，最后使用内置 emoji
+func (o messageOptions) levelIcon(level string) string {
+	if o.Plain {
+		return plainLevelTags[level]
+	}
+	if severity, ok := o.Severities[level]; ok {
+		return severity.Icon
+	}
+	return levelIcons[level]
+}
+
+// resolvedIcon 告警恢复图标
+func (o messageOptions) resolvedIcon() string {
+	if o.Plain {
+		return "[OK]"
+	}
+	return "✅"
+}
+
+// levelName 系统配置中自定义的告警级别名称，未配置时为空
+func (o messageOptions) levelName(level string) string {
+	return o.Severities[level].Name
+}
+
+// messageFields 消息可选字段
+var messageFields = map[string]bool{
+	"probe":     true,
+	"host":      true,
+	"ip":        true,
+	"type":      true,
+	"message":   true,
+	"threshold": true,
+	"value":     true,
+	"time":      true,
+}
+
+// defaultMessageFields 默认的完整消息布局
+var defaultMessageFields = []string{"probe", "host", "ip", "type", "message", "threshold", "value", "time"}
+
+// parseMessageOptions 从渠道配置中解析消息格式选项
+// 配置格式: "format": "plain", "fields": ["probe", "type", "value", "time"]
+func parseMessageOptions(config map[string]interface{}) messageOptions {
+	var opts messageOptions
+	if format, ok := config["format"].(string); ok {
+		opts.Plain = format == "plain"
+	}
+	opts.ShowLabels, _ = config["showLabels"].(bool)
+	opts.Template, _ = config["template"].(string)
+	opts.FiringTemplate, _ = config["firingTemplate"].(string)
+	opts.ResolvedTemplate, _ = config["resolvedTemplate"].(string)
+	opts.Locale, _ = config["locale"].(string)
+	opts.Locale = resolveMessageLocale(opts.Locale)
+	opts.ResolvedDetail = true
+	if detail, ok := config["resolvedDetail"].(bool); ok {
+		opts.ResolvedDetail = detail
+	}
+	if fields, ok := config["fields"].([]interface{}); ok {
+		for _, f := range fields {
+			// 忽略未知字段
+			if name, ok := f.(string); ok && messageFields[name] {
+				opts.Fields = append(opts.Fields, name)
+			}
+		}
+	}
+	return opts
+}
+
+// levelIcons 告警级别图标
+var levelIcons = map[string]string{
+	"info":     "ℹ️",
+	"warning":  "⚠️",
+	"critical": "🚨",
+}
+
+// plainLevelTags 纯文本模式下的告警级别标记
+var plainLevelTags = map[string]string{
+	"info":     "[INFO]",
+	"warning":  "[WARN]",
+	"critical": "[CRIT]",
+}
+
+// alertTypeDisplayName 告警类型名称
+func alertTypeDisplayName(alertType string) string {
+	switch alertType {
 	case "cpu":
-		alertTypeName = "CPU告警"
+		return "CPU告警"
 	case "memory":
-		alertTypeName = "内存告警"
+		return "内存告警"
 	case "disk":
-		alertTypeName = "磁盘告警"
+		return "磁盘告警"
 	case "network":
-		alertTypeName = "网络断开告警"
+		return "网络断开告警"
 	case "cert":
-		alertTypeName = "证书告警"
+		return "证书告警"
 	case "service":
-		alertTypeName = "服务告警"
-	}
-
-	if record.Status == "firing" {
-		// 告警触发消息
-		message = fmt.Sprintf(
-			"%s %s\n\n"+
-				"探针: %s (%s)\n"+
-				"主机: %s\n"+
-				"IP: %s\n"+
-				"告警类型: %s\n"+
-				"告警消息: %s\n"+
-				"阈值: %.2f%%\n"+
-				"当前值: %.2f%%\n"+
-				"触发时间: %s",
-			levelIcon,
-			alertTypeName,
-			agent.Name,
-			agent.ID,
-			agent.Hostname,
-			agent.IP,
-			record.AlertType,
-			record.Message,
-			record.Threshold,
-			record.ActualValue,
-			time.Unix(record.FiredAt/1000, 0).Format("2006-01-02 15:04:05"),
-		)
-	} else if record.Status == "resolved" {
-		// 告警恢复消息
-		message = fmt.Sprintf(
-			"✅ %s已恢复\n\n"+
-				"探针: %s (%s)\n"+
-				"主机: %s\n"+
-				"IP: %s\n"+
-				"告警类型: %s\n"+
-				"当前值: %.2f%%\n"+
-				"恢复时间: %s",
-			alertTypeName,
-			agent.Name,
-			agent.ID,
-			agent.Hostname,
-			agent.IP,
-			record.AlertType,
-			record.ActualValue,
-			time.Unix(record.ResolvedAt/1000, 0).Format("2006-01-02 15:04:05"),
-		)
+		return "服务告警"
+	case "version":
+		return "版本落后告警"
+	case "agent_offline":
+		return "探针离线告警"
+	case "notification_failed":
+		return "通知投递失败"
+	}
+	return ""
+}
+
+// testMessageAlertTypes 测试消息中用于检查字符集的告警类型
+var testMessageAlertTypes = []string{"cpu", "memory", "disk", "network", "cert", "service"}
+
+// BuildTestMessage 构建测试消息，包含真实告警使用的中文告警类型名称和级别图标，
+// 便于在测试阶段发现接收方字符集问题，而不是等到第一条真实告警
+func BuildTestMessage() string {
+	names := make([]string, 0, len(testMessageAlertTypes))
+	for _, alertType := range testMessageAlertTypes {
+		names = append(names, alertTypeDisplayName(alertType))
+	}
+	icons := []string{levelIcons["info"], levelIcons["warning"], levelIcons["critical"], "✅"}
+	return fmt.Sprintf("这是一条测试通知消息\n\n字符集检查: %s\n级别图标: %s\n如以上内容显示为乱码，请检查接收方的字符集配置",
+		strings.Join(names, "、"), strings.Join(icons, " "))
+}
+
+// messageLine 告警消息中的一行字段
+type messageLine struct {
+	Label string
+	Value string
+}
+
+// messageContent 告警消息的结构化内容，供文本、Markdown、卡片等格式渲染
+type messageContent struct {
+	Title string
+	Lines []messageLine
+}
+
+// messageOptions 解析渠道的消息格式选项，并附带系统配置中的告警级别展示方式
+func (n *Notifier) messageOptions(ctx context.Context, config map[string]interface{}) messageOptions {
+	opts := parseMessageOptions(config)
+	if n.propertyService != nil {
+		if systemConfig, err := n.propertyService.GetSystemConfig(ctx); err == nil {
+			opts.Severities = systemConfig.Severities
+			// 渠道未指定或指定了不支持的语言时，依次回退到系统默认语言和 zh
+			channelLocale, _ := config["locale"].(string)
+			if channelLocale == "" {
+				channelLocale, _ = config["lang"].(string)
+			}
+			opts.Locale = resolveMessageLocale(channelLocale, systemConfig.Locale)
+		}
+	}
+	return opts
+}
+
+// buildMessageContent 按渠道选项构建告警消息的标题和字段
+func (n *Notifier) buildMessageContent(agent *models.Agent, record *models.AlertRecord, opts messageOptions) (messageContent, bool) {
+	if record.Status != "firing" && record.Status != "resolved" {
+		return messageContent{}, false
+	}
+	firing := record.Status == "firing"
+
+	// 告警类型名称
+	alertTypeName := opts.alertTypeName(record.AlertType)
+
+	fields := opts.Fields
+	if len(fields) == 0 {
+		fields = defaultMessageFields
+	}
+
+	var content messageContent
+	if firing {
+		content.Title = fmt.Sprintf("%s %s", opts.levelIcon(record.Level), alertTypeName)
+		if name := opts.levelName(record.Level); name != "" {
+			content.Title = fmt.Sprintf("%s [%s] %s", opts.levelIcon(record.Level), name, alertTypeName)
+		}
+	} else {
+		content.Title = opts.textf("title.resolved", opts.resolvedIcon(), alertTypeName)
+	}
+	// 重放的历史告警需明确标注，避免被误认为新告警
+	if record.Replay {
+		content.Title = opts.text("title.replay") + content.Title
+	}
+
+	add := func(label, value string) {
+		content.Lines = append(content.Lines, messageLine{Label: label, Value: value})
 	}
+	for _, field := range fields {
+		switch field {
+		case "probe":
+			// 自定义字段布局时不暴露探针ID，便于在共享群聊中使用
+			if len(opts.Fields) == 0 {
+				add(opts.text("label.probe"), fmt.Sprintf("%s (%s)", agent.Name, agent.ID))
+			} else {
+				add(opts.text("label.probe"), agent.Name)
+			}
+		case "host":
+			add(opts.text("label.host"), agent.Hostname)
+		case "ip":
+			add(opts.text("label.ip"), agent.IP)
+		case "type":
+			add(opts.text("label.type"), record.AlertType)
+		case "message":
+			if firing {
+				add(opts.text("label.message"), record.Message)
+			}
+		case "threshold":
+			if firing && hasMetricValue(record.AlertType) {
+				add(opts.text("label.threshold"), formatAlertValue(record.AlertType, record.Threshold, opts))
+			}
+		case "value":
+			if !hasMetricValue(record.AlertType) {
+				continue
+			}
+			add(opts.text("label.value"), formatAlertValue(record.AlertType, record.ActualValue, opts))
+			// 近期趋势
+			if firing && len(record.RecentValues) > 0 {
+				values := make([]string, 0, len(record.RecentValues))
+				for _, v := range record.RecentValues {
+					values = append(values, formatAlertValue(record.AlertType, v, opts))
+				}
+				add(opts.text("label.trend"), strings.Join(values, " → "))
+			}
+			// 恢复时与阈值对比，确认已明显回到正常范围
+			if !firing && opts.ResolvedDetail {
+				if detail := resolvedThresholdDetail(record, opts); detail != "" {
+					add(opts.text("label.resolvedDetail"), detail)
+				}
+			}
+		case "time":
+			if firing {
+				add(opts.text("label.firedAt"), time.Unix(record.FiredAt/1000, 0).Format("2006-01-02 15:04:05"))
+			} else {
+				add(opts.text("label.resolvedAt"), time.Unix(record.ResolvedAt/1000, 0).Format("2006-01-02 15:04:05"))
+			}
+		}
+	}
+	if opts.ShowLabels && len(record.Labels) > 0 {
+		add(opts.text("label.labels"), formatLabels(record.Labels))
+	}
+	return content, true
+}
 
-	return message
+// hasMetricValue 告警类型的阈值和当前值是否有意义
+// 服务下线和版本落后告警没有可比较的数值，消息中不展示阈值和当前值
+func hasMetricValue(alertType string) bool {
+	switch alertType {
+	case "service", "version":
+		return false
+	}
+	return true
 }
 
-// sendDingTalk 发送钉钉通知
+// formatAlertValue 按告警类型的单位格式化阈值和当前值
+func formatAlertValue(alertType string, value float64, opts messageOptions) string {
+	switch alertType {
+	case "cpu", "memory", "disk":
+		return fmt.Sprintf("%.2f%%", value)
+	case "network":
+		return fmt.Sprintf("%.2fMB/s", value)
+	case "cert":
+		return opts.textf("unit.days", value)
+	case "service", "agent_offline":
+		return opts.textf("unit.seconds", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
+
+// resolvedThresholdDetail 恢复值与阈值的对比，如“恢复至 45.00%，低于阈值 80.00%”
+// 仅适用于基于阈值判断的告警类型
+func resolvedThresholdDetail(record *models.AlertRecord, opts messageOptions) string {
+	if record.Threshold <= 0 {
+		return ""
+	}
+	value := formatAlertValue(record.AlertType, record.ActualValue, opts)
+	threshold := formatAlertValue(record.AlertType, record.Threshold, opts)
+	switch record.AlertType {
+	case "cpu", "memory", "disk", "network":
+		return opts.textf("detail.below", value, threshold)
+	case "cert":
+		// 证书剩余天数越少越严重
+		return opts.textf("detail.above", value, threshold)
+	}
+	return ""
+}
+
+// formatLabels 将标签格式化为按键排序的 k=v 列表
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, ", ")
+}
+
+// buildMessage 构建告警消息文本，渠道配置了对应状态的自定义模板时优先使用模板
+func (n *Notifier) buildMessage(agent *models.Agent, record *models.AlertRecord, opts messageOptions) string {
+	content, ok := n.buildMessageContent(agent, record, opts)
+	if !ok {
+		return ""
+	}
+	if message, ok := n.renderMessageTemplate(agent, record, opts, content); ok {
+		return message
+	}
+
+	var b strings.Builder
+	b.WriteString(content.Title)
+	b.WriteString("\n")
+	for _, line := range content.Lines {
+		b.WriteString("\n" + line.Label + ": " + line.Value)
+	}
+	return b.String()
+}
+
+// sendDingTalk 发送钉钉文本通知
 func (n *Notifier) sendDingTalk(ctx context.Context, webhook, secret, message string) error {
-	// 构造钉钉消息体
-	body := map[string]interface{}{
+	return n.sendDingTalkBody(ctx, webhook, secret, dingTalkTextBody(message))
+}
+
+// dingTalkTextBody 构造钉钉文本消息体
+func dingTalkTextBody(message string) map[string]interface{} {
+	return map[string]interface{}{
 		"msgtype": "text",
 		"text": map[string]string{
 			"content": message,
 		},
 	}
+}
 
+// sendDingTalkBody 发送已构建好的钉钉消息体（文本、Markdown、ActionCard），加签对所有消息类型生效
+func (n *Notifier) sendDingTalkBody(ctx context.Context, webhook, secret string, body map[string]interface{}) error {
 	// 如果有加签密钥，计算签名
-	timestamp := time.Now().UnixMilli()
 	if secret != "" {
+		timestamp := n.now()
 		sign := n.calculateDingTalkSign(timestamp, secret)
 		webhook = fmt.Sprintf("%s&timestamp=%d&sign=%s", webhook, timestamp, sign)
 	}
@@ -133,6 +507,13 @@ func (n *Notifier) sendDingTalk(ctx context.Context, webhook, secret, message st
 	return nil
 }
 
+// calculateFeishuSign 计算飞书签名：以 "timestamp\n密钥" 为 HMAC-SHA256 的 key 对空串签名后 Base64 编码
+func calculateFeishuSign(timestamp int64, secret string) string {
+	stringToSign := fmt.Sprintf("%d\n%s", timestamp, secret)
+	h := hmac.New(sha256.New, []byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
 // calculateDingTalkSign 计算钉钉加签
 func (n *Notifier) calculateDingTalkSign(timestamp int64, secret string) string {
 	stringToSign := fmt.Sprintf("%d\n%s", timestamp, secret)
@@ -146,109 +527,136 @@ type WeComResult struct {
 	Errmsg    string `json:"errmsg"`
 	Type      string `json:"type"`
 	MediaId   string `json:"media_id"`
+	MsgId     string `json:"msgid"`
 	CreatedAt string `json:"created_at"`
 }
 
-// sendWeCom 发送企业微信通知
-func (n *Notifier) sendWeCom(ctx context.Context, webhook, message string) error {
+// messageID 返回平台侧的消息标识，用于与平台投递日志对照
+func (r WeComResult) messageID() string {
+	if r.MsgId != "" {
+		return r.MsgId
+	}
+	return r.MediaId
+}
+
+// FeishuResult 飞书接口返回结果
+type FeishuResult struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		MessageId string `json:"message_id"`
+	} `json:"data"`
+}
+
+// sendWeCom 发送企业微信通知，返回平台消息ID（如有）
+func (n *Notifier) sendWeCom(ctx context.Context, webhook, message string) (string, error) {
+	return n.sendWeComText(ctx, webhook, message, nil)
+}
+
+// sendWeComText 发送企业微信文本通知，mentionedMobiles 为需要 @ 的手机号（@all 表示所有人）
+func (n *Notifier) sendWeComText(ctx context.Context, webhook, message string, mentionedMobiles []string) (string, error) {
+	text := map[string]interface{}{
+		"content": message,
+	}
+	if len(mentionedMobiles) > 0 {
+		text["mentioned_mobile_list"] = mentionedMobiles
+	}
 	body := map[string]interface{}{
 		"msgtype": "text",
-		"text": map[string]string{
-			"content": message,
-		},
+		"text":    text,
 	}
 	result, err := n.sendJSONRequest(ctx, webhook, body)
 	if err != nil {
-		return err
+		return "", err
 	}
 	var weComResult WeComResult
 	if err := json.Unmarshal(result, &weComResult); err != nil {
-		return err
+		return "", err
 	}
 	if weComResult.Errcode != 0 {
-		return fmt.Errorf("%s", weComResult.Errmsg)
+		return "", fmt.Errorf("%s", weComResult.Errmsg)
 	}
-	return nil
+	return weComResult.messageID(), nil
 }
 
-// sendFeishu 发送飞书通知
-func (n *Notifier) sendFeishu(ctx context.Context, webhook, message string) error {
+// sendFeishu 发送飞书通知，返回平台消息ID（如有）
+func (n *Notifier) sendFeishu(ctx context.Context, webhook, secret, message string) (string, error) {
 	body := map[string]interface{}{
 		"msg_type": "text",
 		"content": map[string]string{
 			"text": message,
 		},
 	}
+	return n.sendFeishuBody(ctx, webhook, secret, body)
+}
 
-	_, err := n.sendJSONRequest(ctx, webhook, body)
+// sendFeishuBody 发送已构建好的飞书消息体（文本、富文本、卡片等），返回消息ID
+// 配置了签名校验密钥时在消息体中附加 timestamp 和 sign，对所有消息类型生效
+func (n *Notifier) sendFeishuBody(ctx context.Context, webhook, secret string, body map[string]interface{}) (string, error) {
+	if secret != "" {
+		// 飞书签名使用秒级时间戳
+		timestamp := n.now() / 1000
+		body["timestamp"] = strconv.FormatInt(timestamp, 10)
+		body["sign"] = calculateFeishuSign(timestamp, secret)
+	}
+	result, err := n.sendJSONRequest(ctx, webhook, body)
 	if err != nil {
-		return err
+		return "", err
 	}
-	return nil
+	var feishuResult FeishuResult
+	if err := json.Unmarshal(result, &feishuResult); err != nil {
+		return "", err
+	}
+	if feishuResult.Code != 0 {
+		return "", fmt.Errorf("%s", feishuResult.Msg)
+	}
+	return feishuResult.Data.MessageId, nil
 }
 
 // sendCustomWebhook 发送自定义Webhook
-func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) error {
+// channelConfig 为空时（如测试消息）不参与批量发送
+func (n *Notifier) sendCustomWebhook(ctx context.Context, channelConfig *models.NotificationChannelConfig, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) error {
 	// 解析配置
-	webhookURL, ok := config["url"].(string)
-	if !ok || webhookURL == "" {
-		return fmt.Errorf("自定义Webhook配置缺少 url")
-	}
-
-	// 获取请求方法，默认 POST
-	method := "POST"
-	if m, ok := config["method"].(string); ok && m != "" {
-		method = strings.ToUpper(m)
-	}
-
-	// 获取自定义请求头
-	headers := make(map[string]string)
-	if h, ok := config["headers"].(map[string]interface{}); ok {
-		for k, v := range h {
-			if strVal, ok := v.(string); ok {
-				headers[k] = strVal
-			}
-		}
-	}
-
-	// 获取请求体模板类型，默认 json
-	bodyTemplate := "json"
-	if bt, ok := config["bodyTemplate"].(string); ok && bt != "" {
-		bodyTemplate = bt
+	cfg, err := ParseWebhookConfig(config)
+	if err != nil {
+		return err
 	}
 
 	// 构建消息内容
-	message := n.buildMessage(agent, record)
+	message := n.buildMessage(agent, record, n.messageOptions(ctx, config))
 
 	// 根据模板类型构建请求体
 	var reqBody io.Reader
 	var contentType string
 
-	switch bodyTemplate {
+	switch cfg.BodyTemplate {
 	case "json":
-		// JSON 格式
-		body := map[string]interface{}{
-			"msg_type": "text",
-			"text": map[string]string{
-				"content": message,
-			},
-			"agent": map[string]interface{}{
-				"id":       agent.ID,
-				"name":     agent.Name,
-				"hostname": agent.Hostname,
-				"ip":       agent.IP,
-			},
-			"alert": map[string]interface{}{
-				"type":        record.AlertType,
-				"level":       record.Level,
-				"status":      record.Status,
-				"message":     record.Message,
-				"threshold":   record.Threshold,
-				"actualValue": record.ActualValue,
-				"firedAt":     record.FiredAt,
-				"resolvedAt":  record.ResolvedAt,
-			},
+		// JSON 格式，按渠道固定的 schemaVersion 构建
+		version, err := parseWebhookSchemaVersion(config)
+		if err != nil {
+			return err
+		}
+		var body interface{} = buildWebhookPayload(version, message, agent, record)
+		switch {
+		case cfg.PayloadMode == "structured":
+			body = structuredWebhookPayload{Agent: agent, Record: record, Message: message}
+		case cfg.BodyFormat == "json":
+			// 原始告警记录，便于告警网关直接解析字段
+			body = record
 		}
+		// 批量模式：先缓存，按批次以 JSON 数组发送，发送结果在批次刷新时记录
+		// 演练模式直接渲染单条请求，重试时立即发送以获得本次结果
+		if batch := parseWebhookBatchConfig(config); batch.Enabled && channelConfig != nil && dryRunFrom(ctx) == nil && !directDeliveryFrom(ctx) {
+			// 按渠道分批：共用地址的不同渠道各自使用自己的请求头、签名和字符集
+			n.batcher.Add(channelLimiterKey(channelConfig)+"|"+cfg.URL, config, webhookBatchItem{
+				body:     body,
+				channel:  *channelConfig,
+				record:   record,
+				queuedAt: time.Now(),
+			}, batch)
+			return errWebhookQueued
+		}
+
 		data, err := json.Marshal(body)
 		if err != nil {
 			return fmt.Errorf("序列化 JSON 失败: %w", err)
@@ -274,18 +682,15 @@ func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]inte
 		if record.ResolvedAt > 0 {
 			formData.Set("resolved_at", fmt.Sprintf("%d", record.ResolvedAt))
 		}
+		for k, v := range record.Labels {
+			formData.Set("label_"+k, v)
+		}
 		reqBody = strings.NewReader(formData.Encode())
 		contentType = "application/x-www-form-urlencoded"
 
 	case "custom":
-		// 自定义模板，支持变量替换
-		customBody, ok := config["customBody"].(string)
-		if !ok || customBody == "" {
-			return fmt.Errorf("使用 custom 模板时必须提供 customBody")
-		}
-
-		// 使用 fasttemplate 进行变量替换
-		t := fasttemplate.New(customBody, "{{", "}}")
+		// 自定义模板，支持变量替换，使用 fasttemplate 进行变量替换
+		t := fasttemplate.New(cfg.CustomBody, "{{", "}}")
 		escape := func(s string) string {
 			b, _ := json.Marshal(s)
 			// json.Marshal 会返回带双引号的字符串，例如 "hello\nworld"
@@ -324,6 +729,11 @@ func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]inte
 			case "alert.resolvedAt":
 				v = fmt.Sprintf("%d", record.ResolvedAt)
 			default:
+				// 告警标签: {{alert.labels.<key>}}
+				if key, ok := strings.CutPrefix(tag, "alert.labels."); ok {
+					v = record.Labels[key]
+					break
+				}
 				return w.Write([]byte("{{" + tag + "}}"))
 			}
 
@@ -334,30 +744,59 @@ func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]inte
 		reqBody = strings.NewReader(bodyStr)
 		contentType = "text/plain"
 
-	default:
-		return fmt.Errorf("不支持的 bodyTemplate: %s", bodyTemplate)
+	}
+
+	return n.doWebhookRequest(ctx, cfg, reqBody, contentType)
+}
+
+// doWebhookRequest 按自定义Webhook配置（url、method、headers、charset、签名）发送请求体
+func (n *Notifier) doWebhookRequest(ctx context.Context, cfg models.WebhookConfig, reqBody io.Reader, contentType string) error {
+	// 按配置的字符集对请求体重新编码，默认 UTF-8 不做处理
+	if cfg.Charset != "" {
+		encoded, err := encodeCharset(reqBody, cfg.Charset)
+		if err != nil {
+			return err
+		}
+		reqBody = encoded
+		// 表单已由 url.Values.Encode 按 UTF-8 百分号编码，不声明字符集
+		if contentType != "application/x-www-form-urlencoded" {
+			contentType = contentType + "; charset=" + strings.ToLower(cfg.Charset)
+		}
+	}
+
+	// 配置了签名密钥时，对最终发送的请求体签名
+	var signature string
+	if cfg.SigningSecret != "" {
+		data, err := io.ReadAll(reqBody)
+		if err != nil {
+			return fmt.Errorf("读取请求体失败: %w", err)
+		}
+		signature, err = signWebhookBody(data, cfg.SigningSecret, cfg.SigningAlgorithm)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
 	}
 
 	// 创建请求
-	req, err := http.NewRequestWithContext(ctx, method, webhookURL, reqBody)
+	req, err := http.NewRequestWithContext(ctx, cfg.Method, cfg.URL, reqBody)
 	if err != nil {
 		return fmt.Errorf("创建请求失败: %w", err)
 	}
 
 	// 设置 Content-Type
 	req.Header.Set("Content-Type", contentType)
+	if signature != "" {
+		req.Header.Set(webhookSignatureHeader, signature)
+	}
 
 	// 设置自定义请求头
-	for k, v := range headers {
+	for k, v := range cfg.Headers {
 		req.Header.Set(k, v)
 	}
 
 	// 发送请求
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Do(req)
+	resp, err := n.doHTTP(req)
 	if err != nil {
 		return fmt.Errorf("发送请求失败: %w", err)
 	}
@@ -371,14 +810,57 @@ func (n *Notifier) sendCustomWebhook(ctx context.Context, config map[string]inte
 	}
 
 	n.logger.Info("自定义Webhook发送成功",
-		zap.String("url", webhookURL),
-		zap.String("method", method),
+		zap.String("url", redactRequestURL(req.URL)),
+		zap.String("method", cfg.Method),
 		zap.String("response", string(respBody)),
 	)
 
 	return nil
 }
 
+// charsetEncodings 支持的非 UTF-8 字符集
+var charsetEncodings = map[string]encoding.Encoding{
+	"gbk":     simplifiedchinese.GBK,
+	"gb18030": simplifiedchinese.GB18030,
+}
+
+// encodeCharset 将 UTF-8 请求体转换为指定字符集
+func encodeCharset(body io.Reader, charset string) (io.Reader, error) {
+	charset = strings.ToLower(charset)
+	if charset == "utf-8" || charset == "utf8" {
+		return body, nil
+	}
+	enc, ok := charsetEncodings[charset]
+	if !ok {
+		return nil, fmt.Errorf("不支持的字符集: %s", charset)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("读取请求体失败: %w", err)
+	}
+	encoded, err := enc.NewEncoder().Bytes(stripUnsupportedRunes(data, enc))
+	if err != nil {
+		return nil, fmt.Errorf("转换字符集 %s 失败: %w", charset, err)
+	}
+	return bytes.NewReader(encoded), nil
+}
+
+// stripUnsupportedRunes 删除目标字符集无法表示的字符（如消息标题中的 emoji），避免整个请求体转换失败
+// 不使用 encoding.ReplaceUnsupported，其替换字符为控制字符 0x1A，会使 JSON 请求体无效
+func stripUnsupportedRunes(data []byte, enc encoding.Encoding) []byte {
+	encoder := enc.NewEncoder()
+	result := make([]byte, 0, len(data))
+	for _, r := range string(data) {
+		if r >= utf8.RuneSelf {
+			if _, err := encoder.String(string(r)); err != nil {
+				continue
+			}
+		}
+		result = utf8.AppendRune(result, r)
+	}
+	return result
+}
+
 // sendJSONRequest 发送JSON请求
 func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interface{}) ([]byte, error) {
 	data, err := json.Marshal(body)
@@ -393,11 +875,7 @@ func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interfa
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	resp, err := client.Do(req)
+	resp, err := n.doHTTP(req)
 	if err != nil {
 		return nil, fmt.Errorf("发送请求失败: %w", err)
 	}
@@ -410,140 +888,888 @@ func (n *Notifier) sendJSONRequest(ctx context.Context, url string, body interfa
 		return nil, fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
 	}
 
-	n.logger.Info("通知发送成功", zap.String("url", url), zap.String("response", string(respBody)))
+	n.logger.Info("通知发送成功", zap.String("url", redactRequestURL(req.URL)), zap.String("response", string(respBody)))
 	return respBody, nil
 }
 
-// sendDingTalkByConfig 根据配置发送钉钉通知
+// sendGetRequest 发送GET请求
+func (n *Notifier) sendGetRequest(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("创建请求失败: %w", err)
+	}
+
+	resp, err := n.doHTTP(req)
+	if err != nil {
+		return nil, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+	}
+	return respBody, nil
+}
+
+// getWeComAccessToken 获取企业微信应用 access_token（带缓存）
+func (n *Notifier) getWeComAccessToken(ctx context.Context, corpID, corpSecret string) (string, error) {
+	if dryRunFrom(ctx) != nil {
+		return dryRunAccessToken, nil
+	}
+	return n.tokens.Get(ctx, "wecom:"+corpID+":"+corpSecret, func(ctx context.Context) (string, time.Duration, error) {
+		tokenURL := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=%s&corpsecret=%s",
+			url.QueryEscape(corpID), url.QueryEscape(corpSecret))
+		respBody, err := n.sendGetRequest(ctx, tokenURL)
+		if err != nil {
+			return "", 0, err
+		}
+		var result struct {
+			Errcode     int    `json:"errcode"`
+			Errmsg      string `json:"errmsg"`
+			AccessToken string `json:"access_token"`
+			ExpiresIn   int    `json:"expires_in"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return "", 0, err
+		}
+		if result.Errcode != 0 {
+			return "", 0, fmt.Errorf("获取企业微信 access_token 失败: %s", result.Errmsg)
+		}
+		return result.AccessToken, time.Duration(result.ExpiresIn) * time.Second, nil
+	})
+}
+
+// getFeishuTenantAccessToken 获取飞书自建应用 tenant_access_token（带缓存）
+func (n *Notifier) getFeishuTenantAccessToken(ctx context.Context, appID, appSecret string) (string, error) {
+	if dryRunFrom(ctx) != nil {
+		return dryRunAccessToken, nil
+	}
+	return n.tokens.Get(ctx, "feishu:"+appID+":"+appSecret, func(ctx context.Context) (string, time.Duration, error) {
+		respBody, err := n.sendJSONRequest(ctx, "https://open.feishu.cn/open-apis/auth/v3/tenant_access_token/internal", map[string]string{
+			"app_id":     appID,
+			"app_secret": appSecret,
+		})
+		if err != nil {
+			return "", 0, err
+		}
+		var result struct {
+			Code              int    `json:"code"`
+			Msg               string `json:"msg"`
+			TenantAccessToken string `json:"tenant_access_token"`
+			Expire            int    `json:"expire"`
+		}
+		if err := json.Unmarshal(respBody, &result); err != nil {
+			return "", 0, err
+		}
+		if result.Code != 0 {
+			return "", 0, fmt.Errorf("获取飞书 tenant_access_token 失败: %s", result.Msg)
+		}
+		return result.TenantAccessToken, time.Duration(result.Expire) * time.Second, nil
+	})
+}
+
+const (
+	// weComTextByteLimit 企业微信文本消息内容最大字节数
+	weComTextByteLimit = 2048
+	// feishuTextByteLimit 飞书文本消息内容最大字节数（请求体上限 20KB，预留消息体结构的空间）
+	feishuTextByteLimit = 18 * 1024
+)
+
+// agentDetailURL 构造探针详情页地址
+func agentDetailURL(baseURL, agentID string) string {
+	if baseURL == "" || agentID == "" {
+		return ""
+	}
+	return strings.TrimRight(baseURL, "/") + "/servers/" + url.PathEscape(agentID)
+}
+
+// channelBaseURL 读取渠道配置中的详情页基础地址 baseUrl，兼容钉钉、ntfy、Bark 早期使用的 dashboardUrl
+func channelBaseURL(config map[string]interface{}) string {
+	if baseURL, _ := config["baseUrl"].(string); baseURL != "" {
+		return baseURL
+	}
+	baseURL, _ := config["dashboardUrl"].(string)
+	return baseURL
+}
+
+// channelDetailURL 根据渠道配置中的 baseUrl 构造探针详情页地址，未配置时返回空
+func channelDetailURL(config map[string]interface{}, agent *models.Agent) string {
+	if agent == nil {
+		return ""
+	}
+	return agentDetailURL(channelBaseURL(config), agent.ID)
+}
+
+// truncateMessage 按字节上限截断消息，保证不截断多字节字符，并在末尾附上完整信息的链接
+func truncateMessage(message string, limit int, detailURL string) string {
+	if len(message) <= limit {
+		return message
+	}
+
+	suffix := "\n...（消息过长已截断）"
+	if detailURL != "" {
+		suffix = "\n...\n查看完整信息: " + detailURL
+	}
+
+	cut := limit - len(suffix)
+	if cut < 0 {
+		cut = 0
+	}
+	for cut > 0 && !utf8.RuneStart(message[cut]) {
+		cut--
+	}
+	return message[:cut] + suffix
+}
+
+// sendDingTalkByConfig 根据配置发送钉钉文本通知
 func (n *Notifier) sendDingTalkByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	secretKey, ok := config["secretKey"].(string)
-	if !ok || secretKey == "" {
-		return fmt.Errorf("钉钉配置缺少 secretKey")
+	cfg, err := ParseDingTalkConfig(config)
+	if err != nil {
+		return err
 	}
+	return n.sendDingTalkToRobots(ctx, cfg, dingTalkTextBody(message))
+}
 
-	// 构造 Webhook URL
-	webhook := fmt.Sprintf("https://oapi.dingtalk.com/robot/send?access_token=%s", secretKey)
+// sendDingTalkToRobots 将消息体发送到配置中的所有群机器人
+func (n *Notifier) sendDingTalkToRobots(ctx context.Context, cfg DingTalkConfig, body map[string]interface{}) error {
+	robots := cfg.Robots
 
-	// 检查是否有加签密钥
-	signSecret, _ := config["signSecret"].(string)
+	// 单个机器人保持原有的错误信息
+	if len(robots) == 1 {
+		webhook := fmt.Sprintf("https://oapi.dingtalk.com/robot/send?access_token=%s", robots[0].SecretKey)
+		return n.sendDingTalkBody(ctx, webhook, robots[0].SignSecret, body)
+	}
 
-	return n.sendDingTalk(ctx, webhook, signSecret, message)
+	results := make([]RecipientResult, 0, len(robots))
+	for _, r := range robots {
+		// 构造 Webhook URL
+		webhook := fmt.Sprintf("https://oapi.dingtalk.com/robot/send?access_token=%s", r.SecretKey)
+		result := RecipientResult{Recipient: maskToken(r.SecretKey), Success: true}
+		if err := n.sendDingTalkBody(ctx, webhook, r.SignSecret, body); err != nil {
+			result.Success = false
+			// 网络错误中包含带 access_token 的完整地址，需脱敏后再记录
+			result.Error = deliveryErrorMessage(err)
+			n.logger.Warn("钉钉群机器人发送失败", zap.String("recipient", result.Recipient), zap.String("error", result.Error))
+		}
+		results = append(results, result)
+	}
+	return recipientResultsError(results)
 }
 
-// sendWeComByConfig 根据配置发送企业微信通知
-func (n *Notifier) sendWeComByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	secretKey, ok := config["secretKey"].(string)
-	if !ok || secretKey == "" {
-		return fmt.Errorf("企业微信配置缺少 secretKey")
+// sendWeComAppChat 通过企业微信应用发送到指定群聊（appchat/send）
+func (n *Notifier) sendWeComAppChat(ctx context.Context, corpID, corpSecret, chatID, message string) (string, error) {
+	accessToken, err := n.getWeComAccessToken(ctx, corpID, corpSecret)
+	if err != nil {
+		return "", err
+	}
+
+	body := map[string]interface{}{
+		"chatid":  chatID,
+		"msgtype": "text",
+		"text": map[string]string{
+			"content": message,
+		},
+	}
+	sendURL := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/appchat/send?access_token=%s", url.QueryEscape(accessToken))
+	result, err := n.sendJSONRequest(ctx, sendURL, body)
+	if err != nil {
+		return "", err
+	}
+	var weComResult WeComResult
+	if err := json.Unmarshal(result, &weComResult); err != nil {
+		return "", err
+	}
+	switch weComResult.Errcode {
+	case 0:
+		return weComResult.messageID(), nil
+	case 40014, 42001:
+		// access_token 无效或已过期，清除缓存以便下次重新获取
+		n.tokens.Invalidate("wecom:" + corpID + ":" + corpSecret)
+	}
+	return "", fmt.Errorf("%s", weComResult.Errmsg)
+}
+
+// sendWeComByConfig 根据配置发送企业微信通知，返回平台消息ID（如有）
+func (n *Notifier) sendWeComByConfig(ctx context.Context, config map[string]interface{}, message string) (string, error) {
+	cfg, err := ParseWeComConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	// appchat 模式：使用企业凭证发送到指定群聊
+	if cfg.Mode == "appchat" {
+		return n.sendWeComAppChat(ctx, cfg.CorpID, cfg.CorpSecret, cfg.ChatID, message)
 	}
 
 	// 构造 Webhook URL
-	webhook := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=%s", secretKey)
+	webhook := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=%s", cfg.SecretKey)
 
 	return n.sendWeCom(ctx, webhook, message)
 }
 
-// sendFeishuByConfig 根据配置发送飞书通知
-func (n *Notifier) sendFeishuByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	secretKey, ok := config["secretKey"].(string)
-	if !ok || secretKey == "" {
-		return fmt.Errorf("飞书配置缺少 secretKey")
+// sendWeComAlert 发送企业微信告警通知，群机器人模式下按配置 @ 成员
+func (n *Notifier) sendWeComAlert(ctx context.Context, config map[string]interface{}, record *models.AlertRecord, message string) (string, error) {
+	cfg, err := ParseWeComConfig(config)
+	if err != nil {
+		return "", err
+	}
+	if cfg.Mode == "appchat" || !cfg.Mention.Active(record) {
+		return n.sendWeComByConfig(ctx, config, message)
 	}
 
-	// 构造 Webhook URL
-	webhook := fmt.Sprintf("https://open.feishu.cn/open-apis/bot/v2/hook/%s", secretKey)
+	mobiles := cfg.Mention.Mobiles
+	if cfg.Mention.All {
+		mobiles = append(append([]string{}, mobiles...), "@all")
+	}
+	webhook := fmt.Sprintf("https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=%s", cfg.SecretKey)
+	return n.sendWeComText(ctx, webhook, message, mobiles)
+}
+
+// sendFeishuByConfig 根据配置发送飞书通知，返回平台消息ID（如有）
+func (n *Notifier) sendFeishuByConfig(ctx context.Context, config map[string]interface{}, message string) (string, error) {
+	cfg, err := ParseFeishuConfig(config)
+	if err != nil {
+		return "", err
+	}
+	return n.sendFeishu(ctx, cfg.webhookURL(), cfg.SignSecret, message)
+}
+
+// webhookURL 飞书机器人 Webhook URL
+func (cfg FeishuConfig) webhookURL() string {
+	return fmt.Sprintf("https://open.feishu.cn/open-apis/bot/v2/hook/%s", cfg.SecretKey)
+}
+
+// sendFeishuPost 以飞书富文本（post）格式发送告警，每个字段一行，字段名加粗并附详情链接
+// 无法构建富文本或平台拒绝时回退为文本消息
+func (n *Notifier) sendFeishuPost(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) (string, error) {
+	opts := n.messageOptions(ctx, config)
+	content, ok := n.buildMessageContent(agent, record, opts)
+	if ok {
+		cfg, err := ParseFeishuConfig(config)
+		if err != nil {
+			return "", err
+		}
+		messageID, err := n.sendFeishuBody(ctx, cfg.webhookURL(), cfg.SignSecret, renderFeishuPost(content, channelDetailURL(config, agent), opts))
+		if err == nil {
+			return messageID, nil
+		}
+		n.logger.Warn("飞书富文本消息发送失败，回退为文本消息", zap.String("error", deliveryErrorMessage(err)))
+	}
+	return n.sendFeishuByConfig(ctx, config, truncateMessage(message, feishuTextByteLimit, channelDetailURL(config, agent)))
+}
 
-	return n.sendFeishu(ctx, webhook, message)
+// sendFeishuCard 以飞书消息卡片格式发送告警：标题栏按告警级别着色，字段两列排列，配置 baseUrl 时附详情按钮
+// 配置了自定义模板、无法构建卡片或平台拒绝时回退为文本消息
+func (n *Notifier) sendFeishuCard(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) (string, error) {
+	opts := n.messageOptions(ctx, config)
+	content, ok := n.buildMessageContent(agent, record, opts)
+	if ok && !opts.hasMessageTemplate(record.Status) {
+		cfg, err := ParseFeishuConfig(config)
+		if err != nil {
+			return "", err
+		}
+		messageID, err := n.sendFeishuBody(ctx, cfg.webhookURL(), cfg.SignSecret, renderFeishuCard(content, record, channelDetailURL(config, agent), opts))
+		if err == nil {
+			return messageID, nil
+		}
+		n.logger.Warn("飞书卡片消息发送失败，回退为文本消息", zap.String("error", deliveryErrorMessage(err)))
+	}
+	return n.sendFeishuByConfig(ctx, config, truncateMessage(message, feishuTextByteLimit, channelDetailURL(config, agent)))
+}
+
+// sendEmailByConfig 根据配置发送邮件通知，同时包含纯文本和按告警级别着色的 HTML 正文
+func (n *Notifier) sendEmailByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseEmailConfig(config)
+	if err != nil {
+		return err
+	}
+
+	opts := n.messageOptions(ctx, config)
+	icon := opts.levelIcon(record.Level)
+	alertTypeName := alertTypeDisplayName(record.AlertType)
+	if alertTypeName == "" {
+		alertTypeName = record.AlertType
+	}
+	subject := buildEmailSubject(icon, alertTypeName, record.Status)
+
+	return n.sendEmail(ctx, cfg, subject, message, n.buildEmailHTML(agent, record, opts, message))
+}
+
+// sendWebhookRaw 通过自定义Webhook发送纯文本消息，请求体按 bodyTemplate 构造，仅包含消息内容
+func (n *Notifier) sendWebhookRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseWebhookConfig(config)
+	if err != nil {
+		return err
+	}
+
+	switch cfg.BodyTemplate {
+	case "json":
+		data, err := json.Marshal(map[string]interface{}{
+			"msg_type": "text",
+			"text": map[string]string{
+				"content": message,
+			},
+		})
+		if err != nil {
+			return fmt.Errorf("序列化 JSON 失败: %w", err)
+		}
+		return n.doWebhookRequest(ctx, cfg, bytes.NewReader(data), "application/json")
+	case "form":
+		formData := url.Values{}
+		formData.Set("message", message)
+		return n.doWebhookRequest(ctx, cfg, strings.NewReader(formData.Encode()), "application/x-www-form-urlencoded")
+	default:
+		escaped, _ := json.Marshal(message)
+		body := strings.ReplaceAll(cfg.CustomBody, "{{message}}", string(escaped[1:len(escaped)-1]))
+		return n.doWebhookRequest(ctx, cfg, strings.NewReader(body), "text/plain")
+	}
 }
 
 // sendWebhookByConfig 根据配置发送自定义Webhook
-func (n *Notifier) sendWebhookByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) error {
-	return n.sendCustomWebhook(ctx, config, agent, record)
+func (n *Notifier) sendWebhookByConfig(ctx context.Context, channelConfig *models.NotificationChannelConfig, agent *models.Agent, record *models.AlertRecord) error {
+	return n.sendCustomWebhook(ctx, channelConfig, channelConfig.Config, agent, record)
 }
 
+// withDefaultLevel 告警记录未设置级别时，按告警类型补全默认级别
+// 返回副本，不修改调用方持有的记录
+func (n *Notifier) withDefaultLevel(ctx context.Context, record *models.AlertRecord) *models.AlertRecord {
+	if record.Level != "" {
+		return record
+	}
+
+	var alertConfig *models.AlertConfig
+	if n.propertyService != nil {
+		config, err := n.propertyService.GetAlertConfig(ctx)
+		if err != nil {
+			n.logger.Warn("获取告警配置失败，使用内置默认级别", zap.Error(err))
+		} else {
+			alertConfig = config
+		}
+	}
+
+	level := alertConfig.DefaultLevel(record.AlertType)
+	if level == "" {
+		return record
+	}
+	copied := *record
+	copied.Level = level
+	return &copied
+}
+
+// ignoreQueued 加入批次等待发送不视为错误，用于不关心批次发送结果的调用方
+func ignoreQueued(err error) error {
+	if errors.Is(err, errWebhookQueued) {
+		return nil
+	}
+	return err
+}
+
+// errRateLimited 超出渠道发送频率限制，通知被丢弃；既不是发送成功，也不是需要重试的失败
+var errRateLimited = errors.New("超出渠道发送频率限制，通知已丢弃")
+
 // SendNotificationByConfig 根据新的配置结构发送通知
+// 超出发送频率限制时返回 errRateLimited，自定义Webhook加入批次等待发送时返回 errWebhookQueued
 func (n *Notifier) SendNotificationByConfig(ctx context.Context, channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent) error {
 	if !channelConfig.Enabled {
 		return fmt.Errorf("通知渠道已禁用")
 	}
 
+	record = n.withDefaultLevel(ctx, record)
+	dryRun := dryRunFrom(ctx) != nil
+
+	suppressed := 0
+	if rate, err := parseRatePerMinute(channelConfig.Config); err != nil {
+		n.logger.Warn("渠道发送频率配置无效，忽略", zap.String("channelType", channelConfig.Type), zap.Error(err))
+	} else if rate > 0 && !dryRun {
+		// 演练模式不消耗发送配额
+		var allowed bool
+		if allowed, suppressed = n.rateLimiter.Allow(rateLimitKey(channelConfig, record), rate, time.Now()); !allowed {
+			n.logger.Info("超出渠道发送频率限制，丢弃通知",
+				zap.String("channelType", channelConfig.Type),
+				zap.Int64("recordId", record.ID),
+			)
+			return errRateLimited
+		}
+	}
+
 	n.logger.Info("发送通知",
 		zap.String("channelType", channelConfig.Type),
 	)
 
 	// 构造通知消息内容
-	message := n.buildMessage(agent, record)
-
-	switch channelConfig.Type {
-	case "dingtalk":
-		return n.sendDingTalkByConfig(ctx, channelConfig.Config, message)
-	case "wecom":
-		return n.sendWeComByConfig(ctx, channelConfig.Config, message)
-	case "feishu":
-		return n.sendFeishuByConfig(ctx, channelConfig.Config, message)
-	case "webhook":
-		return n.sendWebhookByConfig(ctx, channelConfig.Config, agent, record)
-	case "email":
-		// TODO: 实现邮件通知
-		return fmt.Errorf("邮件通知暂未实现")
-	default:
-		return fmt.Errorf("不支持的通知渠道类型: %s", channelConfig.Type)
+	opts := n.messageOptions(ctx, channelConfig.Config)
+	message := n.buildMessage(agent, record, opts)
+	if suppressed > 0 {
+		message += "\n\n" + opts.textf("note.suppressed", suppressed)
+	}
+
+	def, ok := lookupChannel(channelConfig.Type)
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnsupportedChannelType, channelConfig.Type)
+	}
+	release, err := n.limiter.acquire(ctx, channelConfig)
+	if err != nil {
+		return err
+	}
+	ctx = WithChannelHTTPOptions(ctx, channelConfig.Config)
+	startedAt := time.Now()
+	err = def.send(n, ctx, channelConfig, record, agent, message)
+	release()
+	if dryRun {
+		if errors.Is(err, errDryRun) {
+			return nil
+		}
+		return err
 	}
+	// 已加入批次，发送结果在批次刷新时记录
+	if errors.Is(err, errWebhookQueued) {
+		return err
+	}
+	n.stats.Record(channelConfig.ID, channelConfig.Type, channelConfig.Name, err == nil, time.Now())
+	n.recordDeliveryAttempt(channelConfig, record, startedAt, err)
+	return err
 }
 
-// SendNotificationByConfigs 根据新的配置结构向多个渠道发送通知
-func (n *Notifier) SendNotificationByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent) error {
-	var errs []error
+// recordDeliveryAttempt 通过回调记录一次发送结果
+func (n *Notifier) recordDeliveryAttempt(channelConfig *models.NotificationChannelConfig, record *models.AlertRecord, startedAt time.Time, err error) {
+	if n.onDeliveryAttempt == nil {
+		return
+	}
+	log := &models.NotificationLog{
+		Timestamp:     startedAt.UnixMilli(),
+		ChannelID:     channelConfig.ID,
+		ChannelType:   channelConfig.Type,
+		AlertRecordID: record.ID,
+		AgentID:       record.AgentID,
+		Success:       err == nil,
+		DurationMs:    time.Since(startedAt).Milliseconds(),
+	}
+	if err != nil {
+		log.ErrorMessage = deliveryErrorMessage(err)
+	}
+	n.onDeliveryAttempt(log)
+}
 
-	for _, channelConfig := range channelConfigs {
-		if err := n.SendNotificationByConfig(ctx, &channelConfig, record, agent); err != nil {
-			n.logger.Error("发送通知失败",
+// deliveryErrorMessage 返回可持久化的失败原因
+// 网络错误中的请求地址只保留协议和主机，避免钉钉 access_token、企业微信 key、Telegram bot token 等写入数据库
+func deliveryErrorMessage(err error) string {
+	message := err.Error()
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || urlErr.URL == "" {
+		return message
+	}
+	host := "<redacted>"
+	if u, parseErr := url.Parse(urlErr.URL); parseErr == nil && u.Host != "" {
+		host = u.Scheme + "://" + u.Host
+	}
+	message = strings.ReplaceAll(message, strconv.Quote(urlErr.URL), strconv.Quote(host))
+	return strings.ReplaceAll(message, urlErr.URL, host)
+}
+
+// SendRawByConfig 将已构建好的消息原样发送到指定渠道，不经过 buildMessage，返回平台消息ID（如有）
+// 用于维护公告、测试通知等非告警类通知复用渠道配置
+func (n *Notifier) SendRawByConfig(ctx context.Context, channelConfig *models.NotificationChannelConfig, message string) (string, error) {
+	if !channelConfig.Enabled {
+		return "", fmt.Errorf("通知渠道已禁用")
+	}
+	def, ok := lookupChannel(channelConfig.Type)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedChannelType, channelConfig.Type)
+	}
+	release, err := n.limiter.acquire(ctx, channelConfig)
+	if err != nil {
+		return "", err
+	}
+	defer release()
+	return def.sendRaw(n, WithChannelHTTPOptions(ctx, channelConfig.Config), channelConfig.Config, message)
+}
+
+// logDelivery 记录平台返回的消息ID，便于与平台侧投递日志对照
+func (n *Notifier) logDelivery(channelType string, record *models.AlertRecord, messageID string, err error) {
+	if err != nil || messageID == "" {
+		return
+	}
+	n.logger.Info("通知已投递",
+		zap.String("channelType", channelType),
+		zap.Int64("recordId", record.ID),
+		zap.String("messageId", messageID),
+	)
+}
+
+// notifyDeliveryFailure 在某个渠道重试次数用尽、最终放弃投递后，通过备用渠道发送一条“投递失败”的元通知
+func (n *Notifier) notifyDeliveryFailure(ctx context.Context, fallbacks []models.NotificationChannelConfig, failedChannel *models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, sendErr error) {
+	if len(fallbacks) == 0 {
+		n.logger.Error("通知最终投递失败，且未配置备用渠道",
+			zap.Int64("recordId", record.ID),
+			zap.String("channelType", failedChannel.Type),
+			zap.String("error", deliveryErrorMessage(sendErr)),
+		)
+		return
+	}
+
+	channelName := failedChannel.Name
+	if channelName == "" {
+		channelName = failedChannel.Type
+	}
+	now := time.Now().UnixMilli()
+	meta := &models.AlertRecord{
+		AgentID:   record.AgentID,
+		AgentName: record.AgentName,
+		AlertType: "notification_failed",
+		// 失败原因会发送到其他渠道，需去除其中带密钥的请求地址
+		Message: fmt.Sprintf("告警 #%d (%s) 投递到通知渠道 %s 失败: %s",
+			record.ID, record.AlertType, channelName, deliveryErrorMessage(sendErr)),
+		Level:     "critical",
+		Status:    "firing",
+		FiredAt:   now,
+		CreatedAt: now,
+	}
+
+	for _, channelConfig := range fallbacks {
+		if err := ignoreQueued(n.SendNotificationByConfig(ctx, &channelConfig, meta, agent)); err != nil {
+			n.logger.Error("发送投递失败通知失败",
 				zap.String("channelType", channelConfig.Type),
-				zap.Error(err),
+				zap.String("error", deliveryErrorMessage(err)),
 			)
-			errs = append(errs, err)
 		}
 	}
+}
 
-	if len(errs) > 0 {
-		return fmt.Errorf("部分通知发送失败: %v", errs)
+// notifyOnResolve 渠道是否发送指定级别告警的恢复通知，默认发送
+// 配置格式: "notifyOnResolve": false 关闭所有恢复通知，或 "notifyOnResolve": {"info": false} 按级别关闭
+func notifyOnResolve(config map[string]interface{}, level string) bool {
+	switch v := config["notifyOnResolve"].(type) {
+	case bool:
+		return v
+	case map[string]interface{}:
+		if enabled, ok := v[level].(bool); ok {
+			return enabled
+		}
 	}
+	return true
+}
 
-	return nil
+// metricAlertTypes 基于探针上报指标的告警类型，探针离线后因无数据而自动恢复
+var metricAlertTypes = map[string]bool{
+	"cpu":     true,
+	"memory":  true,
+	"disk":    true,
+	"network": true,
+}
+
+// suppressOfflineResolve 渠道开启 "suppressResolvedWhenOffline" 时，探针离线导致的指标告警恢复不发送通知
+// 此时指标并未真正恢复，逐条发送“已恢复”会误导值班人员
+func suppressOfflineResolve(config map[string]interface{}, record *models.AlertRecord, agent *models.Agent) bool {
+	if record.Status != "resolved" || agent == nil || agent.Status != 0 || !metricAlertTypes[record.AlertType] {
+		return false
+	}
+	suppress, _ := config["suppressResolvedWhenOffline"].(bool)
+	return suppress
+}
+
+// deliveryFailed 通知渠道发送失败，交给重试队列稍后重新发送
+func (n *Notifier) deliveryFailed(channelConfig models.NotificationChannelConfig, record *models.AlertRecord, err error) {
+	if n.onDeliveryFailed != nil {
+		n.onDeliveryFailed(channelConfig, record, err)
+	}
+}
+
+// isMutedAlertType 告警类型是否在系统配置中被全局静音
+func (n *Notifier) isMutedAlertType(ctx context.Context, alertType string) bool {
+	if n.propertyService == nil {
+		return false
+	}
+	systemConfig, err := n.propertyService.GetSystemConfig(ctx)
+	if err != nil {
+		return false
+	}
+	return slices.Contains(systemConfig.MutedAlertTypes, alertType)
+}
+
+// isSnoozed 告警是否处于暂停通知期内
+func (n *Notifier) isSnoozed(ctx context.Context, record *models.AlertRecord) bool {
+	if n.propertyService == nil {
+		return false
+	}
+	snoozes, err := n.propertyService.GetAlertSnoozes(ctx)
+	if err != nil {
+		n.logger.Warn("获取告警暂停列表失败", zap.Error(err))
+		return false
+	}
+	now := time.Now().UnixMilli()
+	for _, snooze := range snoozes {
+		if snooze.AgentID == record.AgentID && snooze.AlertType == record.AlertType && snooze.Active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// ChannelResult 单个渠道的发送结果
+type ChannelResult struct {
+	ChannelID  string `json:"channelId,omitempty"`  // 渠道ID
+	Name       string `json:"name,omitempty"`       // 渠道名称
+	Type       string `json:"type"`                 // 渠道类型
+	Fallback   bool   `json:"fallback,omitempty"`   // 是否为备用渠道
+	Success    bool   `json:"success"`              // 是否发送成功
+	Suppressed bool   `json:"suppressed,omitempty"` // 是否因超出发送频率限制被丢弃（未发送）
+	Queued     bool   `json:"queued,omitempty"`     // 是否已加入批次等待发送（结果在批次发送后记录）
+	Error      string `json:"error,omitempty"`      // 失败原因
+	DurationMs int64  `json:"durationMs"`           // 发送耗时（毫秒）
+	// 演练模式下渲染出的请求
+	Requests []RenderedRequest `json:"requests,omitempty"`
+}
+
+// SendNotificationByConfigs 根据新的配置结构向多个渠道发送通知
+// 先发送主渠道，只有当所有主渠道都发送失败时才升级发送到备用渠道
+// 返回实际发送的每个渠道的结果，任一渠道失败时同时返回汇总的错误
+func (n *Notifier) SendNotificationByConfigs(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent) ([]ChannelResult, error) {
+	if n.skipRecord(ctx, record) {
+		return nil, nil
+	}
+	results, err := n.sendToChannels(ctx, channelConfigs, n.withDefaultLevel(ctx, record), agent)
+	if !anyDelivered(results) {
+		n.dedup.Release(record)
+	}
+	return results, err
 }
 
-// SendDingTalkByConfig 导出方法供外部调用
-func (n *Notifier) SendDingTalkByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	return n.sendDingTalkByConfig(ctx, config, message)
+// anyDelivered 是否至少有一个渠道发送成功或已加入批次等待发送
+// 批次发送失败时由 flushWebhookBatch 撤销去重记录
+func anyDelivered(results []ChannelResult) bool {
+	for _, result := range results {
+		if result.Success || result.Queued {
+			return true
+		}
+	}
+	return false
 }
 
-// SendWeComByConfig 导出方法供外部调用
-func (n *Notifier) SendWeComByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	return n.sendWeComByConfig(ctx, config, message)
+// releaseUndelivered 撤销没有任何渠道发送成功的告警的去重记录
+func (n *Notifier) releaseUndelivered(records []*models.AlertRecord, delivered map[*models.AlertRecord]bool) {
+	for _, record := range records {
+		if !delivered[record] {
+			n.dedup.Release(record)
+		}
+	}
 }
 
-// SendFeishuByConfig 导出方法供外部调用
-func (n *Notifier) SendFeishuByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	return n.sendFeishuByConfig(ctx, config, message)
+// skipRecord 全局静音、处于暂停通知期、命中静默规则或去重窗口内已发送过的告警不再发送
+// 未跳过的告警会记入去重窗口，所有渠道均发送失败时由调用方通过 dedup.Release 撤销
+func (n *Notifier) skipRecord(ctx context.Context, record *models.AlertRecord) bool {
+	if n.suppressedRecord(ctx, record) {
+		return true
+	}
+
+	if n.dedup.Duplicate(record, time.Now()) {
+		n.logger.Info("去重窗口内已发送相同告警，跳过发送",
+			zap.Int64("recordId", record.ID),
+			zap.String("agentId", record.AgentID),
+			zap.String("alertType", record.AlertType),
+			zap.String("status", record.Status),
+		)
+		return true
+	}
+	return false
+}
+
+// suppressedRecord 告警是否被全局静音、暂停通知或静默规则屏蔽
+func (n *Notifier) suppressedRecord(ctx context.Context, record *models.AlertRecord) bool {
+	if n.isMutedAlertType(ctx, record.AlertType) {
+		n.logger.Info("告警类型已全局静音，跳过发送",
+			zap.Int64("recordId", record.ID),
+			zap.String("alertType", record.AlertType),
+		)
+		return true
+	}
+
+	if n.isSnoozed(ctx, record) {
+		n.logger.Info("告警处于暂停通知期内，跳过发送",
+			zap.Int64("recordId", record.ID),
+			zap.String("agentId", record.AgentID),
+			zap.String("alertType", record.AlertType),
+		)
+		return true
+	}
+
+	if rule := n.silencedBy(ctx, record); rule != nil {
+		n.logger.Info("告警命中静默规则，跳过发送",
+			zap.Int64("recordId", record.ID),
+			zap.String("agentId", record.AgentID),
+			zap.String("alertType", record.AlertType),
+			zap.String("silenceRuleId", rule.ID),
+		)
+		return true
+	}
+	return false
 }
 
-// SendWebhookByConfig 导出方法供外部调用（测试用）
-func (n *Notifier) SendWebhookByConfig(ctx context.Context, config map[string]interface{}, message string) error {
-	// 为了测试，创建一个临时的 agent 和 record
-	agent := &models.Agent{
-		ID:       "test-agent",
-		Name:     "测试探针",
-		Hostname: "test-host",
-		IP:       "127.0.0.1",
+// channelAccepts 渠道是否立即接收该告警，生效时间段之外的告警暂缓到下一次生效时发送
+func (n *Notifier) channelAccepts(channelConfig models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent, now time.Time) bool {
+	// 仅测试渠道不接收真实告警
+	if channelConfig.TestOnly {
+		n.logger.Debug("跳过仅测试通知渠道", zap.String("channelType", channelConfig.Type))
+		return false
+	}
+	// 每日摘要渠道不接收实时告警
+	if isDigestChannel(channelConfig.Config) {
+		n.logger.Debug("跳过每日摘要通知渠道", zap.String("channelType", channelConfig.Type))
+		return false
 	}
-	record := &models.AlertRecord{
-		AlertType:   "test",
-		Level:       "info",
-		Status:      "firing",
-		Message:     message,
-		Threshold:   0,
-		ActualValue: 0,
-		FiredAt:     time.Now().UnixMilli(),
+	if route, err := parseChannelRoute(channelConfig.Config); err != nil {
+		n.logger.Warn("渠道告警路由配置无效，忽略", zap.String("channelType", channelConfig.Type), zap.Error(err))
+	} else if !route.Match(record) {
+		n.logger.Debug("告警不满足渠道的路由条件，跳过",
+			zap.String("channelType", channelConfig.Type),
+			zap.String("alertType", record.AlertType),
+			zap.String("level", record.Level),
+		)
+		return false
 	}
-	return n.sendWebhookByConfig(ctx, config, agent, record)
+	if suppressOfflineResolve(channelConfig.Config, record, agent) {
+		n.logger.Debug("探针已离线，跳过指标告警的恢复通知",
+			zap.String("channelType", channelConfig.Type),
+			zap.String("agentId", record.AgentID),
+			zap.String("alertType", record.AlertType),
+		)
+		return false
+	}
+	if record.Status == "resolved" && !notifyOnResolve(channelConfig.Config, record.Level) {
+		n.logger.Debug("渠道未开启该级别的恢复通知，跳过",
+			zap.String("channelType", channelConfig.Type),
+			zap.String("level", record.Level),
+		)
+		return false
+	}
+	if schedule, err := parseChannelSchedule(channelConfig.Config); err != nil {
+		n.logger.Warn("渠道生效时间配置无效，忽略", zap.String("channelType", channelConfig.Type), zap.Error(err))
+	} else if schedule != nil && !schedule.Active(now) {
+		n.holdNotification(channelConfig, record, agent, schedule.NextActive(now))
+		return false
+	}
+	return true
+}
+
+// sendToChannels 将一条告警发送到多个渠道，先发送主渠道，所有主渠道均失败时升级到备用渠道
+func (n *Notifier) sendToChannels(ctx context.Context, channelConfigs []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent) ([]ChannelResult, error) {
+	var errs []error
+
+	now := time.Now()
+	var primaries, fallbacks []models.NotificationChannelConfig
+	for _, channelConfig := range channelConfigs {
+		if !n.channelAccepts(channelConfig, record, agent, now) {
+			continue
+		}
+		if channelConfig.Fallback {
+			fallbacks = append(fallbacks, channelConfig)
+		} else {
+			primaries = append(primaries, channelConfig)
+		}
+	}
+
+	// 按优先级从高到低排序，同优先级保持原有顺序（错误和结果按此顺序返回）
+	byPriority := func(channels []models.NotificationChannelConfig) {
+		sort.SliceStable(channels, func(i, j int) bool {
+			return channels[i].Priority > channels[j].Priority
+		})
+	}
+	byPriority(primaries)
+	byPriority(fallbacks)
+
+	var failedChannels []models.NotificationChannelConfig
+	results, sendErrs := n.sendConcurrently(ctx, primaries, record, agent)
+	for i, err := range sendErrs {
+		if err == nil {
+			continue
+		}
+		channelConfig := primaries[i]
+		n.logger.Error("发送通知失败",
+			zap.String("channelType", channelConfig.Type),
+			zap.String("channel", channelConfig.DisplayName()),
+			zap.String("error", deliveryErrorMessage(err)),
+		)
+		errs = append(errs, channelError(channelConfig, err))
+		n.deliveryFailed(channelConfig, record, err)
+		failedChannels = append(failedChannels, channelConfig)
+	}
+	failed := len(failedChannels)
+
+	// 所有主渠道均失败，升级到备用渠道
+	if len(primaries) > 0 && failed == len(primaries) && len(fallbacks) > 0 {
+		n.logger.Warn("所有主通知渠道发送失败，升级到备用渠道",
+			zap.Int64("recordId", record.ID),
+			zap.Int("primaryCount", len(primaries)),
+			zap.Int("fallbackCount", len(fallbacks)),
+		)
+		fallbackResults, sendErrs := n.sendConcurrently(ctx, fallbacks, record, agent)
+		results = append(results, fallbackResults...)
+		for i, err := range sendErrs {
+			if err == nil {
+				continue
+			}
+			channelConfig := fallbacks[i]
+			n.logger.Error("发送备用通知失败",
+				zap.String("channelType", channelConfig.Type),
+				zap.String("channel", channelConfig.DisplayName()),
+				zap.String("error", deliveryErrorMessage(err)),
+			)
+			errs = append(errs, channelError(channelConfig, err))
+			n.deliveryFailed(channelConfig, record, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return results, fmt.Errorf("部分通知发送失败: %v", errs)
+	}
+
+	return results, nil
+}
+
+// sendConcurrently 并发发送到多个渠道，慢渠道或无响应的渠道不会阻塞其他渠道
+// 返回的结果和错误与 channels 一一对应，发送成功的错误为 nil
+func (n *Notifier) sendConcurrently(ctx context.Context, channels []models.NotificationChannelConfig, record *models.AlertRecord, agent *models.Agent) ([]ChannelResult, []error) {
+	results := make([]ChannelResult, len(channels))
+	errs := make([]error, len(channels))
+	var wg sync.WaitGroup
+	for i := range channels {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 每个 goroutine 只写入自己的下标，无需加锁
+			channelConfig := &channels[i]
+			start := time.Now()
+			err := n.SendNotificationByConfig(ctx, channelConfig, record, agent)
+			results[i] = ChannelResult{
+				ChannelID:  channelConfig.ID,
+				Name:       channelConfig.Name,
+				Type:       channelConfig.Type,
+				Fallback:   channelConfig.Fallback,
+				Success:    err == nil,
+				DurationMs: time.Since(start).Milliseconds(),
+			}
+			// 被频率限制丢弃的通知既不计为成功，也不按失败重试或升级
+			switch {
+			case errors.Is(err, errRateLimited):
+				results[i].Suppressed = true
+				err = nil
+			case errors.Is(err, errWebhookQueued):
+				results[i].Success = false
+				results[i].Queued = true
+				err = nil
+			}
+			if err != nil {
+				results[i].Error = deliveryErrorMessage(err)
+			}
+			errs[i] = err
+		}(i)
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// channelError 为渠道发送错误附加渠道名称和类型
+func channelError(channelConfig models.NotificationChannelConfig, err error) error {
+	return fmt.Errorf("%s(%s): %w", channelConfig.DisplayName(), channelConfig.Type, err)
 }
@@ -0,0 +1,555 @@
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/config"
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+func TestFormatAlertValue(t *testing.T) {
+	opts := messageOptions{}
+	cases := []struct {
+		alertType string
+		value     float64
+		want      string
+	}{
+		{"cpu", 85.5, "85.50%"},
+		{"memory", 80, "80.00%"},
+		{"disk", 90.123, "90.12%"},
+		{"network", 12.5, "12.50MB/s"},
+		{"cert", 7, "7天"},
+		{"service", 300, "300秒"},
+		{"agent_offline", 120, "120秒"},
+		{"unknown", 1.5, "1.50"},
+	}
+	for _, c := range cases {
+		if got := formatAlertValue(c.alertType, c.value, opts); got != c.want {
+			t.Errorf("formatAlertValue(%q, %v) = %q, want %q", c.alertType, c.value, got, c.want)
+		}
+	}
+}
+
+func TestBuildMessageContentHidesMeaninglessValues(t *testing.T) {
+	n := &Notifier{}
+	opts := messageOptions{Fields: []string{"threshold", "value"}}
+	agent := &models.Agent{ID: "a1", Name: "agent"}
+
+	for _, alertType := range []string{"service", "version"} {
+		record := &models.AlertRecord{AlertType: alertType, Status: "firing", ActualValue: 300}
+		content, ok := n.buildMessageContent(agent, record, opts)
+		if !ok {
+			t.Fatalf("%s: expected content", alertType)
+		}
+		if len(content.Lines) != 0 {
+			t.Errorf("%s: expected no threshold/value lines, got %+v", alertType, content.Lines)
+		}
+	}
+
+	record := &models.AlertRecord{AlertType: "cert", Status: "firing", Threshold: 30, ActualValue: 7}
+	content, _ := n.buildMessageContent(agent, record, opts)
+	if len(content.Lines) != 2 || content.Lines[0].Value != "30天" || content.Lines[1].Value != "7天" {
+		t.Errorf("cert: unexpected lines %+v", content.Lines)
+	}
+}
+
+func TestResolveMessageLocale(t *testing.T) {
+	cases := []struct {
+		candidates []string
+		want       string
+	}{
+		{[]string{"en"}, "en"},
+		{[]string{"en-US"}, "en"},
+		{[]string{"EN_gb"}, "en"},
+		{[]string{"fr", "en"}, "en"},
+		{[]string{"fr", ""}, "zh"},
+	}
+	for _, c := range cases {
+		if got := resolveMessageLocale(c.candidates...); got != c.want {
+			t.Errorf("resolveMessageLocale(%v) = %q, want %q", c.candidates, got, c.want)
+		}
+	}
+
+	opts := messageOptions{Locale: "en"}
+	if got := opts.alertTypeName("agent_offline"); got != "Agent Offline Alert" {
+		t.Errorf("unexpected english name %q", got)
+	}
+	if got := (messageOptions{}).alertTypeName("agent_offline"); got != "探针离线告警" {
+		t.Errorf("unexpected chinese name %q", got)
+	}
+}
+
+func TestRenderMessageTemplate(t *testing.T) {
+	n := &Notifier{}
+	opts := parseMessageOptions(map[string]interface{}{
+		"template":         "{{levelIcon .Alert.Level}} {{.Agent.Name}} {{formatTime .Alert.FiredAt}}",
+		"resolvedTemplate": "{{.Agent.Name}} ok",
+	})
+	agent := &models.Agent{Name: "web-1"}
+
+	firing := &models.AlertRecord{Level: "critical", Status: "firing", FiredAt: 1}
+	message, ok := n.renderMessageTemplate(agent, firing, opts, messageContent{})
+	if !ok || message != opts.levelIcon("critical")+" web-1 "+formatTemplateTime(1) {
+		t.Fatalf("unexpected firing message %q", message)
+	}
+
+	resolved := &models.AlertRecord{Status: "resolved"}
+	if message, _ := n.renderMessageTemplate(agent, resolved, opts, messageContent{}); message != "web-1 ok" {
+		t.Fatalf("unexpected resolved message %q", message)
+	}
+
+	if errs := validateMessageTemplates(map[string]interface{}{"template": "{{unknownFunc}}"}); len(errs) != 1 {
+		t.Fatalf("expected validation error, got %v", errs)
+	}
+}
+
+func TestBuildGroupedMessage(t *testing.T) {
+	n := &Notifier{}
+	agent := &models.Agent{ID: "a1", Name: "web-1", Hostname: "web-1.local", IP: "10.0.0.1"}
+	records := []*models.AlertRecord{
+		{AlertType: "memory", Status: "resolved", Level: "warning", ActualValue: 40},
+		{AlertType: "cpu", Status: "firing", Level: "critical", ActualValue: 95, Threshold: 80},
+	}
+	msg := n.buildGroupedMessage(agent, records, messageOptions{})
+
+	if !strings.HasPrefix(msg, "web-1 同时产生 2 条告警") {
+		t.Fatalf("缺少探针标题: %q", msg)
+	}
+	firing := strings.Index(msg, "[告警中] CPU告警")
+	resolved := strings.Index(msg, "[已恢复] 内存告警")
+	if firing < 0 || resolved < 0 || firing > resolved {
+		t.Fatalf("告警中的条目应排在已恢复之前: %q", msg)
+	}
+	if !strings.Contains(msg, "当前值: 95.00%, 阈值: 80.00%") {
+		t.Fatalf("缺少当前值和阈值: %q", msg)
+	}
+}
+
+func TestSendDingTalkSignedURL(t *testing.T) {
+	var query url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+		_, _ = w.Write([]byte(`{"errcode":0,"errmsg":"ok"}`))
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	n.now = func() int64 { return 1700000000000 }
+	if err := n.sendDingTalk(context.Background(), server.URL+"?access_token=token", "SECdemo", "hello"); err != nil {
+		t.Fatalf("sendDingTalk() error = %v", err)
+	}
+
+	if got := query.Get("timestamp"); got != "1700000000000" {
+		t.Fatalf("timestamp = %s, want 1700000000000", got)
+	}
+	if got, want := query.Get("sign"), "lOvVf9TQCVugV68mGbSZCg0gzOl1SVjxWi1MyVZCUuA="; got != want {
+		t.Fatalf("sign = %s, want %s", got, want)
+	}
+}
+
+// BenchmarkSendJSONRequest 对比共享连接池与每次新建 Transport 时的内存分配和新建连接数
+func TestNewNotificationTLSConfigCipherSuites(t *testing.T) {
+	tlsConfig, err := newNotificationTLSConfig(zap.NewNop(), config.NotificationConfig{
+		TLSCipherSuites: []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_UNKNOWN"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tlsConfig.CipherSuites) != 1 || tlsConfig.CipherSuites[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Fatalf("unexpected cipher suites: %v", tlsConfig.CipherSuites)
+	}
+
+	// 全部无效时不能静默回退到默认套件
+	if _, err := NewNotifier(zap.NewNop(), &config.AppConfig{Notification: &config.NotificationConfig{
+		TLSCipherSuites: []string{"TLS_UNKNOWN", "TLS_RSA_WITH_RC4_128_SHA"},
+	}}, nil); err == nil {
+		t.Fatal("expected error when no configured cipher suite is usable")
+	}
+}
+
+func BenchmarkSendJSONRequest(b *testing.B) {
+	var conns atomic.Int64
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"errcode":0}`))
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	server.Start()
+	defer server.Close()
+
+	body := map[string]interface{}{"msgtype": "text", "text": map[string]string{"content": "benchmark"}}
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	pooled := n.client
+	// beforeRequest 在每次发送前调整 n.client，用于对比共享连接池和每次请求新建 Client 的开销
+	run := func(b *testing.B, beforeRequest func()) {
+		conns.Store(0)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			beforeRequest()
+			if _, err := n.sendJSONRequest(context.Background(), server.URL, body); err != nil {
+				b.Fatal(err)
+			}
+		}
+		b.ReportMetric(float64(conns.Load())/float64(b.N), "conns/op")
+	}
+
+	b.Run("shared", func(b *testing.B) {
+		run(b, func() { n.client = pooled })
+	})
+	b.Run("perRequest", func(b *testing.B) {
+		// 模拟在 sendJSONRequest 内部为每次请求新建 http.Client 和 Transport
+		run(b, func() {
+			n.client.CloseIdleConnections()
+			n.client = &http.Client{Transport: http.DefaultTransport.(*http.Transport).Clone()}
+		})
+	})
+	n.client.CloseIdleConnections()
+}
+
+func TestSendNotificationByConfigRecordsAttempt(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	var logs []*models.NotificationLog
+	n.onDeliveryAttempt = func(log *models.NotificationLog) {
+		logs = append(logs, log)
+	}
+
+	channel := &models.NotificationChannelConfig{
+		ID:      "channel-1",
+		Type:    "webhook",
+		Enabled: true,
+		Config:  map[string]interface{}{"url": server.URL, "maxRetries": float64(0)},
+	}
+	record := &models.AlertRecord{ID: 42, AgentID: "agent-1", AlertType: "cpu", Status: "firing", Level: "warning"}
+	if err := n.SendNotificationByConfig(context.Background(), channel, record, &models.Agent{ID: "agent-1"}); err == nil {
+		t.Fatal("SendNotificationByConfig() error = nil, want error")
+	}
+
+	if len(logs) != 1 {
+		t.Fatalf("应记录 1 条发送记录，实际 %d 条", len(logs))
+	}
+	got := logs[0]
+	if got.ChannelID != "channel-1" || got.ChannelType != "webhook" || got.AlertRecordID != 42 || got.AgentID != "agent-1" {
+		t.Fatalf("发送记录字段不正确: %+v", got)
+	}
+	if got.Success || got.ErrorMessage == "" {
+		t.Fatalf("失败的发送应记录失败原因: %+v", got)
+	}
+}
+
+func TestDeliveryErrorMessageStripsURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+	}{
+		{name: "钉钉", url: "https://oapi.dingtalk.com/robot/send?access_token=secret-token"},
+		{name: "企业微信", url: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=secret-token"},
+		{name: "Telegram", url: "https://api.telegram.org/botsecret-token/sendMessage"},
+		{name: "企业微信应用", url: "https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=corp&corpsecret=secret-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := fmt.Errorf("发送请求失败: %w", &url.Error{Op: "Post", URL: tt.url, Err: errors.New("connection refused")})
+			got := deliveryErrorMessage(err)
+			if strings.Contains(got, "secret-token") {
+				t.Fatalf("失败原因不应包含密钥: %s", got)
+			}
+			if !strings.Contains(got, "connection refused") {
+				t.Fatalf("失败原因应保留底层错误: %s", got)
+			}
+		})
+	}
+}
+
+func TestSendWebhookGBKMessage(t *testing.T) {
+	var body []byte
+	var contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	channel := &models.NotificationChannelConfig{
+		ID:      "channel-1",
+		Type:    "webhook",
+		Enabled: true,
+		Config:  map[string]interface{}{"url": server.URL, "bodyTemplate": "json", "charset": "gbk"},
+	}
+	agent := &models.Agent{ID: "agent-1", Name: "web-1"}
+	record := &models.AlertRecord{ID: 1, AgentID: "agent-1", AlertType: "cpu", Status: "firing", Level: "critical", ActualValue: 95, Threshold: 80}
+	if msg := n.buildMessage(agent, record, n.messageOptions(context.Background(), channel.Config)); !strings.ContainsAny(msg, "ℹ⚠🚨✅") {
+		t.Fatalf("默认消息应包含 emoji: %s", msg)
+	}
+	if err := n.SendNotificationByConfig(context.Background(), channel, record, agent); err != nil {
+		t.Fatalf("SendNotificationByConfig() error = %v", err)
+	}
+
+	decoded, err := simplifiedchinese.GBK.NewDecoder().Bytes(body)
+	if err != nil {
+		t.Fatalf("请求体不是有效的 GBK: %v", err)
+	}
+	if !json.Valid(decoded) || !strings.Contains(string(decoded), "web-1") {
+		t.Fatalf("请求体内容错误: %s", decoded)
+	}
+	if contentType != "application/json; charset=gbk" {
+		t.Fatalf("Content-Type = %s", contentType)
+	}
+}
+
+func TestWebhookBatchRecordsFlushFailurePerItem(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	var logs []*models.NotificationLog
+	n.onDeliveryAttempt = func(log *models.NotificationLog) {
+		logs = append(logs, log)
+	}
+	var failed []int64
+	n.onDeliveryFailed = func(channelConfig models.NotificationChannelConfig, record *models.AlertRecord, err error) {
+		failed = append(failed, record.ID)
+	}
+
+	channel := &models.NotificationChannelConfig{
+		ID:      "channel-1",
+		Type:    "webhook",
+		Enabled: true,
+		Config: map[string]interface{}{
+			"url":        server.URL,
+			"maxRetries": float64(0),
+			"batch":      map[string]interface{}{"enabled": true, "flushIntervalSeconds": float64(3600)},
+		},
+	}
+	for _, id := range []int64{1, 2} {
+		record := &models.AlertRecord{ID: id, AgentID: "agent-1", AlertType: "cpu", Status: "firing", Level: "warning"}
+		if err := n.SendNotificationByConfig(context.Background(), channel, record, &models.Agent{ID: "agent-1"}); !errors.Is(err, errWebhookQueued) {
+			t.Fatalf("SendNotificationByConfig() error = %v, want errWebhookQueued", err)
+		}
+	}
+	if len(logs) != 0 {
+		t.Fatalf("加入批次时不应记录发送结果，实际 %d 条", len(logs))
+	}
+
+	n.FlushWebhookBatches()
+	if requests.Load() != 1 {
+		t.Fatalf("应以一个请求发送整批告警，实际 %d 个", requests.Load())
+	}
+	if len(logs) != 2 || logs[0].Success || logs[1].Success {
+		t.Fatalf("批次发送失败应逐条记录失败: %+v", logs)
+	}
+	if len(failed) != 2 {
+		t.Fatalf("批次发送失败应逐条进入失败处理，实际 %v", failed)
+	}
+}
+
+func TestWebhookBatchSeparatesChannelsSharingURL(t *testing.T) {
+	var tokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tokens = append(tokens, r.Header.Get("X-Token"))
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	newChannel := func(id, token string) models.NotificationChannelConfig {
+		return models.NotificationChannelConfig{
+			ID:      id,
+			Type:    "webhook",
+			Enabled: true,
+			Config: map[string]interface{}{
+				"url":     server.URL,
+				"headers": map[string]interface{}{"X-Token": token},
+				"batch":   map[string]interface{}{"enabled": true, "flushIntervalSeconds": float64(3600)},
+			},
+		}
+	}
+	channels := []models.NotificationChannelConfig{newChannel("channel-a", "token-a"), newChannel("channel-b", "token-b")}
+	record := &models.AlertRecord{ID: 1, AgentID: "agent-1", AlertType: "cpu", Status: "firing", Level: "warning"}
+	results, err := n.SendNotificationByConfigs(context.Background(), channels, record, &models.Agent{ID: "agent-1"})
+	if err != nil {
+		t.Fatalf("SendNotificationByConfigs() error = %v", err)
+	}
+	for _, result := range results {
+		if result.Success || !result.Queued {
+			t.Fatalf("加入批次的通知应标记为等待发送而非成功: %+v", result)
+		}
+	}
+
+	n.FlushWebhookBatches()
+	slices.Sort(tokens)
+	if !slices.Equal(tokens, []string{"token-a", "token-b"}) {
+		t.Fatalf("共用地址的渠道应各自分批并使用自己的请求头，实际 %v", tokens)
+	}
+}
+
+func TestDryRunNotificationByConfigs(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	var logs int
+	n.onDeliveryAttempt = func(*models.NotificationLog) { logs++ }
+
+	channels := []models.NotificationChannelConfig{{
+		Type:   "webhook",
+		Config: map[string]interface{}{"url": server.URL, "headers": map[string]interface{}{"Authorization": "Bearer secret"}},
+	}}
+	record := &models.AlertRecord{AgentID: "agent-1", AlertType: "cpu", Status: "firing", Level: "warning", Message: "CPU过高"}
+	results := n.DryRunNotificationByConfigs(context.Background(), channels, record, &models.Agent{ID: "agent-1", Name: "web-1"})
+
+	if got := requests.Load(); got != 0 {
+		t.Fatalf("演练模式不应发送请求，实际 %d 次", got)
+	}
+	if logs != 0 {
+		t.Fatalf("演练模式不应记录发送记录，实际 %d 条", logs)
+	}
+	if len(results) != 1 || !results[0].Success || len(results[0].Requests) != 1 {
+		t.Fatalf("DryRunNotificationByConfigs() = %+v", results)
+	}
+	rendered := results[0].Requests[0]
+	if rendered.Method != http.MethodPost || rendered.URL != server.URL || !strings.Contains(rendered.Body, "CPU过高") {
+		t.Fatalf("渲染的请求不正确: %+v", rendered)
+	}
+	if rendered.Headers["Authorization"] != "******" {
+		t.Fatalf("认证请求头应被隐藏: %+v", rendered.Headers)
+	}
+}
+
+func TestRedactRequestURL(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "https://oapi.dingtalk.com/robot/send?access_token=secret-token", want: "https://oapi.dingtalk.com/robot/send?access_token=******"},
+		{raw: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=secret-token", want: "https://qyapi.weixin.qq.com/cgi-bin/webhook/send?key=******"},
+		{raw: "https://api.telegram.org/bot123:secret-token/sendMessage", want: "https://api.telegram.org/bot******/sendMessage"},
+		{raw: "https://open.feishu.cn/open-apis/bot/v2/hook/secret-token", want: "https://open.feishu.cn/open-apis/bot/v2/hook/******"},
+		{raw: "https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=corp&corpsecret=secret-token", want: "https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=corp&corpsecret=******"},
+		{raw: "https://qyapi.weixin.qq.com/cgi-bin/appchat/send?access_token=" + dryRunAccessToken, want: "https://qyapi.weixin.qq.com/cgi-bin/appchat/send?access_token=" + dryRunAccessToken},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := redactRequestURL(u); got != tt.want {
+			t.Errorf("redactRequestURL(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestSendAggregatedByConfigsHonorsChannelFilters(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	channels := []models.NotificationChannelConfig{
+		{Type: "webhook", Enabled: true, Config: map[string]interface{}{"url": server.URL, "notifyOnResolve": false}},
+		{Type: "webhook", Enabled: true, Config: map[string]interface{}{"url": server.URL, "alertTypes": []interface{}{"memory"}}},
+		{Type: "webhook", Enabled: true, Config: map[string]interface{}{"url": server.URL}},
+	}
+	var alerts []aggregatedAlert
+	for _, id := range []string{"agent-1", "agent-2"} {
+		alerts = append(alerts, aggregatedAlert{
+			record: &models.AlertRecord{AgentID: id, AlertType: "cpu", Status: "resolved", Level: "warning"},
+			agent:  &models.Agent{ID: id, Name: id, Status: 1},
+		})
+	}
+
+	if err := n.SendAggregatedByConfigs(context.Background(), channels, alerts, 5); err != nil {
+		t.Fatalf("SendAggregatedByConfigs() error = %v", err)
+	}
+	// 只有第三个渠道接收这两条恢复通知
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("应发送 2 次请求，实际 %d 次", got)
+	}
+}
+
+func TestPagerDutyDedupKeySeparatesMonitors(t *testing.T) {
+	a := &models.AlertRecord{AgentID: "agent-1", AlertType: "service", MonitorID: "monitor-a", Status: "firing"}
+	b := &models.AlertRecord{AgentID: "agent-1", AlertType: "service", MonitorID: "monitor-b", Status: "firing"}
+	if pagerDutyDedupKey(a) == pagerDutyDedupKey(b) {
+		t.Fatal("同一探针上不同监控项的告警应使用不同的 dedup_key")
+	}
+	resolved := *a
+	resolved.Status = "resolved"
+	if pagerDutyDedupKey(a) != pagerDutyDedupKey(&resolved) {
+		t.Fatal("同一告警的触发与恢复应使用相同的 dedup_key")
+	}
+}
+
+func TestNotifyDeliveryFailureRedactsError(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	fallbacks := []models.NotificationChannelConfig{{
+		ID:       "fallback",
+		Type:     "webhook",
+		Enabled:  true,
+		Fallback: true,
+		Config:   map[string]interface{}{"url": server.URL},
+	}}
+	failed := &models.NotificationChannelConfig{ID: "dingtalk", Type: "dingtalk", Name: "钉钉"}
+	record := &models.AlertRecord{ID: 7, AgentID: "agent-1", AlertType: "cpu", Status: "firing", Level: "critical"}
+	sendErr := &url.Error{Op: "Post", URL: "https://oapi.dingtalk.com/robot/send?access_token=secret-token", Err: errors.New("timeout")}
+
+	n.notifyDeliveryFailure(context.Background(), fallbacks, failed, record, &models.Agent{ID: "agent-1"}, sendErr)
+	if body == "" {
+		t.Fatal("应通过备用渠道发送投递失败通知")
+	}
+	if strings.Contains(body, "secret-token") {
+		t.Fatalf("投递失败通知不应包含密钥: %s", body)
+	}
+}
+
+func TestPartialDelivery(t *testing.T) {
+	partial := recipientResultsError([]RecipientResult{{Recipient: "a", Success: true}, {Recipient: "b", Error: "timeout"}})
+	allFailed := recipientResultsError([]RecipientResult{{Recipient: "a", Error: "timeout"}, {Recipient: "b", Error: "timeout"}})
+	if !partialDelivery(fmt.Errorf("发送失败: %w", partial)) {
+		t.Error("部分接收方成功时应判定为部分投递")
+	}
+	if partialDelivery(allFailed) || partialDelivery(errors.New("timeout")) || partialDelivery(nil) {
+		t.Error("全部失败或非接收方错误不应判定为部分投递")
+	}
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+const (
+	// defaultNtfyServer 未配置 server 时使用的公共 ntfy 服务
+	defaultNtfyServer = "https://ntfy.sh"
+	// ntfyMessageByteLimit ntfy 默认消息大小上限为 4096 字节，超出会被当作附件
+	ntfyMessageByteLimit = 4096
+)
+
+// ntfyPriorities 告警级别对应的 ntfy 优先级（1 最低，5 最高，3 为默认）
+var ntfyPriorities = map[string]string{
+	"info":     "3",
+	"warning":  "4",
+	"critical": "5",
+}
+
+// ntfyLevelTags 告警级别对应的 ntfy 标签，与 emoji 简码同名的标签会显示为图标
+var ntfyLevelTags = map[string]string{
+	"info":     "information_source",
+	"warning":  "warning",
+	"critical": "rotating_light",
+}
+
+// NtfyConfig ntfy 渠道配置
+type NtfyConfig struct {
+	Server      string // 服务地址，默认 https://ntfy.sh
+	Topic       string // 主题
+	Username    string // 用户名，与 Password 一起使用 Basic 认证
+	Password    string // 密码
+	AccessToken string // 访问令牌，优先于用户名密码
+	BaseURL     string // 详情页地址，点击通知时打开对应探针
+}
+
+// ParseNtfyConfig 解析并校验 ntfy 渠道配置
+// 配置格式: { "server": "https://ntfy.sh", "topic": "pika-alerts", "username": "xxx", "password": "xxx", "accessToken": "tk_xxx", "baseUrl": "https://pika.example.com" }
+func ParseNtfyConfig(config map[string]interface{}) (NtfyConfig, error) {
+	var cfg NtfyConfig
+	cfg.Server, _ = config["server"].(string)
+	cfg.Server = strings.TrimRight(strings.TrimSpace(cfg.Server), "/")
+	if cfg.Server == "" {
+		cfg.Server = defaultNtfyServer
+	}
+	if u, err := url.Parse(cfg.Server); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return cfg, fmt.Errorf("ntfy server 无效: %s", cfg.Server)
+	}
+
+	cfg.Topic, _ = config["topic"].(string)
+	cfg.Topic = strings.TrimSpace(cfg.Topic)
+	if cfg.Topic == "" {
+		return cfg, fmt.Errorf("ntfy 配置缺少 topic")
+	}
+	if strings.Contains(cfg.Topic, "/") {
+		return cfg, fmt.Errorf("ntfy topic 不能包含 /")
+	}
+
+	cfg.Username, _ = config["username"].(string)
+	cfg.Password, _ = config["password"].(string)
+	cfg.AccessToken, _ = config["accessToken"].(string)
+	cfg.AccessToken = strings.TrimSpace(cfg.AccessToken)
+	if cfg.Username == "" && cfg.Password != "" {
+		return cfg, fmt.Errorf("ntfy 配置了 password 但缺少 username")
+	}
+	cfg.BaseURL = channelBaseURL(config)
+	return cfg, nil
+}
+
+// ntfyMessage 一条 ntfy 通知
+type ntfyMessage struct {
+	Title    string
+	Body     string
+	Priority string
+	Tags     string
+	Click    string
+}
+
+// sendNtfy 以请求体为消息内容发布到主题，标题等通过请求头传递，返回 ntfy 的消息ID
+func (n *Notifier) sendNtfy(ctx context.Context, cfg NtfyConfig, msg ntfyMessage) (string, error) {
+	endpoint := cfg.Server + "/" + url.PathEscape(cfg.Topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(truncateMessage(msg.Body, ntfyMessageByteLimit, msg.Click)))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	if msg.Title != "" {
+		// 请求头只能是 ASCII，中文标题按 RFC 2047 编码，ntfy 会自动解码
+		req.Header.Set("Title", mime.BEncoding.Encode("utf-8", msg.Title))
+	}
+	if msg.Priority != "" {
+		req.Header.Set("Priority", msg.Priority)
+	}
+	if msg.Tags != "" {
+		req.Header.Set("Tags", msg.Tags)
+	}
+	if msg.Click != "" {
+		req.Header.Set("X-Click", msg.Click)
+	}
+	if cfg.AccessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.AccessToken)
+	} else if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	resp, err := n.doHTTP(req)
+	if err != nil {
+		return "", fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+	}
+	var result struct {
+		ID string `json:"id"`
+	}
+	_ = json.Unmarshal(respBody, &result)
+	return result.ID, nil
+}
+
+// sendNtfyByConfig 根据配置发送 ntfy 通知，优先级和标签按告警级别设置，恢复通知使用默认优先级
+func (n *Notifier) sendNtfyByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseNtfyConfig(config)
+	if err != nil {
+		return err
+	}
+
+	msg := ntfyMessage{Body: message}
+	if title, body, ok := strings.Cut(message, "\n"); ok {
+		msg.Title = title
+		msg.Body = strings.TrimLeft(body, "\n")
+	}
+	if record.Status == "resolved" {
+		msg.Priority = "3"
+		msg.Tags = "white_check_mark"
+	} else {
+		msg.Priority = ntfyPriorities[record.Level]
+		msg.Tags = ntfyLevelTags[record.Level]
+	}
+	if agent != nil {
+		msg.Click = agentDetailURL(cfg.BaseURL, agent.ID)
+	}
+
+	messageID, err := n.sendNtfy(ctx, cfg, msg)
+	n.logDelivery("ntfy", record, messageID, err)
+	return err
+}
+
+// sendNtfyRaw 发送已构建好的纯文本消息
+func (n *Notifier) sendNtfyRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseNtfyConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = n.sendNtfy(ctx, cfg, ntfyMessage{Body: message})
+	return err
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+func TestSendNtfyByConfig(t *testing.T) {
+	var got *http.Request
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		got, body = r, string(data)
+		_, _ = w.Write([]byte(`{"id":"abc123"}`))
+	}))
+	defer server.Close()
+
+	n, _ := NewNotifier(zap.NewNop(), nil, nil)
+	config := map[string]interface{}{
+		"server":      server.URL,
+		"topic":       "pika",
+		"accessToken": "tk_test",
+		"baseUrl":     "https://pika.example.com",
+	}
+	agent := &models.Agent{ID: "a1", Name: "web-1"}
+	record := &models.AlertRecord{AgentID: "a1", AlertType: "cpu", Status: "firing", Level: "critical"}
+	if err := n.sendNtfyByConfig(context.Background(), config, agent, record, "🚨 CPU告警\n\n探针: web-1"); err != nil {
+		t.Fatalf("sendNtfyByConfig() error = %v", err)
+	}
+
+	if got.URL.Path != "/pika" || body != "探针: web-1" {
+		t.Fatalf("请求路径或消息内容错误: %s %q", got.URL.Path, body)
+	}
+	if title, _ := new(mime.WordDecoder).DecodeHeader(got.Header.Get("Title")); title != "🚨 CPU告警" {
+		t.Fatalf("Title = %q", title)
+	}
+	if got.Header.Get("Priority") != "5" || got.Header.Get("Tags") != "rotating_light" {
+		t.Fatalf("Priority/Tags 错误: %s %s", got.Header.Get("Priority"), got.Header.Get("Tags"))
+	}
+	if got.Header.Get("X-Click") != "https://pika.example.com/servers/a1" || got.Header.Get("Authorization") != "Bearer tk_test" {
+		t.Fatalf("X-Click/Authorization 错误: %s %s", got.Header.Get("X-Click"), got.Header.Get("Authorization"))
+	}
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+const (
+	// pagerDutyEventsURL PagerDuty Events API v2 地址
+	pagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+	// pagerDutySummaryLimit 事件摘要最多 1024 个字符
+	pagerDutySummaryLimit = 1024
+)
+
+// pagerDutySeverities 告警级别对应的 PagerDuty 事件级别
+var pagerDutySeverities = map[string]string{
+	"info":     "info",
+	"warning":  "warning",
+	"critical": "critical",
+}
+
+// PagerDutyConfig PagerDuty 渠道配置
+type PagerDutyConfig struct {
+	RoutingKey string // 服务集成的 Integration Key
+}
+
+// ParsePagerDutyConfig 解析并校验 PagerDuty 渠道配置
+// 配置格式: { "routingKey": "xxx" }，可配合通用的 "minLevel": "critical" 仅为严重告警创建事件
+func ParsePagerDutyConfig(config map[string]interface{}) (PagerDutyConfig, error) {
+	var cfg PagerDutyConfig
+	cfg.RoutingKey, _ = config["routingKey"].(string)
+	cfg.RoutingKey = strings.TrimSpace(cfg.RoutingKey)
+	if cfg.RoutingKey == "" {
+		return cfg, fmt.Errorf("PagerDuty 配置缺少 routingKey")
+	}
+	return cfg, nil
+}
+
+// pagerDutyDedupKey 同一告警（含监控项）的触发与恢复使用相同的 dedup_key，恢复时据此关闭对应的事件
+func pagerDutyDedupKey(record *models.AlertRecord) string {
+	return "pika-" + record.DedupKey()
+}
+
+// sendPagerDutyEvent 发送事件，返回 PagerDuty 分配的 dedup_key
+func (n *Notifier) sendPagerDutyEvent(ctx context.Context, event map[string]interface{}) (string, error) {
+	respBody, err := n.sendJSONRequest(ctx, pagerDutyEventsURL, event)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Status   string `json:"status"`
+		Message  string `json:"message"`
+		DedupKey string `json:"dedup_key"`
+	}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("解析响应失败: %w", err)
+	}
+	if result.Status != "success" {
+		return "", fmt.Errorf("PagerDuty 返回错误: %s", result.Message)
+	}
+	return result.DedupKey, nil
+}
+
+// sendPagerDutyByConfig 根据配置发送 PagerDuty 事件
+// 告警发送 trigger 事件，恢复发送相同 dedup_key 的 resolve 事件以自动关闭事件
+func (n *Notifier) sendPagerDutyByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParsePagerDutyConfig(config)
+	if err != nil {
+		return err
+	}
+	event := map[string]interface{}{
+		"routing_key": cfg.RoutingKey,
+		"dedup_key":   pagerDutyDedupKey(record),
+	}
+	if record.Status == "resolved" {
+		event["event_action"] = "resolve"
+	} else {
+		opts := n.messageOptions(ctx, config)
+		summary, _, _ := strings.Cut(message, "\n")
+		if content, ok := n.buildMessageContent(agent, record, opts); ok {
+			summary = fmt.Sprintf("%s - %s", content.Title, agent.Name)
+		}
+		severity, ok := pagerDutySeverities[record.Level]
+		if !ok {
+			severity = "warning"
+		}
+		source := agent.Hostname
+		if source == "" {
+			source = agent.Name
+		}
+		details := map[string]interface{}{
+			"agentId":   agent.ID,
+			"agentName": agent.Name,
+			"ip":        agent.IP,
+			"message":   message,
+		}
+		if hasMetricValue(record.AlertType) {
+			details["threshold"] = formatAlertValue(record.AlertType, record.Threshold, opts)
+			details["value"] = formatAlertValue(record.AlertType, record.ActualValue, opts)
+		}
+		event["event_action"] = "trigger"
+		event["payload"] = map[string]interface{}{
+			"summary":        truncateMessage(summary, pagerDutySummaryLimit, ""),
+			"source":         source,
+			"severity":       severity,
+			"component":      record.AlertType,
+			"timestamp":      time.UnixMilli(record.FiredAt).Format(time.RFC3339),
+			"custom_details": details,
+		}
+	}
+
+	dedupKey, err := n.sendPagerDutyEvent(ctx, event)
+	n.logDelivery("pagerduty", record, dedupKey, err)
+	return err
+}
+
+// sendPagerDutyRaw 发送已构建好的纯文本消息，以 info 级别的 trigger 事件发送
+func (n *Notifier) sendPagerDutyRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParsePagerDutyConfig(config)
+	if err != nil {
+		return err
+	}
+	summary, _, _ := strings.Cut(message, "\n")
+	_, err = n.sendPagerDutyEvent(ctx, map[string]interface{}{
+		"routing_key":  cfg.RoutingKey,
+		"event_action": "trigger",
+		"payload": map[string]interface{}{
+			"summary":        truncateMessage(summary, pagerDutySummaryLimit, ""),
+			"source":         "pika",
+			"severity":       "info",
+			"custom_details": map[string]interface{}{"message": message},
+		},
+	})
+	return err
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// systemActor 没有登录用户的变更（如初始化默认配置、后台任务）记录的操作人
+const systemActor = "system"
+
+type actorKey struct{}
+
+// WithActor 在 ctx 中记录当前操作人，用于属性变更审计
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// actorFrom 获取 ctx 中的操作人，未设置时为 system
+func actorFrom(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+	return systemActor
+}
+
+// hashPropertyValue 计算属性值的哈希，空值返回空字符串
+func hashPropertyValue(value string) string {
+	if value == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// currentValueHash 获取属性当前值（解密后）的哈希，属性不存在或无法读取时返回空字符串
+// 读取失败（如加密密钥变更）不应阻止重新保存配置
+func (s *PropertyService) currentValueHash(ctx context.Context, id string) string {
+	property, err := s.Get(ctx, id)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Warn("读取属性当前值失败，审计记录中不包含变更前的哈希", zap.String("id", id), zap.Error(err))
+		}
+		return ""
+	}
+	return hashPropertyValue(property.Value)
+}
+
+// audit 写入属性变更审计记录，失败只记录日志，不影响变更本身
+func (s *PropertyService) audit(ctx context.Context, id, action, oldHash, newHash string) {
+	audit := &models.PropertyAudit{
+		PropertyID:   id,
+		Action:       action,
+		OldValueHash: oldHash,
+		NewValueHash: newHash,
+		Actor:        actorFrom(ctx),
+		Timestamp:    time.Now().UnixMilli(),
+	}
+	if err := s.auditRepo.CreateAudit(ctx, audit); err != nil {
+		s.logger.Error("写入属性变更审计记录失败",
+			zap.String("id", id),
+			zap.String("action", action),
+			zap.String("actor", audit.Actor),
+			zap.Error(err),
+		)
+	}
+}
+
+// Delete 删除属性
+func (s *PropertyService) Delete(ctx context.Context, id string) error {
+	oldHash := s.currentValueHash(ctx, id)
+
+	if err := s.repo.DeleteById(ctx, id); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.cache, id)
+	s.mu.Unlock()
+	s.corrupt.Delete(id)
+
+	s.audit(ctx, id, models.PropertyAuditDelete, oldHash, "")
+	return nil
+}
+
+// ListAudits 分页获取指定属性的变更审计记录
+func (s *PropertyService) ListAudits(ctx context.Context, id string, pr *orz.PageRequest) (*orz.PageResult[models.PropertyAudit], error) {
+	audits, total, err := s.auditRepo.FindByPropertyID(ctx, id, pr.PageSize, (pr.PageIndex-1)*pr.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return orz.NewPageResult(audits, total), nil
+}
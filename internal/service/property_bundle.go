@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+)
+
+// propertyBundleVersion 导出文件的格式版本
+const propertyBundleVersion = 1
+
+const (
+	// PropertyImportMerge 导入时只覆盖文件中包含的属性，其他属性保留
+	PropertyImportMerge = "merge"
+	// PropertyImportReplace 导入时删除文件中未包含的属性和通知渠道
+	PropertyImportReplace = "replace"
+)
+
+// PropertyBundle 所有属性和通知渠道的导出文件，用于备份或将测试环境的配置复制到生产环境
+type PropertyBundle struct {
+	Version        int              `json:"version"`        // 格式版本
+	ExportedAt     int64            `json:"exportedAt"`     // 导出时间（时间戳毫秒）
+	IncludeSecrets bool             `json:"includeSecrets"` // 是否包含敏感字段原值
+	Properties     []BundleProperty `json:"properties"`     // 属性列表
+	// 通知渠道（notification_channels 表），缺少该字段的文件导入时不修改通知渠道
+	Channels []models.NotificationChannelConfig `json:"channels"`
+}
+
+// BundleProperty 导出文件中的单个属性
+type BundleProperty struct {
+	ID    string      `json:"id"`
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+// PropertyBundleService 属性和通知渠道的导入导出
+type PropertyBundleService struct {
+	logger          *zap.Logger
+	propertyService *PropertyService
+	channelService  *NotificationChannelService
+}
+
+func NewPropertyBundleService(logger *zap.Logger, propertyService *PropertyService, channelService *NotificationChannelService) *PropertyBundleService {
+	return &PropertyBundleService{
+		logger:          logger,
+		propertyService: propertyService,
+		channelService:  channelService,
+	}
+}
+
+// ExportBundle 导出所有属性和通知渠道，includeSecrets 为 false 时通知渠道的敏感字段以占位值代替
+// 值损坏的属性无法导出，记录日志后跳过
+func (s *PropertyBundleService) ExportBundle(ctx context.Context, includeSecrets bool) (*PropertyBundle, error) {
+	properties, err := s.propertyService.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &PropertyBundle{
+		Version:        propertyBundleVersion,
+		ExportedAt:     time.Now().UnixMilli(),
+		IncludeSecrets: includeSecrets,
+		Properties:     make([]BundleProperty, 0, len(properties)),
+	}
+	for _, property := range properties {
+		var value interface{}
+		if err := s.propertyService.GetValue(ctx, property.ID, &value); err != nil {
+			var corruptErr *CorruptPropertyError
+			if errors.As(err, &corruptErr) {
+				s.logger.Warn("属性值损坏，导出时跳过", zap.String("id", property.ID))
+				continue
+			}
+			return nil, err
+		}
+		if property.ID == PropertyIDNotificationChannels && !includeSecrets {
+			channels, err := decodeChannels(value)
+			if err != nil {
+				return nil, err
+			}
+			for i := range channels {
+				channels[i] = RedactChannelSecrets(channels[i])
+			}
+			value = channels
+		}
+		bundle.Properties = append(bundle.Properties, BundleProperty{
+			ID:    property.ID,
+			Name:  property.Name,
+			Value: value,
+		})
+	}
+
+	channels, err := s.channelService.GetChannelConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !includeSecrets {
+		for i := range channels {
+			channels[i] = RedactChannelSecrets(channels[i])
+		}
+	}
+	bundle.Channels = channels
+	return bundle, nil
+}
+
+// ImportBundle 导入属性和通知渠道，先校验全部内容，任一项不合法时不做任何修改并返回所有错误
+// 通知渠道中仍为占位值的敏感字段保留当前已保存的值
+func (s *PropertyBundleService) ImportBundle(ctx context.Context, bundle *PropertyBundle, mode string) error {
+	if mode == "" {
+		mode = PropertyImportMerge
+	}
+	if mode != PropertyImportMerge && mode != PropertyImportReplace {
+		return orz.NewError(400, "不支持的导入模式: "+mode)
+	}
+	if bundle.Version != propertyBundleVersion {
+		return orz.NewError(400, fmt.Sprintf("不支持的导出文件版本: %d", bundle.Version))
+	}
+
+	var errs []string
+	values := make([]BundleProperty, 0, len(bundle.Properties))
+	included := make(map[string]bool, len(bundle.Properties))
+	for _, property := range bundle.Properties {
+		if property.ID == "" {
+			errs = append(errs, "属性ID不能为空")
+			continue
+		}
+		if included[property.ID] {
+			errs = append(errs, "属性 "+property.ID+" 重复")
+			continue
+		}
+		included[property.ID] = true
+
+		value, propertyErrs, err := s.propertyService.prepareImportValue(ctx, property)
+		if err != nil {
+			return err
+		}
+		for _, e := range propertyErrs {
+			errs = append(errs, property.ID+": "+e)
+		}
+		property.Value = value
+		values = append(values, property)
+	}
+
+	var channels []models.NotificationChannelConfig
+	if bundle.Channels != nil {
+		var channelErrs []string
+		var err error
+		channels, channelErrs, err = s.channelService.prepareImport(ctx, bundle.Channels)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, channelErrs...)
+	}
+	if len(errs) > 0 {
+		return orz.NewError(400, "导入文件校验失败: "+strings.Join(errs, "；"))
+	}
+
+	for _, property := range values {
+		if err := s.propertyService.Set(ctx, property.ID, property.Name, property.Value); err != nil {
+			return fmt.Errorf("导入属性 %s 失败: %w", property.ID, err)
+		}
+	}
+
+	removed := 0
+	if mode == PropertyImportReplace {
+		existing, err := s.propertyService.repo.FindAll(ctx)
+		if err != nil {
+			return err
+		}
+		for _, property := range existing {
+			if included[property.ID] {
+				continue
+			}
+			if err := s.propertyService.Delete(ctx, property.ID); err != nil {
+				return fmt.Errorf("删除属性 %s 失败: %w", property.ID, err)
+			}
+			removed++
+		}
+	}
+
+	removedChannels := 0
+	if bundle.Channels != nil {
+		var err error
+		if removedChannels, err = s.channelService.importChannels(ctx, channels, mode == PropertyImportReplace); err != nil {
+			return err
+		}
+	}
+
+	s.logger.Info("属性导入完成",
+		zap.String("mode", mode),
+		zap.Int("imported", len(values)),
+		zap.Int("removed", removed),
+		zap.Int("importedChannels", len(channels)),
+		zap.Int("removedChannels", removedChannels),
+		zap.String("actor", actorFrom(ctx)),
+	)
+	return nil
+}
+
+// prepareImportValue 校验待导入的属性值，通知渠道还原占位值后按渠道配置校验
+func (s *PropertyService) prepareImportValue(ctx context.Context, property BundleProperty) (interface{}, []string, error) {
+	if errs := ValidatePropertyValue(property.ID, property.Value); len(errs) > 0 {
+		return nil, errs, nil
+	}
+
+	switch property.ID {
+	case PropertyIDNotificationChannels:
+		channels, err := decodeChannels(property.Value)
+		if err != nil {
+			return nil, []string{err.Error()}, nil
+		}
+		channels, err = s.RestoreRedactedChannelSecrets(ctx, channels)
+		if err != nil {
+			return nil, nil, err
+		}
+		return channels, ValidateChannelConfigs(channels), nil
+	case PropertyIDSystemConfig:
+		data, err := json.Marshal(property.Value)
+		if err != nil {
+			return nil, []string{err.Error()}, nil
+		}
+		var systemConfig models.SystemConfig
+		if err := json.Unmarshal(data, &systemConfig); err != nil {
+			return nil, []string{err.Error()}, nil
+		}
+		if err := systemConfig.Validate(); err != nil {
+			return nil, []string{err.Error()}, nil
+		}
+	}
+	return property.Value, nil, nil
+}
+
+// decodeChannels 将 JSON 解码后的属性值转换为通知渠道配置列表
+func decodeChannels(value interface{}) ([]models.NotificationChannelConfig, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var channels []models.NotificationChannelConfig
+	if err := json.Unmarshal(data, &channels); err != nil {
+		return nil, fmt.Errorf("通知渠道配置格式错误: %w", err)
+	}
+	return channels, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-orz/orz"
+	"go.uber.org/zap"
+)
+
+func TestImportBundleRejectsInvalidBundle(t *testing.T) {
+	// 校验失败时不会访问数据库，无需初始化 repo
+	s := &PropertyBundleService{logger: zap.NewNop(), propertyService: &PropertyService{logger: zap.NewNop()}}
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		bundle PropertyBundle
+		mode   string
+		want   string
+	}{
+		{"未知模式", PropertyBundle{Version: propertyBundleVersion}, "overwrite", "不支持的导入模式"},
+		{"未知版本", PropertyBundle{Version: 2}, PropertyImportMerge, "不支持的导出文件版本"},
+		{"重复属性", PropertyBundle{Version: propertyBundleVersion, Properties: []BundleProperty{
+			{ID: PropertyIDAlertSnoozes, Value: map[string]interface{}{}},
+			{ID: PropertyIDAlertSnoozes, Value: map[string]interface{}{}},
+		}}, PropertyImportReplace, "重复"},
+		{"属性值不合法", PropertyBundle{Version: propertyBundleVersion, Properties: []BundleProperty{
+			{ID: PropertyIDSystemConfig, Value: map[string]interface{}{"defaultView": "table"}},
+		}}, "", "system_config: value.defaultView"},
+	}
+	for _, tt := range tests {
+		err := s.ImportBundle(ctx, &tt.bundle, tt.mode)
+		var orzErr *orz.Error
+		if !errors.As(err, &orzErr) || orzErr.Code != 400 {
+			t.Fatalf("%s: 期望 400 错误，实际为 %v", tt.name, err)
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: 错误信息 %q 缺少 %q", tt.name, err.Error(), tt.want)
+		}
+	}
+}
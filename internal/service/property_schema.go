@@ -0,0 +1,161 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// propertySchema 属性值的结构约束（JSON Schema 的子集）：类型、必填字段、枚举和未知字段
+type propertySchema struct {
+	Type       string                     // object, array, string, number, integer, boolean
+	Required   []string                   // object 的必填字段
+	Properties map[string]*propertySchema // object 的已知字段
+	// object 中未在 Properties 列出的字段的约束，为 nil 时不允许未知字段（用于发现字段名拼写错误）
+	AdditionalProperties *propertySchema
+	Items                *propertySchema // array 元素的约束
+	Enum                 []string        // string 的可选值
+}
+
+// anySchema 不做任何约束
+var anySchema = &propertySchema{}
+
+// propertySchemas 按属性ID注册的结构约束，未注册的属性不校验
+var propertySchemas = map[string]*propertySchema{}
+
+func init() {
+	propertySchemas[PropertyIDSystemConfig] = &propertySchema{
+		Type: "object",
+		Properties: map[string]*propertySchema{
+			"systemNameZh": {Type: "string"},
+			"systemNameEn": {Type: "string"},
+			"logoBase64":   {Type: "string"},
+			"icpCode":      {Type: "string"},
+			"defaultView":  {Type: "string", Enum: []string{"grid", "list"}},
+			"severities": {
+				Type: "object",
+				AdditionalProperties: &propertySchema{
+					Type:     "object",
+					Required: []string{"icon", "name"},
+					Properties: map[string]*propertySchema{
+						"icon": {Type: "string"},
+						"name": {Type: "string"},
+					},
+				},
+			},
+			"locale":          {Type: "string"},
+			"mutedAlertTypes": {Type: "array", Items: &propertySchema{Type: "string"}},
+		},
+	}
+
+	// 渠道类型取自注册表，在 init 中生成以避免与 channelRegistry 形成初始化循环
+	channelTypes := make([]string, 0, len(channelRegistry))
+	for _, def := range channelRegistry {
+		channelTypes = append(channelTypes, def.Type)
+	}
+	propertySchemas[PropertyIDNotificationChannels] = &propertySchema{
+		Type: "array",
+		Items: &propertySchema{
+			Type:     "object",
+			Required: []string{"type", "config"},
+			Properties: map[string]*propertySchema{
+				"id":          {Type: "string"},
+				"name":        {Type: "string"},
+				"description": {Type: "string"},
+				"type":        {Type: "string", Enum: channelTypes},
+				"enabled":     {Type: "boolean"},
+				"testOnly":    {Type: "boolean"},
+				"fallback":    {Type: "boolean"},
+				"priority":    {Type: "integer"},
+				// 各渠道的配置项由 ValidateChannelConfig 校验
+				"config": {Type: "object", AdditionalProperties: anySchema},
+			},
+		},
+	}
+}
+
+// ValidatePropertyValue 按属性ID注册的结构约束校验属性值，返回所有违反约束的字段，未注册约束的属性返回 nil
+func ValidatePropertyValue(id string, value interface{}) []string {
+	schema, ok := propertySchemas[id]
+	if !ok {
+		return nil
+	}
+	return schema.validate("value", value)
+}
+
+func (s *propertySchema) validate(path string, value interface{}) []string {
+	// null 视为未设置，必填字段由上层的 Required 检查
+	if value == nil {
+		return nil
+	}
+	if s.Type != "" && !matchesSchemaType(s.Type, value) {
+		return []string{fmt.Sprintf("%s: 类型应为 %s", path, s.Type)}
+	}
+
+	var errs []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				errs = append(errs, fmt.Sprintf("%s: 缺少必填字段 %s", path, key))
+			}
+		}
+		// 按字段名排序，错误信息顺序稳定
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			field, ok := s.Properties[key]
+			if !ok {
+				field = s.AdditionalProperties
+			}
+			if field == nil {
+				if s.Properties != nil {
+					errs = append(errs, fmt.Sprintf("%s: 未知字段 %s", path, key))
+				}
+				continue
+			}
+			errs = append(errs, field.validate(path+"."+key, v[key])...)
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item)...)
+			}
+		}
+	case string:
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+			errs = append(errs, fmt.Sprintf("%s: 取值 %q 无效，可选值为 %v", path, v, s.Enum))
+		}
+	}
+	return errs
+}
+
+// matchesSchemaType 判断 JSON 解码后的值是否为指定类型
+func matchesSchemaType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	default:
+		return true
+	}
+}
@@ -0,0 +1,45 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestValidatePropertyValue(t *testing.T) {
+	decode := func(s string) interface{} {
+		var v interface{}
+		if err := json.Unmarshal([]byte(s), &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	valid := decode(`{"systemNameZh": "皮卡", "defaultView": "grid", "severities": {"critical": {"icon": "🚨", "name": "严重"}}, "mutedAlertTypes": ["cert"]}`)
+	if errs := ValidatePropertyValue(PropertyIDSystemConfig, valid); len(errs) > 0 {
+		t.Fatalf("合法的系统配置不应报错: %v", errs)
+	}
+
+	errs := ValidatePropertyValue(PropertyIDSystemConfig, decode(`{"systemNameZH": "皮卡", "defaultView": "table", "mutedAlertTypes": "cert"}`))
+	joined := strings.Join(errs, "\n")
+	for _, want := range []string{"未知字段 systemNameZH", "value.defaultView", "value.mutedAlertTypes: 类型应为 array"} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("校验结果缺少 %q: %v", want, errs)
+		}
+	}
+
+	errs = ValidatePropertyValue(PropertyIDNotificationChannels, decode(`[{"type": "dingtalk", "config": {"secretKey": "x"}, "priority": 1}, {"type": "pigeon", "enabled": "yes"}]`))
+	joined = strings.Join(errs, "\n")
+	for _, want := range []string{"value[1]: 缺少必填字段 config", "value[1].enabled: 类型应为 boolean", `value[1].type: 取值 "pigeon" 无效`} {
+		if !strings.Contains(joined, want) {
+			t.Errorf("校验结果缺少 %q: %v", want, errs)
+		}
+	}
+	if strings.Contains(joined, "value[0]") {
+		t.Errorf("合法的渠道不应报错: %v", errs)
+	}
+
+	if errs := ValidatePropertyValue("metrics_config", decode(`{"anything": 1}`)); errs != nil {
+		t.Fatalf("未注册约束的属性不应校验: %v", errs)
+	}
+}
@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/dushixiang/pika/internal/config"
 	"github.com/dushixiang/pika/internal/models"
 	"github.com/dushixiang/pika/internal/repo"
 	"github.com/dushixiang/pika/web"
@@ -23,22 +27,56 @@ const (
 	PropertyIDMetricsConfig = "metrics_config"
 	// PropertyIDAlertConfig 告警配置的固定 ID
 	PropertyIDAlertConfig = "alert_config"
+	// PropertyIDAlertSnoozes 告警暂停通知列表的固定 ID
+	PropertyIDAlertSnoozes = "alert_snoozes"
+	// PropertyIDSilenceRules 静默规则列表的固定 ID
+	PropertyIDSilenceRules = "silence_rules"
+	// PropertyIDNotificationChannelsMigrated 通知渠道是否已迁移到独立表的标记
+	PropertyIDNotificationChannelsMigrated = "notification_channels_migrated"
 )
 
 type PropertyService struct {
-	repo   *repo.PropertyRepo
-	logger *zap.Logger
+	repo *repo.PropertyRepo
+	// 属性变更审计记录
+	auditRepo *repo.PropertyAuditRepo
+	logger    *zap.Logger
 	// 内存缓存，key 为 property ID，value 为 Property 对象
 	cache map[string]models.Property
 	// 缓存读写锁
 	mu sync.RWMutex
+	// 已记录日志的损坏属性，key 为 property ID，value 为损坏的原始值，避免重复刷日志
+	corrupt sync.Map
+	// 通知渠道敏感字段的加密器，未配置密钥时为 nil
+	secrets *secretCipher
 }
 
-func NewPropertyService(logger *zap.Logger, db *gorm.DB) *PropertyService {
+// CorruptPropertyError 属性值不是合法的 JSON（通常是手工修改数据库导致）
+// 携带原始值，便于在界面上展示并重新保存修复
+type CorruptPropertyError struct {
+	ID  string
+	Raw string
+	Err error
+}
+
+func (e *CorruptPropertyError) Error() string {
+	return fmt.Sprintf("属性 %s 的值不是合法的 JSON: %v", e.ID, e.Err)
+}
+
+func (e *CorruptPropertyError) Unwrap() error {
+	return e.Err
+}
+
+func NewPropertyService(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) *PropertyService {
+	secrets, err := newSecretCipher(notificationSecretKey(cfg))
+	if err != nil {
+		logger.Error("初始化通知渠道敏感字段加密失败，敏感字段将以明文保存", zap.Error(err))
+	}
 	return &PropertyService{
-		repo:   repo.NewPropertyRepo(db),
-		logger: logger,
-		cache:  make(map[string]models.Property),
+		repo:      repo.NewPropertyRepo(db),
+		auditRepo: repo.NewPropertyAuditRepo(db),
+		logger:    logger,
+		cache:     make(map[string]models.Property),
+		secrets:   secrets,
 	}
 }
 
@@ -57,6 +95,12 @@ func (s *PropertyService) Get(ctx context.Context, id string) (models.Property,
 	if err != nil {
 		return models.Property{}, err
 	}
+	if id == PropertyIDNotificationChannels {
+		property.Value, err = s.decryptChannelsValue(property.Value)
+		if err != nil {
+			return models.Property{}, err
+		}
+	}
 
 	// 更新缓存
 	s.mu.Lock()
@@ -78,37 +122,159 @@ func (s *PropertyService) GetValue(ctx context.Context, id string, target interf
 		return nil
 	}
 
-	return json.Unmarshal([]byte(property.Value), target)
+	if err := json.Unmarshal([]byte(property.Value), target); err != nil {
+		if json.Valid([]byte(property.Value)) {
+			// 合法 JSON 但与目标结构不匹配，不视为损坏
+			return err
+		}
+		s.reportCorrupt(id, property.Value, err)
+		return &CorruptPropertyError{ID: id, Raw: property.Value, Err: err}
+	}
+	return nil
+}
+
+// reportCorrupt 记录损坏的属性值，同一损坏值只记录一次
+func (s *PropertyService) reportCorrupt(id, raw string, err error) {
+	if previous, loaded := s.corrupt.Swap(id, raw); loaded && previous == raw {
+		return
+	}
+	s.logger.Error("属性值不是合法的 JSON，请在配置页面重新保存",
+		zap.String("id", id),
+		zap.String("raw", raw),
+		zap.Error(err),
+	)
 }
 
-// Set 设置属性（接收对象，自动序列化）
+// ErrPropertyVersionConflict 保存时属性的版本号与期望的不一致，说明已被其他人修改
+var ErrPropertyVersionConflict = errors.New("属性已被修改，请刷新后重试")
+
+// Set 设置属性（接收对象，自动序列化），直接覆盖当前值
 func (s *PropertyService) Set(ctx context.Context, id string, name string, value interface{}) error {
+	_, err := s.SetWithVersion(ctx, id, name, value, nil)
+	return err
+}
+
+// SetWithVersion 设置属性并返回保存后的版本号
+// ifVersion 不为 nil 时仅在当前版本号与其一致时保存，否则返回 ErrPropertyVersionConflict；
+// 属性尚不存在时版本号视为 0
+func (s *PropertyService) SetWithVersion(ctx context.Context, id string, name string, value interface{}, ifVersion *int64) (int64, error) {
 	jsonValue, err := json.Marshal(value)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	// 审计记录中的哈希基于未加密的值，内容相同的配置哈希相同
+	oldHash := s.currentValueHash(ctx, id)
+	newHash := hashPropertyValue(string(jsonValue))
+	if id == PropertyIDNotificationChannels {
+		if jsonValue, err = s.encryptChannelsValue(jsonValue); err != nil {
+			return 0, err
+		}
 	}
 
+	now := time.Now().UnixMilli()
 	property := &models.Property{
 		ID:        id,
 		Name:      name,
 		Value:     string(jsonValue),
-		CreatedAt: time.Now().UnixMilli(),
-		UpdatedAt: time.Now().UnixMilli(),
+		CreatedAt: now,
+		UpdatedAt: now,
 	}
 
-	err = s.repo.Save(ctx, property)
+	updated, err := s.repo.UpdateValue(ctx, property, ifVersion)
 	if err != nil {
-		return err
+		return 0, err
+	}
+	if !updated {
+		exists, err := s.repo.ExistsById(ctx, id)
+		if err != nil {
+			return 0, err
+		}
+		if exists || (ifVersion != nil && *ifVersion != 0) {
+			return 0, ErrPropertyVersionConflict
+		}
+		property.Version = 1
+		if err := s.repo.Create(ctx, property); err != nil {
+			return 0, err
+		}
 	}
 
 	// 清空缓存中的该项，下次读取时会重新从数据库加载
 	s.mu.Lock()
 	delete(s.cache, id)
 	s.mu.Unlock()
+	s.corrupt.Delete(id)
 
-	return nil
+	s.audit(ctx, id, models.PropertyAuditSet, oldHash, newHash)
+
+	if ifVersion != nil {
+		return *ifVersion + 1, nil
+	}
+	if !updated {
+		return property.Version, nil
+	}
+	saved, err := s.repo.FindById(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	return saved.Version, nil
+}
+
+// EncryptChannelSecrets 加密渠道配置中的敏感字段，未配置加密密钥时原样返回
+func (s *PropertyService) EncryptChannelSecrets(channel models.NotificationChannelConfig) (models.NotificationChannelConfig, error) {
+	return transformChannelSecrets(channel, s.secrets.Encrypt)
 }
 
+// DecryptChannelSecrets 解密渠道配置中的敏感字段，明文保存的旧配置原样返回
+func (s *PropertyService) DecryptChannelSecrets(channel models.NotificationChannelConfig) (models.NotificationChannelConfig, error) {
+	return transformChannelSecrets(channel, s.secrets.Decrypt)
+}
+
+// encryptChannelsValue 加密通知渠道列表中的敏感字段
+func (s *PropertyService) encryptChannelsValue(data []byte) ([]byte, error) {
+	if s.secrets == nil {
+		return data, nil
+	}
+	var channels []models.NotificationChannelConfig
+	if err := json.Unmarshal(data, &channels); err != nil {
+		return nil, fmt.Errorf("通知渠道配置格式错误: %w", err)
+	}
+	for i := range channels {
+		encrypted, err := s.EncryptChannelSecrets(channels[i])
+		if err != nil {
+			return nil, err
+		}
+		channels[i] = encrypted
+	}
+	return json.Marshal(channels)
+}
+
+// decryptChannelsValue 解密通知渠道列表中的敏感字段，非法 JSON 原样返回，由 GetValue 报告损坏
+func (s *PropertyService) decryptChannelsValue(raw string) (string, error) {
+	if !strings.Contains(raw, encryptedSecretPrefix) {
+		return raw, nil
+	}
+	var channels []models.NotificationChannelConfig
+	if err := json.Unmarshal([]byte(raw), &channels); err != nil {
+		return raw, nil
+	}
+	for i := range channels {
+		decrypted, err := s.DecryptChannelSecrets(channels[i])
+		if err != nil {
+			return "", fmt.Errorf("解密通知渠道配置失败: %w", err)
+		}
+		channels[i] = decrypted
+	}
+	data, err := json.Marshal(channels)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// GetNotificationChannelConfigs 获取旧版存储在 Property 中的通知渠道配置
+//
+// Deprecated: 通知渠道已迁移到 notification_channels 表，请使用 NotificationChannelService。
+// 该方法在废弃期内保留，用于迁移和兼容读取。
 func (s *PropertyService) GetNotificationChannelConfigs(ctx context.Context) ([]models.NotificationChannelConfig, error) {
 	var allChannels []models.NotificationChannelConfig
 	err := s.GetValue(ctx, PropertyIDNotificationChannels, &allChannels)
@@ -118,15 +284,55 @@ func (s *PropertyService) GetNotificationChannelConfigs(ctx context.Context) ([]
 	return allChannels, nil
 }
 
+// GetSystemConfig 获取系统配置，存储的值损坏时返回默认配置，避免整个界面不可用
 func (s *PropertyService) GetSystemConfig(ctx context.Context) (*models.SystemConfig, error) {
 	var systemConfig models.SystemConfig
 	err := s.GetValue(ctx, PropertyIDSystemConfig, &systemConfig)
 	if err != nil {
+		var corruptErr *CorruptPropertyError
+		if errors.As(err, &corruptErr) {
+			systemConfig = defaultSystemConfig()
+			return &systemConfig, nil
+		}
 		return nil, fmt.Errorf("获取系统配置失败: %w", err)
 	}
 	return &systemConfig, nil
 }
 
+// defaultSystemConfig 默认系统配置
+func defaultSystemConfig() models.SystemConfig {
+	return models.SystemConfig{
+		SystemNameZh: "皮卡监控",
+		SystemNameEn: "Pika Monitor",
+		LogoBase64:   web.DefaultLogoBase64(),
+		ICPCode:      "",
+		DefaultView:  "grid",
+	}
+}
+
+// SetMutedAlertTypes 设置全局静音的告警类型，保留系统配置中的其他字段
+func (s *PropertyService) SetMutedAlertTypes(ctx context.Context, alertTypes []string) (*models.SystemConfig, error) {
+	systemConfig, err := s.GetSystemConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	muted := make([]string, 0, len(alertTypes))
+	for _, alertType := range alertTypes {
+		alertType = strings.TrimSpace(alertType)
+		if alertType == "" || slices.Contains(muted, alertType) {
+			continue
+		}
+		muted = append(muted, alertType)
+	}
+	systemConfig.MutedAlertTypes = muted
+
+	if err := s.Set(ctx, PropertyIDSystemConfig, "系统配置", systemConfig); err != nil {
+		return nil, err
+	}
+	return systemConfig, nil
+}
+
 // GetMetricsConfig 获取指标配置
 func (s *PropertyService) GetMetricsConfig(ctx context.Context) models.MetricsConfig {
 	var config models.MetricsConfig
@@ -165,20 +371,50 @@ type defaultPropertyConfig struct {
 	Value interface{}
 }
 
+// GetAlertSnoozes 获取告警暂停通知列表，未设置时返回空列表
+func (s *PropertyService) GetAlertSnoozes(ctx context.Context) ([]models.AlertSnooze, error) {
+	var snoozes []models.AlertSnooze
+	err := s.GetValue(ctx, PropertyIDAlertSnoozes, &snoozes)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return snoozes, nil
+}
+
+// SetAlertSnoozes 设置告警暂停通知列表
+func (s *PropertyService) SetAlertSnoozes(ctx context.Context, snoozes []models.AlertSnooze) error {
+	return s.Set(ctx, PropertyIDAlertSnoozes, "告警暂停通知", snoozes)
+}
+
+// GetSilenceRules 获取静默规则列表，未设置时返回空列表
+func (s *PropertyService) GetSilenceRules(ctx context.Context) ([]models.SilenceRule, error) {
+	var rules []models.SilenceRule
+	err := s.GetValue(ctx, PropertyIDSilenceRules, &rules)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return rules, nil
+}
+
+// SetSilenceRules 设置静默规则列表
+func (s *PropertyService) SetSilenceRules(ctx context.Context, rules []models.SilenceRule) error {
+	return s.Set(ctx, PropertyIDSilenceRules, "静默规则", rules)
+}
+
 // InitializeDefaultConfigs 初始化默认配置（如果数据库中不存在）
 func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 	// 定义所有需要初始化的默认配置
 	defaultConfigs := []defaultPropertyConfig{
 		{
-			ID:   PropertyIDSystemConfig,
-			Name: "系统配置",
-			Value: models.SystemConfig{
-				SystemNameZh: "皮卡监控",
-				SystemNameEn: "Pika Monitor",
-				LogoBase64:   web.DefaultLogoBase64(),
-				ICPCode:      "",
-				DefaultView:  "grid",
-			},
+			ID:    PropertyIDSystemConfig,
+			Name:  "系统配置",
+			Value: defaultSystemConfig(),
 		},
 		{
 			ID:    PropertyIDNotificationChannels,
@@ -217,6 +453,7 @@ func (s *PropertyService) InitializeDefaultConfigs(ctx context.Context) error {
 					AgentOfflineEnabled:  true,
 					AgentOfflineDuration: 300, // 5分钟
 				},
+				TrendSamples: 5,
 			},
 		},
 	}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RecipientResult 多接收方渠道中单个接收方的投递结果
+type RecipientResult struct {
+	Recipient string `json:"recipient"`       // 接收方（邮箱地址或脱敏后的机器人 token）
+	Success   bool   `json:"success"`         // 是否投递成功
+	Error     string `json:"error,omitempty"` // 失败原因
+}
+
+// RecipientError 部分或全部接收方投递失败
+type RecipientError struct {
+	Results []RecipientResult
+}
+
+func (e *RecipientError) Error() string {
+	var failed []string
+	for _, r := range e.Results {
+		if !r.Success {
+			failed = append(failed, fmt.Sprintf("%s: %s", r.Recipient, r.Error))
+		}
+	}
+	return fmt.Sprintf("%d/%d 个接收方投递成功，失败: %s",
+		len(e.Results)-len(failed), len(e.Results), strings.Join(failed, "; "))
+}
+
+// partialDelivery 错误是否为部分接收方投递成功的 *RecipientError
+// 此时整体重发会让已收到的接收方收到重复消息
+func partialDelivery(err error) bool {
+	var recipientErr *RecipientError
+	if !errors.As(err, &recipientErr) {
+		return false
+	}
+	for _, r := range recipientErr.Results {
+		if r.Success {
+			return true
+		}
+	}
+	return false
+}
+
+// recipientResultsError 汇总各接收方的投递结果，存在失败时返回 *RecipientError
+func recipientResultsError(results []RecipientResult) error {
+	for _, r := range results {
+		if !r.Success {
+			return &RecipientError{Results: results}
+		}
+	}
+	return nil
+}
+
+// maskToken 对机器人 token 脱敏，仅保留前几位用于识别
+func maskToken(token string) string {
+	if len(token) <= 6 {
+		return "***"
+	}
+	return token[:6] + "***"
+}
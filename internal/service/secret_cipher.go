@@ -0,0 +1,156 @@
+package service
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/config"
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// secretKeyEnv 通知渠道密钥加密密钥的环境变量，优先于配置文件中的 Notification.SecretKey
+const secretKeyEnv = "PIKA_SECRET_KEY"
+
+// encryptedSecretPrefix 已加密字段的前缀，不带前缀的值视为明文（兼容加密前保存的配置，下次保存时加密）
+const encryptedSecretPrefix = "enc:v1:"
+
+// secretCipher 使用 AES-GCM 加密通知渠道配置中的敏感字段
+type secretCipher struct {
+	aead cipher.AEAD
+}
+
+// newSecretCipher 由任意长度的密钥派生 AES-256 密钥，密钥为空时返回 nil（不加密）
+func newSecretCipher(key string) (*secretCipher, error) {
+	if key == "" {
+		return nil, nil
+	}
+	sum := sha256.Sum256([]byte(key))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &secretCipher{aead: aead}, nil
+}
+
+// notificationSecretKey 读取加密密钥，环境变量优先
+func notificationSecretKey(cfg *config.AppConfig) string {
+	if key := os.Getenv(secretKeyEnv); key != "" {
+		return key
+	}
+	if cfg != nil && cfg.Notification != nil {
+		return cfg.Notification.SecretKey
+	}
+	return ""
+}
+
+// Encrypt 加密明文，已加密的值和未配置密钥（c 为 nil）时原样返回
+func (c *secretCipher) Encrypt(plaintext string) (string, error) {
+	if c == nil || plaintext == "" || strings.HasPrefix(plaintext, encryptedSecretPrefix) {
+		return plaintext, nil
+	}
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedSecretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密密文，未加密的明文原样返回
+func (c *secretCipher) Decrypt(value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedSecretPrefix)
+	if !ok {
+		return value, nil
+	}
+	if c == nil {
+		return "", fmt.Errorf("敏感字段已加密，但未配置加密密钥（%s 或 Notification.SecretKey）", secretKeyEnv)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("密文格式错误: %w", err)
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("密文长度错误")
+	}
+	plaintext, err := c.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("解密失败，请检查加密密钥是否变更: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// channelSecretKeys 各渠道类型中标记为敏感的配置项
+// 在 init 中由注册表生成，避免与 channelRegistry 形成初始化循环
+var channelSecretKeys map[string]map[string]bool
+
+func init() {
+	channelSecretKeys = make(map[string]map[string]bool, len(channelRegistry))
+	for _, def := range channelRegistry {
+		keys := make(map[string]bool)
+		for _, field := range def.Fields {
+			if field.Secret {
+				keys[field.Key] = true
+			}
+		}
+		channelSecretKeys[def.Type] = keys
+	}
+}
+
+// transformChannelSecrets 返回敏感字段经过 fn 处理后的配置副本，非敏感字段保持不变
+// 嵌套的对象和数组（如钉钉的 robots）按相同的字段名处理；
+// 敏感字段本身是对象或数组时（如 Webhook 的 headers），其中所有的值都视为敏感
+func transformChannelSecrets(channel models.NotificationChannelConfig, fn func(string) (string, error)) (models.NotificationChannelConfig, error) {
+	keys := channelSecretKeys[channel.Type]
+	if len(keys) == 0 || channel.Config == nil {
+		return channel, nil
+	}
+	transformed, err := transformSecretValue(channel.Config, false, keys, fn)
+	if err != nil {
+		return channel, err
+	}
+	channel.Config = transformed.(map[string]interface{})
+	return channel, nil
+}
+
+func transformSecretValue(value interface{}, secret bool, keys map[string]bool, fn func(string) (string, error)) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			transformed, err := transformSecretValue(item, secret || keys[k], keys, fn)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			result[k] = transformed
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			transformed, err := transformSecretValue(item, secret, keys, fn)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = transformed
+		}
+		return result, nil
+	case string:
+		if secret {
+			return fn(v)
+		}
+		return v, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestTransformChannelSecrets(t *testing.T) {
+	secrets, err := newSecretCipher("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	channel := models.NotificationChannelConfig{
+		Type: "dingtalk",
+		Config: map[string]interface{}{
+			"secretKey": "token-1",
+			"msgType":   "markdown",
+			"robots": []interface{}{
+				map[string]interface{}{"secretKey": "token-2", "signSecret": "sign-2"},
+			},
+		},
+	}
+
+	encrypted, err := transformChannelSecrets(channel, secrets.Encrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	robot := encrypted.Config["robots"].([]interface{})[0].(map[string]interface{})
+	for _, value := range []interface{}{encrypted.Config["secretKey"], robot["secretKey"], robot["signSecret"]} {
+		if !strings.HasPrefix(value.(string), encryptedSecretPrefix) {
+			t.Fatalf("敏感字段未加密: %v", value)
+		}
+	}
+	if encrypted.Config["msgType"] != "markdown" {
+		t.Fatalf("非敏感字段不应加密: %v", encrypted.Config["msgType"])
+	}
+	if channel.Config["secretKey"] != "token-1" {
+		t.Fatal("加密不应修改原配置")
+	}
+
+	decrypted, err := transformChannelSecrets(encrypted, secrets.Decrypt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	robot = decrypted.Config["robots"].([]interface{})[0].(map[string]interface{})
+	if decrypted.Config["secretKey"] != "token-1" || robot["secretKey"] != "token-2" || robot["signSecret"] != "sign-2" {
+		t.Fatalf("解密结果不正确: %+v", decrypted.Config)
+	}
+
+	// 加密前保存的明文配置仍可读取
+	if plain, err := secrets.Decrypt("token-plain"); err != nil || plain != "token-plain" {
+		t.Fatalf("Decrypt(明文) = %q, %v", plain, err)
+	}
+	// 未配置密钥时无法读取已加密的值
+	var disabled *secretCipher
+	if _, err := disabled.Decrypt(encrypted.Config["secretKey"].(string)); err == nil {
+		t.Fatal("未配置密钥时解密应返回错误")
+	}
+}
+
+func TestRestoreRedactedSecrets(t *testing.T) {
+	existing := []models.NotificationChannelConfig{
+		{ID: "ch-1", Type: "dingtalk", Config: map[string]interface{}{
+			"secretKey":  "token-1",
+			"signSecret": "sign-1",
+			"robots":     []interface{}{map[string]interface{}{"secretKey": "token-2"}},
+		}},
+		{Type: "email", Config: map[string]interface{}{"smtpHost": "smtp.example.com", "password": "smtp-pass"}},
+	}
+
+	// 前端拿到的是脱敏后的配置，原样提交时应保留已保存的值
+	submitted := make([]models.NotificationChannelConfig, len(existing))
+	for i, channel := range existing {
+		submitted[i] = RedactChannelSecrets(channel)
+	}
+	if submitted[0].Config["secretKey"] != RedactedSecret || submitted[1].Config["password"] != RedactedSecret {
+		t.Fatalf("敏感字段未脱敏: %+v", submitted)
+	}
+	// 修改了的敏感字段使用新值
+	submitted[0].Config["signSecret"] = "sign-new"
+
+	restored := restoreRedactedSecrets(submitted, existing)
+	robot := restored[0].Config["robots"].([]interface{})[0].(map[string]interface{})
+	if restored[0].Config["secretKey"] != "token-1" || robot["secretKey"] != "token-2" {
+		t.Fatalf("未修改的敏感字段应保留原值: %+v", restored[0].Config)
+	}
+	if restored[0].Config["signSecret"] != "sign-new" {
+		t.Fatalf("修改的敏感字段应使用新值: %v", restored[0].Config["signSecret"])
+	}
+	if restored[1].Config["password"] != "smtp-pass" {
+		t.Fatalf("没有 ID 的渠道应按位置保留原值: %v", restored[1].Config["password"])
+	}
+
+	// 新增渠道没有原值，占位值清空后由校验提示缺少字段
+	added := restoreRedactedSecrets([]models.NotificationChannelConfig{
+		{ID: "ch-new", Type: "dingtalk", Config: map[string]interface{}{"secretKey": RedactedSecret}},
+	}, existing)
+	if added[0].Config["secretKey"] != "" {
+		t.Fatalf("新增渠道的占位值应清空: %v", added[0].Config["secretKey"])
+	}
+}
+
+func TestTransformChannelSecretsCoversAllSenderSecrets(t *testing.T) {
+	secrets, err := newSecretCipher("test-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		channel models.NotificationChannelConfig
+		secrets func(config map[string]interface{}) []interface{}
+	}{
+		{
+			models.NotificationChannelConfig{Type: "wecom", Config: map[string]interface{}{"mode": "appchat", "corpId": "corp", "corpSecret": "corp-secret", "chatId": "chat"}},
+			func(config map[string]interface{}) []interface{} { return []interface{}{config["corpSecret"]} },
+		},
+		{
+			models.NotificationChannelConfig{Type: "feishu", Config: map[string]interface{}{"secretKey": "token", "encryptKey": "encrypt-key", "verificationToken": "verification-token"}},
+			func(config map[string]interface{}) []interface{} {
+				return []interface{}{config["encryptKey"], config["verificationToken"]}
+			},
+		},
+		{
+			models.NotificationChannelConfig{Type: "webhook", Config: map[string]interface{}{"url": "https://example.com", "headers": map[string]interface{}{"Authorization": "Bearer secret"}}},
+			func(config map[string]interface{}) []interface{} {
+				return []interface{}{config["headers"].(map[string]interface{})["Authorization"]}
+			},
+		},
+	}
+	for _, tt := range tests {
+		encrypted, err := transformChannelSecrets(tt.channel, secrets.Encrypt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, value := range tt.secrets(encrypted.Config) {
+			if !strings.HasPrefix(value.(string), encryptedSecretPrefix) {
+				t.Errorf("%s 的敏感字段未加密: %v", tt.channel.Type, value)
+			}
+		}
+		redacted := RedactChannelSecrets(tt.channel)
+		for _, value := range tt.secrets(redacted.Config) {
+			if value != RedactedSecret {
+				t.Errorf("%s 的敏感字段未脱敏: %v", tt.channel.Type, value)
+			}
+		}
+	}
+	if config := RedactChannelSecrets(tests[0].channel).Config; config["corpId"] != "corp" {
+		t.Errorf("非敏感字段不应脱敏: %v", config["corpId"])
+	}
+}
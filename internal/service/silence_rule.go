@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"github.com/go-orz/orz"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// silenceRuleActive 判断静默规则在指定时间是否生效
+func silenceRuleActive(rule models.SilenceRule, now time.Time) (bool, error) {
+	ms := now.UnixMilli()
+	if rule.StartAt > 0 && ms < rule.StartAt {
+		return false, nil
+	}
+	if rule.Expired(ms) {
+		return false, nil
+	}
+	if rule.Schedule == nil {
+		return true, nil
+	}
+	schedule, err := newChannelSchedule(rule.Schedule.Start, rule.Schedule.End, rule.Schedule.Days, rule.Schedule.Timezone)
+	if err != nil {
+		return false, err
+	}
+	return schedule.Active(now), nil
+}
+
+// matchSilenceRule 返回第一条命中该告警的生效中的静默规则，未命中时返回 nil
+func matchSilenceRule(rules []models.SilenceRule, record *models.AlertRecord, now time.Time) *models.SilenceRule {
+	for i := range rules {
+		if !rules[i].Matches(record.AgentID, record.AlertType) {
+			continue
+		}
+		if active, err := silenceRuleActive(rules[i], now); err == nil && active {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// silencedBy 返回命中该告警的静默规则，未命中或读取失败时返回 nil
+func (n *Notifier) silencedBy(ctx context.Context, record *models.AlertRecord) *models.SilenceRule {
+	if n.propertyService == nil {
+		return nil
+	}
+	rules, err := n.propertyService.GetSilenceRules(ctx)
+	if err != nil {
+		n.logger.Warn("获取静默规则失败", zap.Error(err))
+		return nil
+	}
+	return matchSilenceRule(rules, record, time.Now())
+}
+
+// ListSilenceRules 列出未过期的静默规则
+func (s *AlertService) ListSilenceRules(ctx context.Context) ([]models.SilenceRule, error) {
+	rules, err := s.propertyService.GetSilenceRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UnixMilli()
+	result := make([]models.SilenceRule, 0, len(rules))
+	for _, rule := range rules {
+		if !rule.Expired(now) {
+			result = append(result, rule)
+		}
+	}
+	return result, nil
+}
+
+// CreateSilenceRule 创建静默规则，同时清理已过期的规则
+func (s *AlertService) CreateSilenceRule(ctx context.Context, rule models.SilenceRule) (*models.SilenceRule, error) {
+	if rule.StartAt > 0 && rule.EndAt > 0 && rule.EndAt <= rule.StartAt {
+		return nil, orz.NewError(400, "结束时间必须晚于开始时间")
+	}
+	if rule.EndAt == 0 && rule.Schedule == nil {
+		return nil, orz.NewError(400, "结束时间和重复时间段不能同时为空")
+	}
+	if rule.Schedule != nil {
+		if _, err := newChannelSchedule(rule.Schedule.Start, rule.Schedule.End, rule.Schedule.Days, rule.Schedule.Timezone); err != nil {
+			return nil, orz.NewError(400, "静默时间段无效: "+err.Error())
+		}
+	}
+	if rule.AgentID != "" {
+		if _, err := s.agentRepo.FindById(ctx, rule.AgentID); err != nil {
+			return nil, err
+		}
+	}
+
+	s.silenceMu.Lock()
+	defer s.silenceMu.Unlock()
+
+	rules, err := s.propertyService.GetSilenceRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixMilli()
+	rule.ID = uuid.NewString()
+	rule.CreatedAt = now
+
+	kept := make([]models.SilenceRule, 0, len(rules)+1)
+	for _, item := range rules {
+		if !item.Expired(now) {
+			kept = append(kept, item)
+		}
+	}
+	kept = append(kept, rule)
+
+	if err := s.propertyService.SetSilenceRules(ctx, kept); err != nil {
+		return nil, err
+	}
+	s.logger.Info("静默规则已创建",
+		zap.String("id", rule.ID),
+		zap.String("agentId", rule.AgentID),
+		zap.String("alertType", rule.AlertType),
+	)
+	return &rule, nil
+}
+
+// DeleteSilenceRule 删除静默规则
+func (s *AlertService) DeleteSilenceRule(ctx context.Context, id string) error {
+	s.silenceMu.Lock()
+	defer s.silenceMu.Unlock()
+
+	rules, err := s.propertyService.GetSilenceRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	kept := make([]models.SilenceRule, 0, len(rules))
+	for _, item := range rules {
+		if item.ID == id {
+			found = true
+			continue
+		}
+		kept = append(kept, item)
+	}
+	if !found {
+		return orz.NewError(404, "静默规则不存在")
+	}
+
+	if err := s.propertyService.SetSilenceRules(ctx, kept); err != nil {
+		return err
+	}
+	s.logger.Info("静默规则已删除", zap.String("id", id))
+	return nil
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// slackLevelColors 告警级别对应的 Slack 附件颜色
+var slackLevelColors = map[string]string{
+	"info":     "#439FE0",
+	"warning":  "warning",
+	"critical": "danger",
+}
+
+// SlackConfig Slack 渠道配置
+type SlackConfig struct {
+	WebhookURL string // Incoming Webhook 地址
+}
+
+// ParseSlackConfig 解析并校验 Slack 渠道配置
+// 配置格式: { "webhookUrl": "https://hooks.slack.com/services/xxx" }
+func ParseSlackConfig(config map[string]interface{}) (SlackConfig, error) {
+	var cfg SlackConfig
+	cfg.WebhookURL, _ = config["webhookUrl"].(string)
+	cfg.WebhookURL = strings.TrimSpace(cfg.WebhookURL)
+	if cfg.WebhookURL == "" {
+		return cfg, fmt.Errorf("Slack 配置缺少 webhookUrl")
+	}
+	if !strings.HasPrefix(cfg.WebhookURL, "https://") && !strings.HasPrefix(cfg.WebhookURL, "http://") {
+		return cfg, fmt.Errorf("Slack webhookUrl 必须以 http:// 或 https:// 开头")
+	}
+	return cfg, nil
+}
+
+// slackTitle 附件标题，告警与恢复使用不同标题
+func (n *Notifier) slackTitle(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord) string {
+	if content, ok := n.buildMessageContent(agent, record, n.messageOptions(ctx, config)); ok {
+		return content.Title
+	}
+	return record.AlertType
+}
+
+// sendSlackByConfig 根据配置发送 Slack 通知，消息放在按告警级别着色的附件中
+func (n *Notifier) sendSlackByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseSlackConfig(config)
+	if err != nil {
+		return err
+	}
+
+	title := n.slackTitle(ctx, config, agent, record)
+	attachment := map[string]interface{}{
+		"title":    title,
+		"text":     message,
+		"fallback": title,
+	}
+	if color, ok := slackLevelColors[record.Level]; ok {
+		attachment["color"] = color
+	}
+	body := map[string]interface{}{
+		"attachments": []interface{}{attachment},
+	}
+	_, err = n.sendJSONRequest(ctx, cfg.WebhookURL, body)
+	return err
+}
+
+// sendSlackRaw 发送已构建好的纯文本消息
+func (n *Notifier) sendSlackRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseSlackConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = n.sendJSONRequest(ctx, cfg.WebhookURL, map[string]interface{}{"text": message})
+	return err
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+// teamsLevelColors 告警级别对应的 MessageCard themeColor
+var teamsLevelColors = map[string]string{
+	"info":     "439FE0",
+	"warning":  "FFA500",
+	"critical": "E74C3C",
+}
+
+// teamsAdaptiveColors 告警级别对应的 Adaptive Card 标题颜色
+var teamsAdaptiveColors = map[string]string{
+	"info":     "Accent",
+	"warning":  "Warning",
+	"critical": "Attention",
+}
+
+// TeamsConfig Microsoft Teams 渠道配置
+type TeamsConfig struct {
+	WebhookURL string // Incoming Webhook 或 Power Automate 工作流地址
+}
+
+// ParseTeamsConfig 解析并校验 Microsoft Teams 渠道配置
+// 配置格式: { "webhookUrl": "https://xxx.webhook.office.com/webhookb2/xxx" }
+func ParseTeamsConfig(config map[string]interface{}) (TeamsConfig, error) {
+	var cfg TeamsConfig
+	cfg.WebhookURL, _ = config["webhookUrl"].(string)
+	cfg.WebhookURL = strings.TrimSpace(cfg.WebhookURL)
+	if cfg.WebhookURL == "" {
+		return cfg, fmt.Errorf("Teams 配置缺少 webhookUrl")
+	}
+	if !strings.HasPrefix(cfg.WebhookURL, "https://") && !strings.HasPrefix(cfg.WebhookURL, "http://") {
+		return cfg, fmt.Errorf("Teams webhookUrl 必须以 http:// 或 https:// 开头")
+	}
+	return cfg, nil
+}
+
+// isTeamsWorkflowURL 是否为 Power Automate 工作流地址
+// Teams 的 Incoming Webhook（Office 365 连接器）正在迁移到工作流，工作流只接受 Adaptive Card
+func isTeamsWorkflowURL(webhookURL string) bool {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	return strings.HasSuffix(host, ".logic.azure.com") || strings.HasSuffix(host, ".powerplatform.com")
+}
+
+// teamsFact 卡片中的一个键值字段
+type teamsFact struct {
+	Name  string
+	Value string
+}
+
+// teamsMessageText Teams 卡片文本按 Markdown 渲染，单个换行会被合并，需转为段落
+func teamsMessageText(message string) string {
+	return strings.ReplaceAll(message, "\n", "\n\n")
+}
+
+// buildTeamsMessageCard 构造旧版 MessageCard 消息体
+func buildTeamsMessageCard(title, text, level string, facts []teamsFact) map[string]interface{} {
+	section := map[string]interface{}{"text": teamsMessageText(text)}
+	if len(facts) > 0 {
+		items := make([]interface{}, 0, len(facts))
+		for _, fact := range facts {
+			items = append(items, map[string]string{"name": fact.Name, "value": fact.Value})
+		}
+		section["facts"] = items
+	}
+	card := map[string]interface{}{
+		"@type":    "MessageCard",
+		"@context": "https://schema.org/extensions",
+		"summary":  title,
+		"title":    title,
+		"sections": []interface{}{section},
+	}
+	if color, ok := teamsLevelColors[level]; ok {
+		card["themeColor"] = color
+	}
+	return card
+}
+
+// buildTeamsAdaptiveCard 构造工作流使用的 Adaptive Card 消息体
+func buildTeamsAdaptiveCard(title, text, level string, facts []teamsFact) map[string]interface{} {
+	var body []interface{}
+	if title != "" {
+		heading := map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   title,
+			"weight": "Bolder",
+			"size":   "Medium",
+			"wrap":   true,
+		}
+		if color, ok := teamsAdaptiveColors[level]; ok {
+			heading["color"] = color
+		}
+		body = append(body, heading)
+	}
+	if len(facts) > 0 {
+		items := make([]interface{}, 0, len(facts))
+		for _, fact := range facts {
+			items = append(items, map[string]string{"title": fact.Name, "value": fact.Value})
+		}
+		body = append(body, map[string]interface{}{"type": "FactSet", "facts": items})
+	}
+	body = append(body, map[string]interface{}{
+		"type": "TextBlock",
+		"text": teamsMessageText(text),
+		"wrap": true,
+	})
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]interface{}{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+				},
+			},
+		},
+	}
+}
+
+// sendTeamsCard 按地址类型选择 Adaptive Card 或 MessageCard 发送
+func (n *Notifier) sendTeamsCard(ctx context.Context, cfg TeamsConfig, title, text, level string, facts []teamsFact) error {
+	var body map[string]interface{}
+	if isTeamsWorkflowURL(cfg.WebhookURL) {
+		body = buildTeamsAdaptiveCard(title, text, level, facts)
+	} else {
+		body = buildTeamsMessageCard(title, text, level, facts)
+	}
+	_, err := n.sendJSONRequest(ctx, cfg.WebhookURL, body)
+	return err
+}
+
+// sendTeamsByConfig 根据配置发送 Teams 通知，主机、IP 和阈值以字段形式展示
+func (n *Notifier) sendTeamsByConfig(ctx context.Context, config map[string]interface{}, agent *models.Agent, record *models.AlertRecord, message string) error {
+	cfg, err := ParseTeamsConfig(config)
+	if err != nil {
+		return err
+	}
+
+	opts := n.messageOptions(ctx, config)
+	title := record.AlertType
+	if content, ok := n.buildMessageContent(agent, record, opts); ok {
+		title = content.Title
+	}
+	facts := []teamsFact{
+		{Name: opts.text("label.probe"), Value: agent.Name},
+		{Name: opts.text("label.host"), Value: agent.Hostname},
+		{Name: opts.text("label.ip"), Value: agent.IP},
+	}
+	if hasMetricValue(record.AlertType) {
+		facts = append(facts,
+			teamsFact{Name: opts.text("label.threshold"), Value: formatAlertValue(record.AlertType, record.Threshold, opts)},
+			teamsFact{Name: opts.text("label.value"), Value: formatAlertValue(record.AlertType, record.ActualValue, opts)},
+		)
+	}
+	return n.sendTeamsCard(ctx, cfg, title, message, record.Level, facts)
+}
+
+// sendTeamsRaw 发送已构建好的纯文本消息
+func (n *Notifier) sendTeamsRaw(ctx context.Context, config map[string]interface{}, message string) error {
+	cfg, err := ParseTeamsConfig(config)
+	if err != nil {
+		return err
+	}
+	title, _, _ := strings.Cut(message, "\n")
+	return n.sendTeamsCard(ctx, cfg, title, message, "", nil)
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// telegramTextByteLimit Telegram 文本消息最多 4096 个字符，按字节截断可保证不超限
+const telegramTextByteLimit = 4096
+
+// TelegramConfig Telegram 机器人渠道配置
+type TelegramConfig struct {
+	BotToken        string // 机器人 Token
+	ChatID          string // 会话ID，可为数字ID或 @channelusername
+	MessageThreadID int64  // 话题群组的话题ID，可为 0
+}
+
+// ParseTelegramConfig 解析并校验 Telegram 渠道配置
+// 配置格式: { "botToken": "123456:ABC-xxx", "chatId": "-1001234567890", "messageThreadId": 42 }
+func ParseTelegramConfig(config map[string]interface{}) (TelegramConfig, error) {
+	var cfg TelegramConfig
+	cfg.BotToken, _ = config["botToken"].(string)
+	cfg.BotToken = strings.TrimSpace(cfg.BotToken)
+	if cfg.BotToken == "" {
+		return cfg, fmt.Errorf("Telegram 配置缺少 botToken")
+	}
+
+	// chatId 在 JSON 中可能是数字
+	switch chatID := config["chatId"].(type) {
+	case string:
+		cfg.ChatID = strings.TrimSpace(chatID)
+	case float64:
+		cfg.ChatID = strconv.FormatInt(int64(chatID), 10)
+	}
+	if cfg.ChatID == "" {
+		return cfg, fmt.Errorf("Telegram 配置缺少 chatId")
+	}
+
+	switch threadID := config["messageThreadId"].(type) {
+	case nil:
+	case float64:
+		cfg.MessageThreadID = int64(threadID)
+	case string:
+		if threadID != "" {
+			id, err := strconv.ParseInt(threadID, 10, 64)
+			if err != nil {
+				return cfg, fmt.Errorf("Telegram 配置的 messageThreadId 必须为数字")
+			}
+			cfg.MessageThreadID = id
+		}
+	default:
+		return cfg, fmt.Errorf("Telegram 配置的 messageThreadId 必须为数字")
+	}
+	return cfg, nil
+}
+
+// TelegramResult Telegram Bot API 响应
+type TelegramResult struct {
+	OK          bool   `json:"ok"`
+	Description string `json:"description"`
+	Result      struct {
+		MessageID int64 `json:"message_id"`
+	} `json:"result"`
+}
+
+// sendTelegram 发送 Telegram 消息，返回平台消息ID
+// Bot API 出错时同样返回 {"ok":false,"description":...}，直接以 description 作为错误信息
+func (n *Notifier) sendTelegram(ctx context.Context, cfg TelegramConfig, message string) (string, error) {
+	body := map[string]interface{}{
+		"chat_id":    cfg.ChatID,
+		"text":       html.EscapeString(message),
+		"parse_mode": "HTML",
+	}
+	if cfg.MessageThreadID != 0 {
+		body["message_thread_id"] = cfg.MessageThreadID
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return "", fmt.Errorf("序列化请求体失败: %w", err)
+	}
+
+	apiURL := "https://api.telegram.org/bot" + cfg.BotToken + "/sendMessage"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("创建请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.doHTTP(req)
+	if err != nil {
+		// 错误信息中的 URL 包含机器人 Token，只保留底层错误
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return "", fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(resp.Body)
+	var result TelegramResult
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("请求失败，状态码: %d, 响应: %s", resp.StatusCode, string(respBody))
+	}
+	if !result.OK {
+		if result.Description == "" {
+			return "", fmt.Errorf("请求失败，状态码: %d", resp.StatusCode)
+		}
+		return "", fmt.Errorf("%s", result.Description)
+	}
+	return strconv.FormatInt(result.Result.MessageID, 10), nil
+}
+
+// sendTelegramByConfig 根据配置发送 Telegram 通知，返回平台消息ID
+func (n *Notifier) sendTelegramByConfig(ctx context.Context, config map[string]interface{}, message string) (string, error) {
+	cfg, err := ParseTelegramConfig(config)
+	if err != nil {
+		return "", err
+	}
+	return n.sendTelegram(ctx, cfg, message)
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// tokenRefreshSkew 令牌提前刷新的时间余量，避免令牌在请求途中过期
+const tokenRefreshSkew = 5 * time.Minute
+
+// tokenFetcher 获取新的访问令牌，返回令牌及其有效期
+type tokenFetcher func(ctx context.Context) (token string, expiresIn time.Duration, err error)
+
+// cachedToken 缓存的访问令牌
+type cachedToken struct {
+	mu        sync.Mutex
+	value     string
+	expiresAt time.Time
+}
+
+// tokenCache 访问令牌缓存（按 appid 区分）
+// 企业微信应用、飞书自建应用等基于 access_token 的渠道共用同一份实现
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+	// now 当前时间，测试时可替换
+	now func() time.Time
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		tokens: make(map[string]*cachedToken),
+		now:    time.Now,
+	}
+}
+
+// entry 获取指定 key 的缓存项，不存在时创建
+func (c *tokenCache) entry(key string) *cachedToken {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t, ok := c.tokens[key]
+	if !ok {
+		t = &cachedToken{}
+		c.tokens[key] = t
+	}
+	return t
+}
+
+// Get 获取令牌，缓存不存在或即将过期时调用 fetch 刷新
+// 同一 key 的并发请求只会触发一次刷新，不同 key 之间互不阻塞
+func (c *tokenCache) Get(ctx context.Context, key string, fetch tokenFetcher) (string, error) {
+	t := c.entry(key)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := c.now()
+	if t.value != "" && now.Add(tokenRefreshSkew).Before(t.expiresAt) {
+		return t.value, nil
+	}
+
+	token, expiresIn, err := fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.value = token
+	t.expiresAt = now.Add(expiresIn)
+	return token, nil
+}
+
+// Invalidate 使指定 key 的令牌失效（例如平台返回令牌过期时）
+func (c *tokenCache) Invalidate(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tokens, key)
+}
@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestTokenCacheRefreshOnExpiry(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	cache := newTokenCache()
+	cache.now = func() time.Time { return now }
+
+	calls := 0
+	fetch := func(ctx context.Context) (string, time.Duration, error) {
+		calls++
+		return fmt.Sprintf("token-%d", calls), 2 * time.Hour, nil
+	}
+
+	ctx := context.Background()
+	token, err := cache.Get(ctx, "app", fetch)
+	if err != nil || token != "token-1" {
+		t.Fatalf("unexpected first token: %q, %v", token, err)
+	}
+
+	// 有效期内命中缓存
+	now = now.Add(time.Hour)
+	if token, _ = cache.Get(ctx, "app", fetch); token != "token-1" || calls != 1 {
+		t.Fatalf("expected cached token, got %q after %d fetches", token, calls)
+	}
+
+	// 进入提前刷新窗口后重新获取
+	now = now.Add(time.Hour - tokenRefreshSkew)
+	if token, _ = cache.Get(ctx, "app", fetch); token != "token-2" || calls != 2 {
+		t.Fatalf("expected refreshed token, got %q after %d fetches", token, calls)
+	}
+}
+
+func TestTokenCacheFetchError(t *testing.T) {
+	cache := newTokenCache()
+	_, err := cache.Get(context.Background(), "app", func(ctx context.Context) (string, time.Duration, error) {
+		return "", 0, errors.New("boom")
+	})
+	if err == nil {
+		t.Fatal("expected fetch error")
+	}
+
+	token, err := cache.Get(context.Background(), "app", func(ctx context.Context) (string, time.Duration, error) {
+		return "ok", time.Hour, nil
+	})
+	if err != nil || token != "ok" {
+		t.Fatalf("expected retry after failure, got %q, %v", token, err)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseVersion 解析形如 v1.2.3 / 1.2.3-beta 的版本号，忽略前缀 v 和预发布后缀
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	if version == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(version, ".")
+	result := make([]int, 0, len(parts))
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		result = append(result, n)
+	}
+	return result, true
+}
+
+// compareVersions 比较两个版本号，a < b 返回 -1，a == b 返回 0，a > b 返回 1
+// 任一版本号无法解析时 ok 为 false（例如开发版本 dev）
+func compareVersions(a, b string) (result int, ok bool) {
+	va, ok := parseVersion(a)
+	if !ok {
+		return 0, false
+	}
+	vb, ok := parseVersion(b)
+	if !ok {
+		return 0, false
+	}
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		if x < y {
+			return -1, true
+		}
+		if x > y {
+			return 1, true
+		}
+	}
+	return 0, true
+}
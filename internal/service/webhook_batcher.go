@@ -0,0 +1,193 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dushixiang/pika/internal/models"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultWebhookBatchMaxSize 默认每批最多告警数
+	defaultWebhookBatchMaxSize = 50
+	// defaultWebhookBatchFlushInterval 默认批次刷新间隔
+	defaultWebhookBatchFlushInterval = 5 * time.Second
+)
+
+// webhookBatchConfig 自定义Webhook批量发送配置
+// 配置格式: "batch": { "enabled": true, "maxSize": 50, "flushIntervalSeconds": 5 }
+type webhookBatchConfig struct {
+	Enabled       bool
+	MaxSize       int
+	FlushInterval time.Duration
+}
+
+// parseWebhookBatchConfig 解析批量发送配置，未配置时为单条发送
+func parseWebhookBatchConfig(config map[string]interface{}) webhookBatchConfig {
+	result := webhookBatchConfig{
+		MaxSize:       defaultWebhookBatchMaxSize,
+		FlushInterval: defaultWebhookBatchFlushInterval,
+	}
+	batch, ok := config["batch"].(map[string]interface{})
+	if !ok {
+		return result
+	}
+	result.Enabled, _ = batch["enabled"].(bool)
+	if v, ok := batch["maxSize"].(float64); ok && v > 0 {
+		result.MaxSize = int(v)
+	}
+	if v, ok := batch["flushIntervalSeconds"].(float64); ok && v > 0 {
+		result.FlushInterval = time.Duration(v * float64(time.Second))
+	}
+	return result
+}
+
+// errWebhookQueued 告警已加入批次等待发送，发送结果在批次刷新时记录
+var errWebhookQueued = errors.New("告警已加入Webhook批次")
+
+type directDeliveryKey struct{}
+
+// withDirectDelivery 跳过批量缓存立即发送，用于重试等需要同步拿到发送结果的场景
+func withDirectDelivery(ctx context.Context) context.Context {
+	return context.WithValue(ctx, directDeliveryKey{}, true)
+}
+
+// directDeliveryFrom ctx 是否要求立即发送
+func directDeliveryFrom(ctx context.Context) bool {
+	direct, _ := ctx.Value(directDeliveryKey{}).(bool)
+	return direct
+}
+
+// webhookBatchItem 批次中的一条告警，保留渠道和告警记录以便刷新后逐条记录发送结果
+type webhookBatchItem struct {
+	body     interface{}
+	channel  models.NotificationChannelConfig
+	record   *models.AlertRecord
+	queuedAt time.Time
+}
+
+// webhookBatch 待发送的一批告警
+type webhookBatch struct {
+	config map[string]interface{}
+	items  []webhookBatchItem
+	timer  *time.Timer
+}
+
+// webhookBatcher 按 Webhook 地址缓存告警，达到批次上限或刷新间隔后一次性发送
+type webhookBatcher struct {
+	mu      sync.Mutex
+	batches map[string]*webhookBatch
+	flush   func(config map[string]interface{}, items []webhookBatchItem)
+}
+
+func newWebhookBatcher(flush func(config map[string]interface{}, items []webhookBatchItem)) *webhookBatcher {
+	return &webhookBatcher{
+		batches: make(map[string]*webhookBatch),
+		flush:   flush,
+	}
+}
+
+// Add 添加一条告警到批次
+func (b *webhookBatcher) Add(key string, config map[string]interface{}, item webhookBatchItem, cfg webhookBatchConfig) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	batch, ok := b.batches[key]
+	if !ok {
+		batch = &webhookBatch{}
+		b.batches[key] = batch
+		batch.timer = time.AfterFunc(cfg.FlushInterval, func() {
+			b.flushKey(key, batch)
+		})
+	}
+	// 始终使用最新的配置发送
+	batch.config = config
+	batch.items = append(batch.items, item)
+
+	if len(batch.items) >= cfg.MaxSize {
+		batch.timer.Stop()
+		delete(b.batches, key)
+		go b.flush(batch.config, batch.items)
+	}
+}
+
+// flushKey 刷新间隔到期时发送批次
+func (b *webhookBatcher) flushKey(key string, batch *webhookBatch) {
+	b.mu.Lock()
+	// 批次可能已因达到上限而被发送
+	if b.batches[key] != batch {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.batches, key)
+	b.mu.Unlock()
+
+	b.flush(batch.config, batch.items)
+}
+
+// FlushAll 立即发送所有未到刷新时间的批次，用于服务停止前避免丢失告警
+func (b *webhookBatcher) FlushAll() {
+	b.mu.Lock()
+	batches := b.batches
+	b.batches = make(map[string]*webhookBatch)
+	for _, batch := range batches {
+		batch.timer.Stop()
+	}
+	b.mu.Unlock()
+
+	for _, batch := range batches {
+		b.flush(batch.config, batch.items)
+	}
+}
+
+// FlushWebhookBatches 立即发送所有缓存中的自定义Webhook批次
+func (n *Notifier) FlushWebhookBatches() {
+	n.batcher.FlushAll()
+}
+
+// flushWebhookBatch 以 JSON 数组的形式发送一批告警，并逐条记录发送结果
+func (n *Notifier) flushWebhookBatch(config map[string]interface{}, items []webhookBatchItem) {
+	err := n.sendWebhookBatch(config, items)
+	if err != nil {
+		n.logger.Error("批量发送自定义Webhook失败", zap.Int("count", len(items)), zap.String("error", deliveryErrorMessage(err)))
+	} else {
+		n.logger.Info("批量发送自定义Webhook成功", zap.Int("count", len(items)))
+	}
+	for i := range items {
+		item := &items[i]
+		n.stats.Record(item.channel.ID, item.channel.Type, item.channel.Name, err == nil, time.Now())
+		n.recordDeliveryAttempt(&item.channel, item.record, item.queuedAt, err)
+		if err != nil {
+			// 加入批次时已记入去重窗口，发送失败后撤销，使上游重试的相同告警仍能发送
+			n.dedup.Release(item.record)
+			n.deliveryFailed(item.channel, item.record, err)
+		}
+	}
+}
+
+// sendWebhookBatch 发送一批告警
+func (n *Notifier) sendWebhookBatch(config map[string]interface{}, items []webhookBatchItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	ctx = WithChannelHTTPOptions(ctx, config)
+
+	cfg, err := ParseWebhookConfig(config)
+	if err != nil {
+		return err
+	}
+	bodies := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		bodies = append(bodies, item.body)
+	}
+	data, err := json.Marshal(bodies)
+	if err != nil {
+		return fmt.Errorf("序列化批量告警失败: %w", err)
+	}
+	return n.doWebhookRequest(ctx, cfg, bytes.NewReader(data), "application/json")
+}
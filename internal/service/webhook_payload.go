@@ -0,0 +1,85 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+const (
+	// defaultWebhookSchemaVersion 未指定 schemaVersion 时使用的版本，保持与旧接收方兼容
+	defaultWebhookSchemaVersion = 1
+	// latestWebhookSchemaVersion 当前最新的请求体版本
+	latestWebhookSchemaVersion = 2
+)
+
+// parseWebhookSchemaVersion 解析渠道配置中固定的 schemaVersion
+func parseWebhookSchemaVersion(config map[string]interface{}) (int, error) {
+	raw, ok := config["schemaVersion"]
+	if !ok || raw == nil {
+		return defaultWebhookSchemaVersion, nil
+	}
+	version, ok := raw.(float64)
+	if !ok || version != float64(int(version)) || version < 1 || int(version) > latestWebhookSchemaVersion {
+		return 0, fmt.Errorf("不支持的 schemaVersion: %v，可选 1-%d", raw, latestWebhookSchemaVersion)
+	}
+	return int(version), nil
+}
+
+// buildWebhookPayload 按版本构建 json 模板的请求体
+// v1: 兼容飞书文本消息的 msg_type/text 结构，附带 agent、alert
+// v2: 去掉 msg_type/text 包装，消息放在顶层 message，agent、alert 结构不变
+func buildWebhookPayload(version int, message string, agent *models.Agent, record *models.AlertRecord) map[string]interface{} {
+	agentPayload := map[string]interface{}{
+		"id":       agent.ID,
+		"name":     agent.Name,
+		"hostname": agent.Hostname,
+		"ip":       agent.IP,
+	}
+	alertPayload := map[string]interface{}{
+		"type":        record.AlertType,
+		"level":       record.Level,
+		"status":      record.Status,
+		"message":     record.Message,
+		"threshold":   record.Threshold,
+		"actualValue": record.ActualValue,
+		"firedAt":     record.FiredAt,
+		"resolvedAt":  record.ResolvedAt,
+		"replay":      record.Replay,
+		"labels":      record.Labels,
+		"dedupKey":    record.DedupKey(),
+	}
+
+	switch version {
+	case 2:
+		return map[string]interface{}{
+			"schemaVersion": 2,
+			"message":       message,
+			"agent":         agentPayload,
+			"alert":         alertPayload,
+		}
+	default:
+		return map[string]interface{}{
+			"schemaVersion": 1,
+			"msg_type":      "text",
+			"text": map[string]string{
+				"content": message,
+			},
+			"agent": agentPayload,
+			"alert": alertPayload,
+		}
+	}
+}
+
+// structuredWebhookPayload payloadMode 为 structured 时的请求体，完整保留探针和告警记录的字段：
+//
+//	{
+//	  "agent":   { "id": "...", "name": "...", "hostname": "...", "ip": "...", "os": "...", ... },  // models.Agent
+//	  "record":  { "id": 1, "agentId": "...", "alertType": "cpu", "threshold": 80, "actualValue": 92.5, "firedAt": 1700000000000, ... },  // models.AlertRecord
+//	  "message": "..."  // 与文本模式相同的消息文本
+//	}
+type structuredWebhookPayload struct {
+	Agent   *models.Agent       `json:"agent"`
+	Record  *models.AlertRecord `json:"record"`
+	Message string              `json:"message"`
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+
+	"github.com/dushixiang/pika/internal/models"
+)
+
+func TestStructuredWebhookPayloadKeys(t *testing.T) {
+	payload := structuredWebhookPayload{
+		Agent:   &models.Agent{ID: "a1", Name: "web-1", IP: "10.0.0.1"},
+		Record:  &models.AlertRecord{AgentID: "a1", AlertType: "cpu", Threshold: 80, ActualValue: 92.5, FiredAt: 1700000000000},
+		Message: "CPU告警",
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]json.RawMessage
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	keys := make([]string, 0, len(decoded))
+	for k := range decoded {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if want := []string{"agent", "message", "record"}; len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] || keys[2] != want[2] {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(decoded["record"], &record); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"agentId", "alertType", "threshold", "actualValue", "firedAt"} {
+		if _, ok := record[key]; !ok {
+			t.Errorf("record missing key %q", key)
+		}
+	}
+	var agent map[string]interface{}
+	if err := json.Unmarshal(decoded["agent"], &agent); err != nil {
+		t.Fatal(err)
+	}
+	if agent["ip"] != "10.0.0.1" {
+		t.Errorf("unexpected agent %v", agent)
+	}
+}
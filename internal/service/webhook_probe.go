@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// webhookProbeTimeout 连通性探测超时时间，保存配置时不宜等待过久
+const webhookProbeTimeout = 5 * time.Second
+
+// WebhookProbeResult 自定义Webhook连通性探测结果
+type WebhookProbeResult struct {
+	Name       string `json:"name,omitempty"`       // 渠道名称
+	URL        string `json:"url"`                  // 探测地址
+	Reachable  bool   `json:"reachable"`            // 是否可达（收到任意 HTTP 响应即视为可达）
+	StatusCode int    `json:"statusCode,omitempty"` // 响应状态码
+	Error      string `json:"error,omitempty"`      // 不可达时的错误信息
+}
+
+// ProbeWebhook 对自定义Webhook地址做一次快速的连通性探测
+// 先发送 HEAD 请求，服务端不支持 HEAD 时改用 OPTIONS；不发送告警内容
+func (n *Notifier) ProbeWebhook(ctx context.Context, config map[string]interface{}) WebhookProbeResult {
+	webhookURL, _ := config["url"].(string)
+	result := WebhookProbeResult{URL: webhookURL}
+	if webhookURL == "" {
+		result.Error = "自定义Webhook配置缺少 url"
+		return result
+	}
+
+	if proxy, err := parseProxyURL(config); err == nil {
+		ctx = withProxyURL(ctx, proxy)
+	}
+	ctx, cancel := context.WithTimeout(ctx, webhookProbeTimeout)
+	defer cancel()
+
+	statusCode, err := n.probe(ctx, http.MethodHead, webhookURL)
+	if err == nil && (statusCode == http.StatusMethodNotAllowed || statusCode == http.StatusNotImplemented) {
+		statusCode, err = n.probe(ctx, http.MethodOptions, webhookURL)
+	}
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.Reachable = true
+	result.StatusCode = statusCode
+	return result
+}
+
+// probe 发送不带请求体的探测请求，返回响应状态码
+func (n *Notifier) probe(ctx context.Context, method, url string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, fmt.Errorf("创建请求失败: %w", err)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("发送请求失败: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"strings"
+)
+
+// webhookSignatureHeader 自定义Webhook签名请求头，格式: <algorithm>=<hex>
+const webhookSignatureHeader = "X-Pika-Signature"
+
+// defaultSigningAlgorithm 默认签名算法
+const defaultSigningAlgorithm = "sha256"
+
+// signingAlgorithms 支持的签名算法，sha1 仅用于兼容只支持 HMAC-SHA1 的旧接收端
+var signingAlgorithms = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// signWebhookBody 使用 HMAC 对请求体签名，返回签名请求头的值
+func signWebhookBody(body []byte, secret, algorithm string) (string, error) {
+	algorithm = strings.ToLower(algorithm)
+	if algorithm == "" {
+		algorithm = defaultSigningAlgorithm
+	}
+	newHash, ok := signingAlgorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("不支持的签名算法: %s", algorithm)
+	}
+
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write(body)
+	return algorithm + "=" + hex.EncodeToString(mac.Sum(nil)), nil
+}
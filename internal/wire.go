@@ -29,6 +29,8 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		service.NewTamperService,
 		service.NewMetricService,
 		service.NewGeoIPService,
+		service.NewNotificationChannelService,
+		service.NewPropertyBundleService,
 
 		service.NewNotifier,
 		// WebSocket Manager
@@ -45,6 +47,7 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 		handler.NewApiKeyHandler,
 		handler.NewAccountHandler,
 		handler.NewTamperHandler,
+		handler.NewNotificationChannelHandler,
 
 		// App Components
 		wire.Struct(new(AppComponents), "*"),
@@ -62,6 +65,8 @@ type AppComponents struct {
 	MonitorHandler  *handler.MonitorHandler
 	TamperHandler   *handler.TamperHandler
 
+	NotificationChannelHandler *handler.NotificationChannelHandler
+
 	AgentService    *service.AgentService
 	MetricService   *service.MetricService
 	AlertService    *service.AlertService
@@ -70,5 +75,8 @@ type AppComponents struct {
 	ApiKeyService   *service.ApiKeyService
 	TamperService   *service.TamperService
 
+	NotificationChannelService *service.NotificationChannelService
+	Notifier                   *service.Notifier
+
 	WSManager *websocket.Manager
 }
@@ -26,7 +26,7 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	accountService := service.NewAccountService(logger, userService, oidcService, gitHubOAuthService, cfg)
 	accountHandler := handler.NewAccountHandler(accountService)
 	apiKeyService := service.NewApiKeyService(logger, db)
-	propertyService := service.NewPropertyService(logger, db)
+	propertyService := service.NewPropertyService(logger, db, cfg)
 	metricService := service.NewMetricService(logger, db, propertyService)
 	geoIPService, err := service.NewGeoIPService(logger, cfg)
 	if err != nil {
@@ -39,28 +39,37 @@ func InitializeApp(logger *zap.Logger, db *gorm.DB, cfg *config.AppConfig) (*App
 	tamperService := service.NewTamperService(logger, tamperRepo, manager)
 	agentHandler := handler.NewAgentHandler(logger, agentService, metricService, monitorService, tamperService, manager)
 	apiKeyHandler := handler.NewApiKeyHandler(logger, apiKeyService)
-	notifier := service.NewNotifier(logger)
-	alertService := service.NewAlertService(logger, db, propertyService, notifier)
+	notifier, err := service.NewNotifier(logger, cfg, propertyService)
+	if err != nil {
+		return nil, err
+	}
+	notificationChannelService := service.NewNotificationChannelService(logger, db, propertyService)
+	alertService := service.NewAlertService(logger, db, propertyService, notificationChannelService, notifier)
 	alertHandler := handler.NewAlertHandler(logger, alertService)
-	propertyHandler := handler.NewPropertyHandler(logger, propertyService, notifier)
+	propertyBundleService := service.NewPropertyBundleService(logger, propertyService, notificationChannelService)
+	propertyHandler := handler.NewPropertyHandler(logger, propertyService, propertyBundleService, notifier)
 	monitorHandler := handler.NewMonitorHandler(logger, monitorService, agentService)
 	tamperHandler := handler.NewTamperHandler(logger, tamperService)
+	notificationChannelHandler := handler.NewNotificationChannelHandler(logger, notificationChannelService, notifier)
 	appComponents := &AppComponents{
-		AccountHandler:  accountHandler,
-		AgentHandler:    agentHandler,
-		ApiKeyHandler:   apiKeyHandler,
-		AlertHandler:    alertHandler,
-		PropertyHandler: propertyHandler,
-		MonitorHandler:  monitorHandler,
-		TamperHandler:   tamperHandler,
-		AgentService:    agentService,
-		MetricService:   metricService,
-		AlertService:    alertService,
-		PropertyService: propertyService,
-		MonitorService:  monitorService,
-		ApiKeyService:   apiKeyService,
-		TamperService:   tamperService,
-		WSManager:       manager,
+		AccountHandler:             accountHandler,
+		AgentHandler:               agentHandler,
+		ApiKeyHandler:              apiKeyHandler,
+		AlertHandler:               alertHandler,
+		PropertyHandler:            propertyHandler,
+		MonitorHandler:             monitorHandler,
+		TamperHandler:              tamperHandler,
+		AgentService:               agentService,
+		MetricService:              metricService,
+		AlertService:               alertService,
+		PropertyService:            propertyService,
+		MonitorService:             monitorService,
+		ApiKeyService:              apiKeyService,
+		TamperService:              tamperService,
+		NotificationChannelHandler: notificationChannelHandler,
+		NotificationChannelService: notificationChannelService,
+		Notifier:                   notifier,
+		WSManager:                  manager,
 	}
 	return appComponents, nil
 }
@@ -77,6 +86,8 @@ type AppComponents struct {
 	MonitorHandler  *handler.MonitorHandler
 	TamperHandler   *handler.TamperHandler
 
+	NotificationChannelHandler *handler.NotificationChannelHandler
+
 	AgentService    *service.AgentService
 	MetricService   *service.MetricService
 	AlertService    *service.AlertService
@@ -85,5 +96,8 @@ type AppComponents struct {
 	ApiKeyService   *service.ApiKeyService
 	TamperService   *service.TamperService
 
+	NotificationChannelService *service.NotificationChannelService
+	Notifier                   *service.Notifier
+
 	WSManager *websocket.Manager
 }
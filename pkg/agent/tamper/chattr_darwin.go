@@ -0,0 +1,128 @@
+//go:build darwin
+
+package tamper
+
+import (
+	"os"
+	"syscall"
+)
+
+/*
+File attributes.
+
+macOS 没有 ioctl 形式的文件属性，使用 BSD chflags 实现。
+对外仍使用 Linux 的 FS_*_FL 常量，调用方无需区分系统。
+*/
+const (
+	FS_IMMUTABLE_FL = 0x00000010 /* Immutable file */
+	FS_APPEND_FL    = 0x00000020 /* writes to file may only append */
+	FS_NODUMP_FL    = 0x00000040 /* do not dump file */
+)
+
+/*
+BSD file flags.
+*/
+const (
+	// from /usr/include/sys/stat.h
+	UF_NODUMP    = 0x00000001 /* do not dump file */
+	UF_IMMUTABLE = 0x00000002 /* file may not be changed */
+	UF_APPEND    = 0x00000004 /* writes to file may only append */
+	SF_IMMUTABLE = 0x00020000 /* file may not be changed */
+	SF_APPEND    = 0x00040000 /* writes to file may only append */
+)
+
+// chflagsMapping FS_*_FL 与 chflags 标志的对应关系，user 为普通用户可设置的标志，
+// system 为只有 root 能设置和清除的标志（securelevel > 0 时 root 也无法清除）
+var chflagsMapping = []struct {
+	attr   int32
+	user   uint32
+	system uint32
+}{
+	{FS_IMMUTABLE_FL, UF_IMMUTABLE, SF_IMMUTABLE},
+	{FS_APPEND_FL, UF_APPEND, SF_APPEND},
+	{FS_NODUMP_FL, UF_NODUMP, 0},
+}
+
+func getFlags(f *os.File) (uint32, error) {
+	var st syscall.Stat_t
+	if err := syscall.Fstat(int(f.Fd()), &st); err != nil {
+		return 0, os.NewSyscallError("fstat", err)
+	}
+	return st.Flags, nil
+}
+
+func setFlags(f *os.File, flags uint32) error {
+	if err := syscall.Fchflags(int(f.Fd()), int(flags)); err != nil {
+		return os.NewSyscallError("fchflags", err)
+	}
+	return nil
+}
+
+/*
+GetAttr retrieves the attributes of a file.
+UF_* 与 SF_* 任一标志存在时即视为设置了对应属性。
+*/
+func GetAttrs(f *os.File) (int32, error) {
+	flags, err := getFlags(f)
+	if err != nil {
+		return 0, err
+	}
+	var attrs int32
+	for _, m := range chflagsMapping {
+		if flags&(m.user|m.system) != 0 {
+			attrs |= m.attr
+		}
+	}
+	return attrs, nil
+}
+
+/*
+SetAttr sets the given attribute.
+以 root 运行时设置 SF_* 标志，普通用户无法移除；否则设置 UF_* 标志。
+*/
+func SetAttr(f *os.File, attr int32) error {
+	flags, err := getFlags(f)
+	if err != nil {
+		return err
+	}
+	root := os.Geteuid() == 0
+	for _, m := range chflagsMapping {
+		if attr&m.attr == 0 {
+			continue
+		}
+		if root && m.system != 0 {
+			flags |= m.system
+		} else {
+			flags |= m.user
+		}
+	}
+	return setFlags(f, flags)
+}
+
+/*
+UnsetAttr unsets the given attribute.
+同时清除 UF_* 和 SF_* 标志，清除 SF_* 标志需要 root 权限。
+*/
+func UnsetAttr(f *os.File, attr int32) error {
+	flags, err := getFlags(f)
+	if err != nil {
+		return err
+	}
+	for _, m := range chflagsMapping {
+		if attr&m.attr != 0 {
+			flags &^= m.user | m.system
+		}
+	}
+	return setFlags(f, flags)
+}
+
+/*
+IsAttr checks whether the given attribute is set.
+*/
+func IsAttr(f *os.File, attr int32) (bool, error) {
+	attrs, err := GetAttrs(f)
+	if err != nil {
+		return false, err
+	}
+	return (attrs & attr) != 0, nil
+}
@@ -0,0 +1,75 @@
+package tamper
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+/*
+SetAppendOnly sets the append-only attribute.
+文件只能追加写入，不能截断、覆盖或删除，适用于防篡改的日志文件。
+*/
+func SetAppendOnly(f *os.File) error {
+	return SetAttr(f, FS_APPEND_FL)
+}
+
+/*
+UnsetAppendOnly unsets the append-only attribute.
+*/
+func UnsetAppendOnly(f *os.File) error {
+	return UnsetAttr(f, FS_APPEND_FL)
+}
+
+/*
+SetAttrRecursive sets the given attribute on every regular file under path.
+目录本身不设置属性（目录设置不可变后无法新增文件），需要时单独调用 SetAttr。
+符号链接会被跳过，避免修改目录树之外的文件；单个文件失败不会中断遍历，所有错误合并后返回。
+*/
+func SetAttrRecursive(path string, attr int32) error {
+	return walkAttr(path, func(f *os.File) error {
+		return SetAttr(f, attr)
+	})
+}
+
+/*
+UnsetAttrRecursive unsets the given attribute on every regular file under path.
+*/
+func UnsetAttrRecursive(path string, attr int32) error {
+	return walkAttr(path, func(f *os.File) error {
+		return UnsetAttr(f, attr)
+	})
+}
+
+// walkAttr 遍历目录树，对每个普通文件执行 apply，收集所有错误
+func walkAttr(root string, apply func(f *os.File) error) error {
+	var errs []error
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if err := applyAttr(path, apply); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func applyAttr(path string, apply func(f *os.File) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return apply(f)
+}
@@ -3,9 +3,12 @@ package tamper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -513,3 +516,58 @@ func TestChmodEventDetectsAttributeTamper(t *testing.T) {
 		t.Fatal("超时未收到属性篡改告警")
 	}
 }
+
+func TestSetAttrRecursive(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("防篡改功能仅支持 Linux 系统")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("此测试需要 root 权限才能设置文件属性")
+	}
+
+	root := t.TempDir()
+	outside := filepath.Join(t.TempDir(), "outside.txt")
+	files := []string{filepath.Join(root, "a.txt"), filepath.Join(root, "sub", "b.txt"), outside}
+	for _, file := range files {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatalf("创建测试目录失败: %v", err)
+		}
+		if err := os.WriteFile(file, []byte("pika"), 0644); err != nil {
+			t.Fatalf("创建测试文件失败: %v", err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "link.txt")); err != nil {
+		t.Fatalf("创建符号链接失败: %v", err)
+	}
+
+	if err := SetAttrRecursive(root, FS_IMMUTABLE_FL); err != nil {
+		if errors.Is(err, syscall.ENOTTY) || errors.Is(err, syscall.EOPNOTSUPP) {
+			t.Skipf("文件系统不支持文件属性: %v", err)
+		}
+		t.Fatalf("SetAttrRecursive() 失败: %v", err)
+	}
+	defer UnsetAttrRecursive(root, FS_IMMUTABLE_FL)
+
+	want := map[string]bool{files[0]: true, files[1]: true, outside: false}
+	for file, immutable := range want {
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("打开文件失败: %v", err)
+		}
+		got, err := IsAttr(f, FS_IMMUTABLE_FL)
+		f.Close()
+		if err != nil {
+			t.Fatalf("IsAttr() 失败: %v", err)
+		}
+		if got != immutable {
+			t.Errorf("%s 不可变属性为 %v，期望 %v", file, got, immutable)
+		}
+	}
+
+	if err := UnsetAttrRecursive(root, FS_IMMUTABLE_FL); err != nil {
+		t.Fatalf("UnsetAttrRecursive() 失败: %v", err)
+	}
+	if err := os.Remove(files[0]); err != nil {
+		t.Errorf("移除属性后应能删除文件: %v", err)
+	}
+}
@@ -1,4 +1,4 @@
-import { get, post, put } from './request';
+import { del, get, post, put } from './request';
 
 // ==================== 通用 Property 接口 ====================
 
@@ -50,29 +50,41 @@ export const saveMetricsConfig = async (config: MetricsConfig): Promise<void> =>
 
 // ==================== 通知渠道配置 ====================
 
-const PROPERTY_ID_NOTIFICATION_CHANNELS = 'notification_channels';
-
-// 通知渠道配置（通过 type 标识，不再使用独立ID）
+// 通知渠道（存储在独立的 notification_channels 表中，旧的 notification_channels 属性已废弃）
 export interface NotificationChannel {
-    type: 'dingtalk' | 'wecom' | 'feishu' | 'email' | 'webhook'; // 渠道类型，作为唯一标识
+    id?: string; // 渠道ID，新建时为空
+    name?: string; // 渠道名称
+    type: 'dingtalk' | 'wecom' | 'feishu' | 'email' | 'webhook'; // 渠道类型
     enabled: boolean; // 是否启用
-    config: Record<string, any>; // JSON配置，根据type不同而不同
+    config: Record<string, any>; // JSON配置，根据type不同而不同，敏感字段以 ******** 返回，原样提交表示不修改
 }
 
 // 获取通知渠道列表
 export const getNotificationChannels = async (): Promise<NotificationChannel[]> => {
-    const channels = await getProperty<NotificationChannel[]>(PROPERTY_ID_NOTIFICATION_CHANNELS);
-    return channels || [];
+    const response = await get<NotificationChannel[]>('/admin/notification-channels');
+    return response.data || [];
+};
+
+// 创建通知渠道
+export const createNotificationChannel = async (channel: NotificationChannel): Promise<NotificationChannel> => {
+    const response = await post<NotificationChannel>('/admin/notification-channels', channel);
+    return response.data;
+};
+
+// 更新通知渠道
+export const updateNotificationChannel = async (id: string, channel: NotificationChannel): Promise<NotificationChannel> => {
+    const response = await put<NotificationChannel>(`/admin/notification-channels/${id}`, channel);
+    return response.data;
 };
 
-// 保存通知渠道列表
-export const saveNotificationChannels = async (channels: NotificationChannel[]): Promise<void> => {
-    return saveProperty(PROPERTY_ID_NOTIFICATION_CHANNELS, '通知渠道配置', channels);
+// 删除通知渠道
+export const deleteNotificationChannel = async (id: string): Promise<void> => {
+    await del(`/admin/notification-channels/${id}`);
 };
 
 // 测试通知渠道（从数据库读取配置）
-export const testNotificationChannel = async (type: string): Promise<{ message: string }> => {
-    const response = await post<{ message: string }>(`/admin/notification-channels/${type}/test`);
+export const testNotificationChannel = async (id: string): Promise<{ message: string }> => {
+    const response = await post<{ message: string }>(`/admin/notification-channels/${id}/test`);
     return response.data;
 };
 
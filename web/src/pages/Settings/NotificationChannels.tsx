@@ -3,10 +3,12 @@ import {App, Button, Card, Collapse, Form, Input, Select, Space, Spin, Switch} f
 import {TestTube} from 'lucide-react';
 import {useMutation, useQuery, useQueryClient} from '@tanstack/react-query';
 import {
+    createNotificationChannel,
+    deleteNotificationChannel,
     getNotificationChannels,
     type NotificationChannel,
-    saveNotificationChannels,
     testNotificationChannel,
+    updateNotificationChannel,
 } from '@/api/property.ts';
 import {getErrorMessage} from '@/lib/utils';
 
@@ -21,9 +23,31 @@ const NotificationChannels = () => {
         queryFn: getNotificationChannels,
     });
 
+    // 每种类型在此页面管理第一个渠道，其他渠道不受影响
+    const channelOfType = (type: NotificationChannel['type']) => channels.find((channel) => channel.type === type);
+
+    // 按表单结果逐个创建、更新或删除渠道，更新时保留页面未展示的配置项
+    const saveChannels = async (desired: Record<string, NotificationChannel | null>) => {
+        const requests = Object.entries(desired).map(([type, channel]) => {
+            const existing = channelOfType(type as NotificationChannel['type']);
+            if (!channel) {
+                return existing?.id ? deleteNotificationChannel(existing.id) : Promise.resolve();
+            }
+            if (existing?.id) {
+                return updateNotificationChannel(existing.id, {
+                    ...existing,
+                    enabled: channel.enabled,
+                    config: {...existing.config, ...channel.config},
+                });
+            }
+            return createNotificationChannel(channel);
+        });
+        await Promise.all(requests);
+    };
+
     // 保存 mutation
     const saveMutation = useMutation({
-        mutationFn: saveNotificationChannels,
+        mutationFn: saveChannels,
         onSuccess: () => {
             messageApi.success('保存成功');
             queryClient.invalidateQueries({queryKey: ['notificationChannels']});
@@ -49,7 +73,11 @@ const NotificationChannels = () => {
         if (channels.length > 0) {
             const formValues: Record<string, any> = {};
 
-            channels.forEach((channel) => {
+            (['dingtalk', 'wecom', 'feishu', 'webhook'] as const).forEach((type) => {
+                const channel = channelOfType(type);
+                if (!channel) {
+                    return;
+                }
                 if (channel.type === 'dingtalk') {
                     formValues.dingtalkEnabled = channel.enabled;
                     formValues.dingtalkSecretKey = channel.config?.secretKey || '';
@@ -85,41 +113,46 @@ const NotificationChannels = () => {
     const handleSave = async () => {
         try {
             const values = await form.validateFields();
-            const newChannels: NotificationChannel[] = [];
+            const newChannels: Record<string, NotificationChannel | null> = {
+                dingtalk: null,
+                wecom: null,
+                feishu: null,
+                webhook: null,
+            };
 
             // 钉钉
             if (values.dingtalkEnabled || values.dingtalkSecretKey) {
-                newChannels.push({
+                newChannels.dingtalk = {
                     type: 'dingtalk',
                     enabled: values.dingtalkEnabled || false,
                     config: {
                         secretKey: values.dingtalkSecretKey || '',
                         signSecret: values.dingtalkSignSecret || '',
                     },
-                });
+                };
             }
 
             // 企业微信
             if (values.wecomEnabled || values.wecomSecretKey) {
-                newChannels.push({
+                newChannels.wecom = {
                     type: 'wecom',
                     enabled: values.wecomEnabled || false,
                     config: {
                         secretKey: values.wecomSecretKey || '',
                     },
-                });
+                };
             }
 
             // 飞书
             if (values.feishuEnabled || values.feishuSecretKey) {
-                newChannels.push({
+                newChannels.feishu = {
                     type: 'feishu',
                     enabled: values.feishuEnabled || false,
                     config: {
                         secretKey: values.feishuSecretKey || '',
                         signSecret: values.feishuSignSecret || '',
                     },
-                });
+                };
             }
 
             // 自定义Webhook
@@ -134,7 +167,7 @@ const NotificationChannels = () => {
                     });
                 }
 
-                newChannels.push({
+                newChannels.webhook = {
                     type: 'webhook',
                     enabled: values.webhookEnabled || false,
                     config: {
@@ -144,7 +177,7 @@ const NotificationChannels = () => {
                         customBody: values.webhookCustomBody || '',
                         headers: Object.keys(headersObj).length > 0 ? headersObj : undefined,
                     },
-                });
+                };
             }
 
             saveMutation.mutate(newChannels);
@@ -153,8 +186,13 @@ const NotificationChannels = () => {
         }
     };
 
-    const handleTest = (type: string) => {
-        testMutation.mutate(type);
+    const handleTest = (type: NotificationChannel['type']) => {
+        const channel = channelOfType(type);
+        if (!channel?.id) {
+            messageApi.warning('请先保存渠道配置');
+            return;
+        }
+        testMutation.mutate(channel.id);
     };
 
     if (isLoading) {